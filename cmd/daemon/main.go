@@ -3,6 +3,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -10,53 +12,105 @@ import (
 	"syscall"
 	"time"
 
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-
 	"hf-scraper/internal/config"
+	"hf-scraper/internal/delivery/middleware"
+	"hf-scraper/internal/delivery/rest"
+	"hf-scraper/internal/delivery/sse"
 	"hf-scraper/internal/delivery/ui"
 	"hf-scraper/internal/events"
+	"hf-scraper/internal/metrics"
 	"hf-scraper/internal/scraper"
 	"hf-scraper/internal/service"
 	"hf-scraper/internal/storage"
+	"hf-scraper/internal/tracing"
+	"hf-scraper/internal/webhook"
 )
 
 func main() {
+	backfillStartURL := flag.String("backfill-start-url", "", "Start the backfill from this URL instead of the default (overrides WATCHER.BACKFILL_START_URL)")
+	flag.Parse()
+
 	// 1. Load Configuration
 	cfg, err := config.Load()
+	var invalid config.ValidationErrors
+	if errors.As(err, &invalid) {
+		log.Println("Invalid configuration:")
+		for _, fieldErr := range invalid {
+			log.Printf("  - %v", fieldErr)
+		}
+		os.Exit(1)
+	}
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if *backfillStartURL != "" {
+		cfg.Watcher.BackfillStartURL = *backfillStartURL
+	}
 
 	// 2. Setup Context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 3. Initialize Database Connection
-	log.Println("Connecting to MongoDB...")
-	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.Database.URI))
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// 3. Initialize Storage
+	log.Printf("Opening %s storage...", cfg.Database.Driver)
+	store, err := storage.Open(ctx, cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		log.Fatalf("Failed to open storage: %v", err)
 	}
-	defer mongoClient.Disconnect(ctx)
-	db := mongoClient.Database(cfg.Database.Name)
+	defer store.Close(ctx)
+	go store.Supervise(ctx)
 
 	// 4. Initialize Components
 	log.Println("Initializing components...")
-	broker := events.NewBroker()
-	modelStore := storage.NewMongoModelStorage(db, cfg.Database.Collection)
-	statusStore := storage.NewMongoStatusStorage(db, cfg.Database.StatusCollection)
-	hfScraper := scraper.NewScraper(cfg.Scraper)
-	coreService := service.NewService(cfg.Watcher, cfg.Scraper, *hfScraper, modelStore, statusStore, broker)
+	var appMetrics metrics.Metrics = metrics.Noop{}
+	var promMetrics *metrics.Prometheus
+	if cfg.Metrics.Enabled {
+		promMetrics = metrics.NewPrometheus()
+		appMetrics = promMetrics
+	}
+	broker := events.NewBroker(appMetrics)
+	if cfg.Events.BatchIntervalMs > 0 {
+		broker = events.NewBatchingBroker(time.Duration(cfg.Events.BatchIntervalMs)*time.Millisecond, appMetrics)
+	}
+	defer broker.Close()
+	modelStore, statusStore := store.Models, store.Status
+	hfScraper := scraper.NewScraper(cfg.Scraper, appMetrics)
+	if cfg.Scraper.PingOnStartup {
+		pingCtx, pingCancel := context.WithTimeout(ctx, time.Duration(cfg.Scraper.PingTimeoutSeconds)*time.Second)
+		err := hfScraper.Ping(pingCtx)
+		pingCancel()
+		if err != nil {
+			log.Fatalf("Hugging Face API at %s is not reachable: %v", cfg.Scraper.BaseURL, err)
+		}
+	}
+	coreService := service.NewService(cfg.Watcher, cfg.Scraper, *hfScraper, modelStore, statusStore, broker, cfg.Cache, cfg.Ingest, cfg.Database, appMetrics,
+		service.WithBadges(cfg.Badges))
 
 	// 5. Initialize and Start The Server (API and UI)
-	uiHandlers := ui.NewHandlers(coreService)
+	uiHandlers := ui.NewHandlers(coreService, cfg.Server)
+	apiHandlers := rest.NewModelHandlers(coreService, cfg.Server)
+	sseHandlers := sse.NewHandlers(broker, cfg.Server)
 	mux := http.NewServeMux()
-	uiHandlers.RegisterRoutes(mux) // Register all UI routes and static files
+	apiHandlers.RegisterRoutes(mux) // Register the JSON API routes
+	uiHandlers.RegisterRoutes(mux)  // Register all UI routes and static files
+
+	// Long-lived event streams and metrics scrapes bypass the in-flight limit.
+	root := http.NewServeMux()
+	if promMetrics != nil {
+		root.Handle("GET /metrics", promMetrics.Handler())
+	}
+	sseHandlers.RegisterRoutes(root) // Register the event stream
+	root.Handle("/", middleware.MaxInFlight(cfg.Server.MaxInFlightRequests)(mux))
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
-		Handler:      mux,
+		Handler:      tracing.Middleware(middleware.Logging(cfg.Server.RequestLogSampleRate)(root)),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  15 * time.Second,
@@ -70,7 +124,19 @@ func main() {
 	}()
 
 	// 6. Start the Engine
+	if cfg.Database.NormalizeMissingFields {
+		go coreService.NormalizeMissingFields(ctx)
+	}
+	go coreService.WarmCache(ctx)
+	go coreService.RunStatsSnapshots(ctx)
+	go coreService.RunBadgeThresholds(ctx)
+	go webhook.NewNotifier(cfg.Webhook).Run(ctx, broker)
 	go func() {
+		// The watch cycle reads the benchmark through these indexes, so they
+		// are in place before the service starts.
+		if cfg.Database.EnsureIndexes {
+			coreService.EnsureIndexes(ctx)
+		}
 		if err := coreService.Start(ctx); err != nil {
 			log.Printf("Core service error: %v", err)
 			cancel()
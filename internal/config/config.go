@@ -2,6 +2,9 @@
 package config
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -13,44 +16,448 @@ type Config struct {
 	Database DatabaseConfig
 	Scraper  ScraperConfig
 	Watcher  WatcherConfig
+	Events   EventsConfig
+	Webhook  WebhookConfig
+	Cache    CacheConfig
+	Ingest   IngestConfig
+	Metrics  MetricsConfig
+	Tracing  TracingConfig
+	Badges   BadgesConfig
 }
 
 // ServerConfig holds the API server settings.
 type ServerConfig struct {
 	Port string `mapstructure:"port"`
+	// AdminToken is the bearer token required by admin endpoints.
+	// Admin endpoints are disabled while it is empty.
+	AdminToken string `mapstructure:"admin_token"`
+	// MaxRequestBytes caps the request body size accepted by mutating endpoints.
+	MaxRequestBytes int64 `mapstructure:"max_request_bytes"`
+	// PublicFieldDenylist lists model fields, by their JSON name, that are
+	// stripped from public API and UI responses, e.g. ["sha", "private"].
+	// Admin endpoints still return them.
+	PublicFieldDenylist []string `mapstructure:"public_field_denylist"`
+	// ResponseFormat selects how public endpoints serialize models: "default",
+	// or "hf" to match the Hugging Face API's field names and types.
+	ResponseFormat string `mapstructure:"response_format"`
+	// MaxSSEConnections caps the number of open event streams. Further
+	// connections are refused with 503 until one closes. Zero means no limit.
+	MaxSSEConnections int64 `mapstructure:"max_sse_connections"`
+	// IndexSort is the field the UI index page and searches without an
+	// explicit sort are ordered by: likes, downloads or lastModified.
+	IndexSort string `mapstructure:"index_sort"`
+	// IndexSortOrder is the order of IndexSort: -1 descending, 1 ascending.
+	IndexSortOrder int `mapstructure:"index_sort_order"`
+	// RequestLogSampleRate logs one in every N successful requests. Failed
+	// requests are always logged. 1 logs every request, 0 only failures.
+	RequestLogSampleRate int `mapstructure:"request_log_sample_rate"`
+	// StaticMaxAgeSeconds is the Cache-Control max-age of the UI's static
+	// assets. Zero makes browsers revalidate them on every use.
+	StaticMaxAgeSeconds int `mapstructure:"static_max_age_seconds"`
+	// MaxBatchIDs caps the number of IDs one POST /models/batch request may
+	// look up. Zero means no limit.
+	MaxBatchIDs int `mapstructure:"max_batch_ids"`
+	// LowercaseModelIDs lowercases the model ID in model detail paths before
+	// the lookup. Stored IDs keep the Hub's case, so only enable it for a
+	// store whose IDs are all lowercase.
+	LowercaseModelIDs bool `mapstructure:"lowercase_model_ids"`
+	// MaxInFlightRequests caps the number of API and UI requests served at
+	// the same time; further requests get a 503 until one completes. Event
+	// streams and metrics scrapes are not counted. Zero means no limit.
+	MaxInFlightRequests int64 `mapstructure:"max_in_flight_requests"`
+}
+
+// Supported values for ServerConfig.ResponseFormat.
+const (
+	ResponseFormatDefault = "default"
+	ResponseFormatHF      = "hf"
+)
+
+// IndexSortFields lists the allowed ServerConfig.IndexSort values.
+var IndexSortFields = []string{"likes", "downloads", "lastModified"}
+
+// DeniedFields returns PublicFieldDenylist as a set.
+func (c ServerConfig) DeniedFields() map[string]bool {
+	denied := make(map[string]bool, len(c.PublicFieldDenylist))
+	for _, field := range c.PublicFieldDenylist {
+		denied[field] = true
+	}
+	return denied
 }
 
 // DatabaseConfig holds the database connection settings.
 type DatabaseConfig struct {
+	// Driver selects the storage backend: "mongo", or "memory" for development
+	// and tests. The memory backend keeps nothing across restarts.
+	Driver           string `mapstructure:"driver"`
 	URI              string `mapstructure:"uri"`
 	Name             string `mapstructure:"name"`
 	Collection       string `mapstructure:"collection"`
 	StatusCollection string `mapstructure:"status_collection"`
+	// HistoryCollection holds the model snapshots recorded with WATCHER.RECORD_HISTORY.
+	HistoryCollection string `mapstructure:"history_collection"`
+	// StatsCollection holds the stats snapshots taken every WATCHER.STATS_SNAPSHOT_MINUTES.
+	StatsCollection string `mapstructure:"stats_collection"`
+	// ReadPreference selects the replica set members serving search and detail
+	// reads: "primary", "secondaryPreferred" or "nearest". Writes always go to the primary.
+	ReadPreference string `mapstructure:"read_preference"`
+	// SlowQueryThresholdMs logs a warning for every storage operation that
+	// takes at least this long. Zero disables slow-query logging.
+	SlowQueryThresholdMs int `mapstructure:"slow_query_threshold_ms"`
+	// ShardingThresholdGB is the data size from which the shard recommendation
+	// admin endpoint suggests sharding the models collection.
+	ShardingThresholdGB int `mapstructure:"sharding_threshold_gb"`
+	// EnsureIndexes creates the model indexes on startup, pausing backfill
+	// writes until the build finishes.
+	EnsureIndexes bool `mapstructure:"ensure_indexes"`
+	// NormalizeMissingFields fills sortable fields missing from older stored
+	// models with zero values on startup, so sorts place them consistently.
+	NormalizeMissingFields bool `mapstructure:"normalize_missing_fields"`
+	// MaxConcurrentWrites caps the model upserts in flight at once across the
+	// backfill, the watcher and the reconciler. Zero means no limit.
+	MaxConcurrentWrites int `mapstructure:"max_concurrent_writes"`
+	// HealthCheckSeconds is how often the Mongo connection is pinged. After
+	// ReconnectAfterFailures consecutive failed pings the client is rebuilt
+	// from URI and swapped in. Zero disables the health check.
+	HealthCheckSeconds     int `mapstructure:"health_check_seconds"`
+	ReconnectAfterFailures int `mapstructure:"reconnect_after_failures"`
 }
 
+// Supported values for DatabaseConfig.Driver.
+const (
+	DatabaseDriverMongo  = "mongo"
+	DatabaseDriverMemory = "memory"
+)
+
+// Supported values for DatabaseConfig.ReadPreference.
+const (
+	ReadPreferencePrimary            = "primary"
+	ReadPreferenceSecondaryPreferred = "secondaryPreferred"
+	ReadPreferenceNearest            = "nearest"
+)
+
 // ScraperConfig holds settings for the Hugging Face API scraper.
 type ScraperConfig struct {
 	BaseURL           string `mapstructure:"base_url"`
 	RequestsPerSecond int    `mapstructure:"requests_per_second"`
 	BurstLimit        int    `mapstructure:"burst_limit"`
+	// BackfillRequestsPerSecond and BackfillBurstLimit override the limits above
+	// while backfilling, so history can be drained faster than the gentle watch
+	// mode. Zero values fall back to RequestsPerSecond and BurstLimit.
+	BackfillRequestsPerSecond int `mapstructure:"backfill_requests_per_second"`
+	BackfillBurstLimit        int `mapstructure:"backfill_burst_limit"`
+	// AdaptiveThreshold throttles requests once the API's X-RateLimit-Remaining
+	// drops to this value, restoring the rate after the quota resets. Higher
+	// values throttle earlier. Zero disables adaptive throttling.
+	AdaptiveThreshold int `mapstructure:"adaptive_threshold"`
+	// BreakerThreshold is the number of consecutive failures that opens the
+	// circuit breaker. Zero disables the breaker.
+	BreakerThreshold       int `mapstructure:"breaker_threshold"`
+	BreakerCooldownSeconds int `mapstructure:"breaker_cooldown_seconds"`
+	// MaxRetryAfterSeconds caps the delay the API may ask for with a
+	// Retry-After header when it rate-limits us. Zero uses the default of
+	// five minutes.
+	MaxRetryAfterSeconds int `mapstructure:"max_retry_after_seconds"`
+	// FieldMappings renames incoming JSON keys to canonical ones before decoding,
+	// e.g. {"pipelineTag": "pipeline_tag"}. Keys are matched case-insensitively.
+	FieldMappings map[string]string `mapstructure:"field_mappings"`
+	// Filter and Search are passed to the Hub API as the "filter" and "search"
+	// query parameters to scrape only a slice of the Hub, e.g. filter=diffusers.
+	Filter string `mapstructure:"filter"`
+	Search string `mapstructure:"search"`
+	// AllowedHosts lists the hosts the scraper may fetch pages from, guarding
+	// against next-page or configured URLs pointing elsewhere. The host of
+	// BaseURL is always allowed.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	// MaxRedirects is the number of redirects followed per request, each of
+	// which must also stay within AllowedHosts. Zero follows none.
+	MaxRedirects int `mapstructure:"max_redirects"`
+	// DebugURLs logs the URL of every fetched page and the next URL parsed from
+	// it, with tokens redacted. It is verbose, so it is off by default.
+	DebugURLs bool `mapstructure:"debug_urls"`
+	// PingOnStartup checks that the API is reachable before starting and exits
+	// with an error otherwise, waiting at most PingTimeoutSeconds.
+	PingOnStartup      bool `mapstructure:"ping_on_startup"`
+	PingTimeoutSeconds int  `mapstructure:"ping_timeout_seconds"`
+	// ByteCount selects what the downloaded bytes metric counts: the
+	// "decompressed" response bodies, or the "wire" bytes as transferred.
+	ByteCount string `mapstructure:"byte_count"`
+	// Accept is the Accept header sent with every API request, e.g. to pin an
+	// API version or satisfy a mirror. Empty sends none.
+	Accept string `mapstructure:"accept"`
+	// APIToken is a Hugging Face access token sent as a bearer token with
+	// every API request, so gated and private models the token can see are
+	// scraped in full. Empty scrapes anonymously. It is never logged.
+	APIToken string `mapstructure:"api_token"`
+	// UserAgent is the User-Agent sent with every API request, so the Hub can
+	// tell this scraper's traffic apart. Empty sends Go's default.
+	UserAgent string `mapstructure:"user_agent"`
+	// Contact is an email address or URL appended to UserAgent in
+	// parentheses, so the Hub knows whom to reach about the traffic.
+	Contact string `mapstructure:"contact"`
+	// OnDemandRequestsPerSecond and OnDemandBurstLimit rate-limit the Hub
+	// fetches triggered by user reads, such as the full record of a compact
+	// or tag-truncated model, apart from the scrape. Reads beyond the limit
+	// are served from the database. Zero disables on-demand fetches.
+	OnDemandRequestsPerSecond int `mapstructure:"on_demand_requests_per_second"`
+	OnDemandBurstLimit        int `mapstructure:"on_demand_burst_limit"`
 }
 
+// Supported values for ScraperConfig.ByteCount.
+const (
+	ByteCountDecompressed = "decompressed"
+	ByteCountWire         = "wire"
+)
+
 // WatcherConfig holds settings for the "Watch Mode" logic.
 type WatcherConfig struct {
 	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// BackfillStartURL overrides the default backfill start URL, e.g. to shard
+	// the historical scrape across instances writing to the same database.
+	// It applies only when no cursor is saved, so restarts resume where the
+	// backfill stopped. It can also be set with the -backfill-start-url flag.
+	BackfillStartURL string `mapstructure:"backfill_start_url"`
+	// MaxBackfillMinutes stops the backfill after this long and switches to
+	// watch mode with the pages collected so far, e.g. for demo or CI runs.
+	// Zero lets the backfill run to the end.
+	MaxBackfillMinutes int `mapstructure:"max_backfill_minutes"`
+	// BackfillShard names this instance's backfill shard, under which its cursor
+	// is saved. Instances sharing a database need distinct names to resume
+	// independently. Empty means the default shard.
+	BackfillShard string `mapstructure:"backfill_shard"`
+	// BackfillWriters is the number of backfill pages stored in parallel while
+	// the next pages are fetched. Values below 1 mean 1.
+	BackfillWriters int `mapstructure:"backfill_writers"`
+	// DedupeWindowMinutes skips re-writing a model whose content is unchanged
+	// and whose lastModified moved by less than this window. The newest model
+	// of a cycle is always written so the watch benchmark keeps up. Zero
+	// disables it.
+	DedupeWindowMinutes int `mapstructure:"dedupe_window_minutes"`
+	// ReconcileIntervalMinutes is how often one batch of stored models is
+	// re-checked against the Hub, marking vanished ones as deleted. Zero disables it.
+	ReconcileIntervalMinutes int `mapstructure:"reconcile_interval_minutes"`
+	ReconcileBatchSize       int `mapstructure:"reconcile_batch_size"`
+	// DeletedRetentionDays hard-deletes soft-deleted models once they have been
+	// deleted for this many days, checked every PurgeIntervalMinutes. Zero keeps
+	// them forever.
+	DeletedRetentionDays int `mapstructure:"deleted_retention_days"`
+	PurgeIntervalMinutes int `mapstructure:"purge_interval_minutes"`
+	// ConcurrentBackfill starts watch mode right away instead of after the
+	// backfill, so new models are captured during a long backfill.
+	ConcurrentBackfill bool `mapstructure:"concurrent_backfill"`
+	// EnrichNewAuthors re-fetches the full record of watched models whose author
+	// is not stored yet. AuthorsRefreshMinutes is how often the known-author set
+	// is reloaded from the database.
+	EnrichNewAuthors      bool `mapstructure:"enrich_new_authors"`
+	AuthorsRefreshMinutes int  `mapstructure:"authors_refresh_minutes"`
+	// RecordHistory saves a snapshot of the likes and downloads of every model
+	// the backfill, watcher or reconciler stores, for the diff endpoint.
+	RecordHistory bool `mapstructure:"record_history"`
+	// CycleHistorySize is the number of recent watch cycle start times the
+	// achieved-interval average on /status and in the metrics is computed over.
+	CycleHistorySize int `mapstructure:"cycle_history_size"`
+	// StatsSnapshotMinutes is how often the stats summary is computed and
+	// stored as a snapshot, which /stats/summary then serves. Zero disables it.
+	StatsSnapshotMinutes int `mapstructure:"stats_snapshot_minutes"`
+	// BenchmarkField is the timestamp the watch cycle sorts the Hub listing by
+	// and compares against the newest stored value: "lastModified" or "createdAt".
+	BenchmarkField string `mapstructure:"benchmark_field"`
+	// RefreshTopDownloads adds a pass to every watch cycle that re-fetches the
+	// TopDownloadsCount most-downloaded models on the Hub, so the metrics of
+	// trending models stay fresh even when they are not modified.
+	RefreshTopDownloads bool `mapstructure:"refresh_top_downloads"`
+	TopDownloadsCount   int  `mapstructure:"top_downloads_count"`
+	// MaxPagesPerCycle bounds how many listing pages one watch cycle follows
+	// while it keeps finding new models, e.g. after downtime. The following
+	// cycles resume the listing where it stopped.
+	MaxPagesPerCycle int `mapstructure:"max_pages_per_cycle"`
+}
+
+// MaxTopDownloadsCount is the largest page the Hub listing returns, which
+// bounds WatcherConfig.TopDownloadsCount.
+const MaxTopDownloadsCount = 1000
+
+// Supported values for WatcherConfig.BenchmarkField.
+const (
+	BenchmarkFieldLastModified = "lastModified"
+	BenchmarkFieldCreatedAt    = "createdAt"
+)
+
+// EventsConfig holds settings for the internal event broker.
+type EventsConfig struct {
+	// BatchIntervalMs coalesces events per topic into one batch delivered on this
+	// interval. Zero disables batching.
+	BatchIntervalMs int `mapstructure:"batch_interval_ms"`
+}
+
+// WebhookConfig holds settings for delivering broker events to HTTP endpoints.
+type WebhookConfig struct {
+	// URLs receive every event of Topics as a JSON POST. No URLs disables webhooks.
+	URLs   []string `mapstructure:"urls"`
+	Topics []string `mapstructure:"topics"`
+	// TimeoutSeconds bounds a single delivery attempt.
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// MaxRetries is the number of retries after a failed attempt, waiting
+	// twice as long before each one starting at BackoffMs. A delivery that
+	// still fails is dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+	BackoffMs  int `mapstructure:"backoff_ms"`
+	// MaxInFlight caps concurrent deliveries. Events arriving while every slot
+	// is busy are dropped rather than queued behind a slow endpoint.
+	MaxInFlight int `mapstructure:"max_in_flight"`
+}
+
+// CacheConfig holds settings for the in-memory model read cache.
+type CacheConfig struct {
+	// MaxEntries caps the number of cached models. Zero disables the cache.
+	MaxEntries int `mapstructure:"max_entries"`
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// WarmupCount pre-loads this many of the most-liked models on startup.
+	WarmupCount int `mapstructure:"warmup_count"`
+	// ServeStale serves an expired cached model, flagged as stale, when the
+	// database cannot be read instead of failing the request.
+	ServeStale bool `mapstructure:"serve_stale"`
+}
+
+// IngestConfig holds settings for how scraped models are transformed before storage.
+type IngestConfig struct {
+	// CompactDocuments stores only a curated subset of fields (dropping sha and
+	// siblings) and fetches the full record from the Hub on demand.
+	CompactDocuments bool `mapstructure:"compact_documents"`
+	// NormalizeTags lowercases, trims and de-duplicates tags before storing them.
+	NormalizeTags bool `mapstructure:"normalize_tags"`
+	// ImportBatchSize is the number of models upserted per write by the JSONL import.
+	ImportBatchSize int `mapstructure:"import_batch_size"`
+	// ImportWriters is the number of import batches upserted at the same time.
+	ImportWriters int `mapstructure:"import_writers"`
+	// MaxTags caps the number of tags stored per model, keeping the first
+	// ones. The model detail fetches the full list from the Hub. Zero keeps all.
+	MaxTags int `mapstructure:"max_tags"`
+}
+
+// BadgesConfig holds the thresholds of the popularity badges added to models
+// in API and UI responses.
+type BadgesConfig struct {
+	// Enabled computes badges for every model read.
+	Enabled bool `mapstructure:"enabled"`
+	// PopularPercentile and TrendingPercentile are the percentiles of
+	// downloads and likes, respectively, a model must reach for the Popular
+	// and Trending badges, e.g. 99 for the top 1%.
+	PopularPercentile  float64 `mapstructure:"popular_percentile"`
+	TrendingPercentile float64 `mapstructure:"trending_percentile"`
+	// NewWithinDays gives the New badge to models first seen within this many
+	// days. Zero disables it.
+	NewWithinDays int `mapstructure:"new_within_days"`
+	// RefreshMinutes is how often the percentile thresholds are recomputed.
+	RefreshMinutes int `mapstructure:"refresh_minutes"`
+}
+
+// MetricsConfig holds settings for metrics export.
+type MetricsConfig struct {
+	// Enabled exports Prometheus metrics at /metrics.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// TracingConfig holds settings for OpenTelemetry tracing.
+type TracingConfig struct {
+	// OTLPEndpoint is the OTLP/HTTP traces endpoint, e.g. "http://localhost:4318/v1/traces".
+	// Tracing is disabled when it is empty.
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	SampleRatio  float64 `mapstructure:"sample_ratio"`
 }
 
 // Load loads the configuration from file and environment variables.
 func Load() (*Config, error) {
 	// Set default values
 	viper.SetDefault("SERVER.PORT", "8080")
+	viper.SetDefault("SERVER.MAX_REQUEST_BYTES", 1<<20)
+	viper.SetDefault("SERVER.RESPONSE_FORMAT", ResponseFormatDefault)
+	viper.SetDefault("SERVER.MAX_SSE_CONNECTIONS", 100)
+	viper.SetDefault("SERVER.INDEX_SORT", "likes")
+	viper.SetDefault("SERVER.INDEX_SORT_ORDER", -1)
+	viper.SetDefault("SERVER.REQUEST_LOG_SAMPLE_RATE", 1)
+	viper.SetDefault("SERVER.STATIC_MAX_AGE_SECONDS", 3600)
+	viper.SetDefault("SERVER.MAX_BATCH_IDS", 1000)
+	viper.SetDefault("SERVER.LOWERCASE_MODEL_IDS", false)
+	viper.SetDefault("SERVER.MAX_IN_FLIGHT_REQUESTS", 0)
+	viper.SetDefault("DATABASE.DRIVER", DatabaseDriverMongo)
 	viper.SetDefault("DATABASE.NAME", "hf-scraper")
 	viper.SetDefault("DATABASE.COLLECTION", "models")
 	viper.SetDefault("DATABASE.STATUS_COLLECTION", "_status")
+	viper.SetDefault("DATABASE.HISTORY_COLLECTION", "model_history")
+	viper.SetDefault("DATABASE.STATS_COLLECTION", "stats_snapshots")
+	viper.SetDefault("DATABASE.READ_PREFERENCE", ReadPreferencePrimary)
+	viper.SetDefault("DATABASE.SLOW_QUERY_THRESHOLD_MS", 500)
+	viper.SetDefault("DATABASE.SHARDING_THRESHOLD_GB", 100)
+	viper.SetDefault("DATABASE.ENSURE_INDEXES", true)
+	viper.SetDefault("DATABASE.NORMALIZE_MISSING_FIELDS", false)
+	viper.SetDefault("DATABASE.MAX_CONCURRENT_WRITES", 0)
+	viper.SetDefault("DATABASE.HEALTH_CHECK_SECONDS", 30)
+	viper.SetDefault("DATABASE.RECONNECT_AFTER_FAILURES", 3)
 	viper.SetDefault("SCRAPER.BASE_URL", "https://huggingface.co")
 	viper.SetDefault("SCRAPER.REQUESTS_PER_SECOND", 5)
 	viper.SetDefault("SCRAPER.BURST_LIMIT", 10)
+	viper.SetDefault("SCRAPER.BACKFILL_REQUESTS_PER_SECOND", 0)
+	viper.SetDefault("SCRAPER.BACKFILL_BURST_LIMIT", 0)
+	viper.SetDefault("SCRAPER.ADAPTIVE_THRESHOLD", 0)
+	viper.SetDefault("SCRAPER.BREAKER_THRESHOLD", 5)
+	viper.SetDefault("SCRAPER.BREAKER_COOLDOWN_SECONDS", 60)
+	viper.SetDefault("SCRAPER.MAX_RETRY_AFTER_SECONDS", 300)
+	viper.SetDefault("SCRAPER.ALLOWED_HOSTS", []string{"huggingface.co"})
+	viper.SetDefault("SCRAPER.MAX_REDIRECTS", 5)
+	viper.SetDefault("SCRAPER.DEBUG_URLS", false)
+	viper.SetDefault("SCRAPER.PING_ON_STARTUP", false)
+	viper.SetDefault("SCRAPER.PING_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("SCRAPER.BYTE_COUNT", ByteCountDecompressed)
+	viper.SetDefault("SCRAPER.ACCEPT", "application/json")
+	viper.SetDefault("SCRAPER.API_TOKEN", "")
+	viper.SetDefault("SCRAPER.USER_AGENT", "hf-scraper/1.0")
+	viper.SetDefault("SCRAPER.CONTACT", "")
+	viper.SetDefault("SCRAPER.ON_DEMAND_REQUESTS_PER_SECOND", 1)
+	viper.SetDefault("SCRAPER.ON_DEMAND_BURST_LIMIT", 5)
 	viper.SetDefault("WATCHER.INTERVAL_MINUTES", 5)
+	viper.SetDefault("WATCHER.BACKFILL_SHARD", "")
+	viper.SetDefault("WATCHER.MAX_BACKFILL_MINUTES", 0)
+	viper.SetDefault("WATCHER.BACKFILL_WRITERS", 1)
+	viper.SetDefault("WATCHER.DEDUPE_WINDOW_MINUTES", 60)
+	viper.SetDefault("WATCHER.RECONCILE_INTERVAL_MINUTES", 0)
+	viper.SetDefault("WATCHER.RECONCILE_BATCH_SIZE", 50)
+	viper.SetDefault("WATCHER.DELETED_RETENTION_DAYS", 0)
+	viper.SetDefault("WATCHER.PURGE_INTERVAL_MINUTES", 60)
+	viper.SetDefault("WATCHER.CONCURRENT_BACKFILL", false)
+	viper.SetDefault("WATCHER.ENRICH_NEW_AUTHORS", false)
+	viper.SetDefault("WATCHER.AUTHORS_REFRESH_MINUTES", 60)
+	viper.SetDefault("WATCHER.RECORD_HISTORY", false)
+	viper.SetDefault("WATCHER.CYCLE_HISTORY_SIZE", 10)
+	viper.SetDefault("WATCHER.STATS_SNAPSHOT_MINUTES", 0)
+	viper.SetDefault("WATCHER.BENCHMARK_FIELD", BenchmarkFieldLastModified)
+	viper.SetDefault("WATCHER.REFRESH_TOP_DOWNLOADS", false)
+	viper.SetDefault("WATCHER.TOP_DOWNLOADS_COUNT", 50)
+	viper.SetDefault("WATCHER.MAX_PAGES_PER_CYCLE", 10)
+	viper.SetDefault("EVENTS.BATCH_INTERVAL_MS", 0)
+	viper.SetDefault("WEBHOOK.URLS", []string{})
+	viper.SetDefault("WEBHOOK.TOPICS", []string{"status:mode_change"})
+	viper.SetDefault("WEBHOOK.TIMEOUT_SECONDS", 5)
+	viper.SetDefault("WEBHOOK.MAX_RETRIES", 3)
+	viper.SetDefault("WEBHOOK.BACKOFF_MS", 500)
+	viper.SetDefault("WEBHOOK.MAX_IN_FLIGHT", 4)
+	viper.SetDefault("CACHE.MAX_ENTRIES", 1000)
+	viper.SetDefault("CACHE.TTL_SECONDS", 300)
+	viper.SetDefault("CACHE.WARMUP_COUNT", 0)
+	viper.SetDefault("CACHE.SERVE_STALE", false)
+	viper.SetDefault("INGEST.COMPACT_DOCUMENTS", false)
+	viper.SetDefault("INGEST.NORMALIZE_TAGS", false)
+	viper.SetDefault("INGEST.IMPORT_BATCH_SIZE", 500)
+	viper.SetDefault("INGEST.IMPORT_WRITERS", 1)
+	viper.SetDefault("BADGES.ENABLED", false)
+	viper.SetDefault("BADGES.POPULAR_PERCENTILE", 99.0)
+	viper.SetDefault("BADGES.TRENDING_PERCENTILE", 99.0)
+	viper.SetDefault("BADGES.NEW_WITHIN_DAYS", 7)
+	viper.SetDefault("BADGES.REFRESH_MINUTES", 60)
+	viper.SetDefault("INGEST.MAX_TAGS", 0)
+	viper.SetDefault("METRICS.ENABLED", false)
+	viper.SetDefault("TRACING.OTLP_ENDPOINT", "")
+	viper.SetDefault("TRACING.SAMPLE_RATIO", 1.0)
 
 	// Load from config file
 	viper.SetConfigName("config")
@@ -71,6 +478,141 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
+
+// shardNamePattern matches the allowed WatcherConfig.BackfillShard values.
+var shardNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// FieldError describes one invalid setting.
+type FieldError struct {
+	// Field is the setting's key, e.g. "SERVER.INDEX_SORT".
+	Field  string `json:"field"`
+	Value  any    `json:"value"`
+	Reason string `json:"reason"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("invalid %s %#v: %s", e.Field, e.Value, e.Reason)
+}
+
+// ValidationErrors lists every invalid setting found by Validate.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate reports settings whose values are out of their allowed range. All
+// of them are reported at once, as a ValidationErrors.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	invalid := func(field string, value any, reason string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Value: value, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	// The shard name becomes part of a document field path.
+	if !shardNamePattern.MatchString(c.Watcher.BackfillShard) {
+		invalid("WATCHER.BACKFILL_SHARD", c.Watcher.BackfillShard, "only letters, digits, '-' and '_' are allowed")
+	}
+	switch c.Watcher.BenchmarkField {
+	case BenchmarkFieldLastModified, BenchmarkFieldCreatedAt:
+	default:
+		invalid("WATCHER.BENCHMARK_FIELD", c.Watcher.BenchmarkField, "must be %s or %s",
+			BenchmarkFieldLastModified, BenchmarkFieldCreatedAt)
+	}
+	if c.Watcher.RefreshTopDownloads && (c.Watcher.TopDownloadsCount < 1 || c.Watcher.TopDownloadsCount > MaxTopDownloadsCount) {
+		invalid("WATCHER.TOP_DOWNLOADS_COUNT", c.Watcher.TopDownloadsCount, "must be between 1 and %d", MaxTopDownloadsCount)
+	}
+	if c.Watcher.MaxPagesPerCycle <= 0 {
+		invalid("WATCHER.MAX_PAGES_PER_CYCLE", c.Watcher.MaxPagesPerCycle, "must be positive")
+	}
+	if c.Ingest.ImportBatchSize <= 0 {
+		invalid("INGEST.IMPORT_BATCH_SIZE", c.Ingest.ImportBatchSize, "must be positive")
+	}
+	if c.Ingest.ImportWriters <= 0 {
+		invalid("INGEST.IMPORT_WRITERS", c.Ingest.ImportWriters, "must be positive")
+	}
+	if c.Badges.Enabled {
+		if c.Badges.PopularPercentile <= 0 || c.Badges.PopularPercentile >= 100 {
+			invalid("BADGES.POPULAR_PERCENTILE", c.Badges.PopularPercentile, "must be between 0 and 100, exclusive")
+		}
+		if c.Badges.TrendingPercentile <= 0 || c.Badges.TrendingPercentile >= 100 {
+			invalid("BADGES.TRENDING_PERCENTILE", c.Badges.TrendingPercentile, "must be between 0 and 100, exclusive")
+		}
+		if c.Badges.NewWithinDays < 0 {
+			invalid("BADGES.NEW_WITHIN_DAYS", c.Badges.NewWithinDays, "must not be negative")
+		}
+		if c.Badges.RefreshMinutes <= 0 {
+			invalid("BADGES.REFRESH_MINUTES", c.Badges.RefreshMinutes, "must be positive")
+		}
+	}
+	if c.Ingest.MaxTags < 0 {
+		invalid("INGEST.MAX_TAGS", c.Ingest.MaxTags, "must not be negative")
+	}
+	if c.Scraper.MaxRetryAfterSeconds < 0 {
+		invalid("SCRAPER.MAX_RETRY_AFTER_SECONDS", c.Scraper.MaxRetryAfterSeconds, "must not be negative")
+	}
+	if c.Scraper.OnDemandRequestsPerSecond < 0 {
+		invalid("SCRAPER.ON_DEMAND_REQUESTS_PER_SECOND", c.Scraper.OnDemandRequestsPerSecond, "must not be negative")
+	}
+	if c.Scraper.OnDemandBurstLimit < 0 {
+		invalid("SCRAPER.ON_DEMAND_BURST_LIMIT", c.Scraper.OnDemandBurstLimit, "must not be negative")
+	}
+	switch c.Scraper.ByteCount {
+	case ByteCountDecompressed, ByteCountWire:
+	default:
+		invalid("SCRAPER.BYTE_COUNT", c.Scraper.ByteCount, "must be %s or %s",
+			ByteCountDecompressed, ByteCountWire)
+	}
+	switch c.Server.ResponseFormat {
+	case ResponseFormatDefault, ResponseFormatHF:
+	default:
+		invalid("SERVER.RESPONSE_FORMAT", c.Server.ResponseFormat, "must be %s or %s",
+			ResponseFormatDefault, ResponseFormatHF)
+	}
+	if !slices.Contains(IndexSortFields, c.Server.IndexSort) {
+		invalid("SERVER.INDEX_SORT", c.Server.IndexSort, "must be one of %s",
+			strings.Join(IndexSortFields, ", "))
+	}
+	if c.Server.IndexSortOrder != 1 && c.Server.IndexSortOrder != -1 {
+		invalid("SERVER.INDEX_SORT_ORDER", c.Server.IndexSortOrder, "must be 1 or -1")
+	}
+	if c.Server.RequestLogSampleRate < 0 {
+		invalid("SERVER.REQUEST_LOG_SAMPLE_RATE", c.Server.RequestLogSampleRate, "must not be negative")
+	}
+	if c.Server.StaticMaxAgeSeconds < 0 {
+		invalid("SERVER.STATIC_MAX_AGE_SECONDS", c.Server.StaticMaxAgeSeconds, "must not be negative")
+	}
+	if c.Server.MaxBatchIDs < 0 {
+		invalid("SERVER.MAX_BATCH_IDS", c.Server.MaxBatchIDs, "must not be negative")
+	}
+	if c.Server.MaxInFlightRequests < 0 {
+		invalid("SERVER.MAX_IN_FLIGHT_REQUESTS", c.Server.MaxInFlightRequests, "must not be negative")
+	}
+	switch c.Database.Driver {
+	case DatabaseDriverMongo, DatabaseDriverMemory:
+	default:
+		invalid("DATABASE.DRIVER", c.Database.Driver, "must be %s or %s",
+			DatabaseDriverMongo, DatabaseDriverMemory)
+	}
+	switch c.Database.ReadPreference {
+	case ReadPreferencePrimary, ReadPreferenceSecondaryPreferred, ReadPreferenceNearest:
+	default:
+		invalid("DATABASE.READ_PREFERENCE", c.Database.ReadPreference, "must be one of %s, %s or %s",
+			ReadPreferencePrimary, ReadPreferenceSecondaryPreferred, ReadPreferenceNearest)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
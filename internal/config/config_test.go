@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// defaultConfig loads the defaults. The tests run outside the repository
+// root, so no config file is found.
+func defaultConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("the defaults do not load: %v", err)
+	}
+	return cfg
+}
+
+// invalidFields returns the fields Validate reports for cfg.
+func invalidFields(t *testing.T, cfg *Config) []string {
+	t.Helper()
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	return fields
+}
+
+func TestValidateReadPreference(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		valid bool
+	}{
+		{value: ReadPreferencePrimary, valid: true},
+		{value: ReadPreferenceSecondaryPreferred, valid: true},
+		{value: ReadPreferenceNearest, valid: true},
+		{value: "secondary", valid: false},
+		{value: "", valid: false},
+	} {
+		cfg := defaultConfig(t)
+		cfg.Database.ReadPreference = tc.value
+		fields := invalidFields(t, cfg)
+		if tc.valid && len(fields) != 0 {
+			t.Errorf("%q: rejected %v", tc.value, fields)
+		}
+		if !tc.valid && (len(fields) != 1 || fields[0] != "DATABASE.READ_PREFERENCE") {
+			t.Errorf("%q: invalid fields = %v, want [DATABASE.READ_PREFERENCE]", tc.value, fields)
+		}
+	}
+}
+
+func TestValidateImportBatchSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		cfg := defaultConfig(t)
+		cfg.Ingest.ImportBatchSize = size
+		if fields := invalidFields(t, cfg); !slices.Equal(fields, []string{"INGEST.IMPORT_BATCH_SIZE"}) {
+			t.Errorf("%d: invalid fields = %v, want [INGEST.IMPORT_BATCH_SIZE]", size, fields)
+		}
+	}
+	cfg := defaultConfig(t)
+	cfg.Ingest.ImportBatchSize = 1
+	if fields := invalidFields(t, cfg); len(fields) != 0 {
+		t.Errorf("1: rejected %v", fields)
+	}
+}
+
+func TestValidateIndexSort(t *testing.T) {
+	for _, tc := range []struct {
+		sort  string
+		order int
+		want  []string
+	}{
+		{sort: "lastModified", order: -1},
+		{sort: "downloads", order: 1},
+		{sort: "name", order: -1, want: []string{"SERVER.INDEX_SORT"}},
+		{sort: "likes", order: 0, want: []string{"SERVER.INDEX_SORT_ORDER"}},
+	} {
+		cfg := defaultConfig(t)
+		cfg.Server.IndexSort, cfg.Server.IndexSortOrder = tc.sort, tc.order
+		if fields := invalidFields(t, cfg); !slices.Equal(fields, tc.want) {
+			t.Errorf("%s %d: invalid fields = %v, want %v", tc.sort, tc.order, fields, tc.want)
+		}
+	}
+}
+
+func TestValidateReportsEveryInvalidField(t *testing.T) {
+	cfg := defaultConfig(t)
+	cfg.Watcher.MaxPagesPerCycle = 0
+	cfg.Scraper.ByteCount = "compressed"
+	cfg.Database.Driver = "postgres"
+
+	err := cfg.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	want := []FieldError{
+		{Field: "WATCHER.MAX_PAGES_PER_CYCLE", Value: 0, Reason: "must be positive"},
+		{Field: "SCRAPER.BYTE_COUNT", Value: "compressed", Reason: "must be decompressed or wire"},
+		{Field: "DATABASE.DRIVER", Value: "postgres", Reason: "must be mongo or memory"},
+	}
+	if !slices.Equal(errs, want) {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+	for _, e := range want {
+		if !strings.Contains(err.Error(), e.Field) {
+			t.Errorf("message %q does not mention %s", err.Error(), e.Field)
+		}
+	}
+}
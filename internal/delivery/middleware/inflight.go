@@ -0,0 +1,34 @@
+// Path: internal/delivery/middleware/inflight.go
+package middleware
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// busyRetryAfterSeconds is suggested to clients refused because the server
+// is at its concurrency limit. Requests are short, so slots free up quickly.
+const busyRetryAfterSeconds = "1"
+
+// MaxInFlight bounds the number of requests served at the same time. A
+// request arriving while limit requests are in flight is refused with 503
+// and a Retry-After header instead of queueing, so a burst cannot pile up
+// work on the database. A limit of 0 or less disables it.
+func MaxInFlight(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		slots := semaphore.NewWeighted(limit)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slots.TryAcquire(1) {
+				w.Header().Set("Retry-After", busyRetryAfterSeconds)
+				http.Error(w, "Server busy, try again later", http.StatusServiceUnavailable)
+				return
+			}
+			defer slots.Release(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
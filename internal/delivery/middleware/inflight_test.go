@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaxInFlightRefusesPastTheLimitUntilASlotFrees(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := MaxInFlight(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	finished := make(chan struct{}, 2)
+	for range 2 {
+		go func() {
+			serve("/slow")
+			finished <- struct{}{}
+		}()
+		<-started
+	}
+
+	rec := serve("/fast")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("with every slot taken: status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != busyRetryAfterSeconds {
+		t.Errorf("Retry-After = %q, want %q", got, busyRetryAfterSeconds)
+	}
+
+	// Once one slow request completes, its slot serves the next request
+	// while the other is still in flight.
+	release <- struct{}{}
+	<-finished
+	if rec := serve("/fast"); rec.Code != http.StatusOK {
+		t.Errorf("after a request completed: status = %d, want 200", rec.Code)
+	}
+	release <- struct{}{}
+	<-finished
+}
@@ -0,0 +1,61 @@
+// Path: internal/delivery/middleware/logging.go
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush event streams.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Logging logs one line per request with its method, path, status and
+// duration. Failed requests (status 400 and above) are always logged, while
+// successful ones are sampled: only one in every sampleRate is logged. A rate
+// of 1 logs every request and 0 logs failures only.
+func Logging(sampleRate int) func(http.Handler) http.Handler {
+	var successes atomic.Uint64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status < http.StatusBadRequest {
+				if sampleRate <= 0 || (successes.Add(1)-1)%uint64(sampleRate) != 0 {
+					return
+				}
+			}
+			log.Printf("%s %s %d %s", r.Method, r.URL.Path, status, time.Since(start).Round(time.Millisecond))
+		})
+	}
+}
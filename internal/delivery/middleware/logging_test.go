@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoggingSamplesSuccessesButLogsEveryFailure(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	flags := log.Flags()
+	log.SetFlags(0)
+	t.Cleanup(func() { log.SetFlags(flags) })
+
+	handler := Logging(5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	serve := func(path string, n int) {
+		for range n {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+
+	serve("/ok", 20)
+	serve("/fail", 3)
+
+	var ok, failed int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		switch {
+		case strings.HasPrefix(line, "GET /ok 200 "):
+			ok++
+		case strings.HasPrefix(line, "GET /fail 500 "):
+			failed++
+		default:
+			t.Errorf("unexpected log line %q", line)
+		}
+	}
+	if ok != 4 {
+		t.Errorf("logged %d of 20 successful requests, want every fifth (4)", ok)
+	}
+	if failed != 3 {
+		t.Errorf("logged %d of 3 failed requests, want all of them", failed)
+	}
+}
@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"hf-scraper/internal/domain"
+)
+
+// batchStreamChunk is the number of IDs looked up per query while streaming
+// a batch; the response is flushed after each chunk.
+const batchStreamChunk = 100
+
+// ndjsonContentType is the media type of a streamed batch, one model per line.
+const ndjsonContentType = "application/x-ndjson"
+
+// GetModelsBatch looks up the models with the IDs in a {"ids": [...]} body,
+// leaving out the ones that are not stored. At most SERVER.MAX_BATCH_IDS IDs
+// are accepted. With "Accept: application/x-ndjson" the models are streamed
+// one JSON object per line as they are read; otherwise they are returned as
+// {"models": [...]}.
+// Path: /models/batch
+func (h *ModelHandlers) GetModelsBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if bodyTooLarge(w, err) {
+			return
+		}
+		http.Error(w, "Body must be a JSON object like {\"ids\": [\"author/name\"]}", http.StatusBadRequest)
+		return
+	}
+	if h.cfg.MaxBatchIDs > 0 && len(req.IDs) > h.cfg.MaxBatchIDs {
+		http.Error(w, fmt.Sprintf("At most %d IDs are accepted, got %d", h.cfg.MaxBatchIDs, len(req.IDs)), http.StatusBadRequest)
+		return
+	}
+
+	if acceptsNDJSON(r) {
+		h.streamModelsBatch(w, r, req.IDs)
+		return
+	}
+
+	models, err := h.service.GetModelsByIDs(r.Context(), req.IDs)
+	if err != nil {
+		log.Printf("Error looking up a batch of %d models: %v", len(req.IDs), err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if models == nil {
+		models = []domain.HuggingFaceModel{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"models": h.publicViews(models)})
+}
+
+// streamModelsBatch writes the models with the given IDs as NDJSON, looking
+// them up batchStreamChunk at a time. Once the first line is sent the status
+// can no longer change, so a later lookup error just ends the stream early.
+func (h *ModelHandlers) streamModelsBatch(w http.ResponseWriter, r *http.Request, ids []string) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	// The controller reaches the connection through the middleware wrappers.
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+
+	for start := 0; start < len(ids); start += batchStreamChunk {
+		chunk := ids[start:min(start+batchStreamChunk, len(ids))]
+		models, err := h.service.GetModelsByIDs(r.Context(), chunk)
+		if err != nil {
+			log.Printf("Error streaming a batch of %d models after %d IDs: %v", len(ids), start, err)
+			if start == 0 {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+		for i := range models {
+			if err := encoder.Encode(h.publicView(&models[i])); err != nil {
+				// The client went away.
+				return
+			}
+		}
+		controller.Flush()
+	}
+}
+
+// acceptsNDJSON reports whether the request asks for an NDJSON response.
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			if mediaType, _, err := mime.ParseMediaType(part); err == nil && mediaType == ndjsonContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
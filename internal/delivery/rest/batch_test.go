@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+)
+
+// batchService stores the models with an even number and records the IDs
+// of every lookup.
+type batchService struct {
+	fakeService
+	lookups [][]string
+}
+
+func (f *batchService) GetModelsByIDs(_ context.Context, ids []string) ([]domain.HuggingFaceModel, error) {
+	f.lookups = append(f.lookups, ids)
+	var models []domain.HuggingFaceModel
+	for _, id := range ids {
+		var n int
+		fmt.Sscanf(id, "a/m%d", &n)
+		if n%2 == 0 {
+			models = append(models, domain.HuggingFaceModel{ID: id})
+		}
+	}
+	return models, nil
+}
+
+// batchBody returns a batch request body for the IDs a/m0 to a/m<n-1>.
+func batchBody(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("a/m%d", i)
+	}
+	body, _ := json.Marshal(map[string][]string{"ids": ids})
+	return string(body)
+}
+
+func TestBatchStreamsNDJSONWhenAccepted(t *testing.T) {
+	svc := &batchService{}
+	mux := newTestMux(svc, config.ServerConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/models/batch", strings.NewReader(batchBody(250)))
+	req.Header.Set("Accept", "application/json;q=0.5, application/x-ndjson")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+	var lines int
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var model map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &model); err != nil {
+			t.Fatalf("line %d is not a JSON object: %q", lines+1, scanner.Text())
+		}
+		if want := fmt.Sprintf("a/m%d", lines*2); model["id"] != want {
+			t.Errorf("line %d is %v, want %s", lines+1, model["id"], want)
+		}
+		lines++
+	}
+	if lines != 125 {
+		t.Errorf("streamed %d models, want 125", lines)
+	}
+	if len(svc.lookups) != 3 || len(svc.lookups[0]) != 100 || len(svc.lookups[2]) != 50 {
+		t.Errorf("looked up %d chunks, want 3 of at most 100 IDs", len(svc.lookups))
+	}
+}
+
+func TestBatchReturnsAnArrayByDefault(t *testing.T) {
+	mux := newTestMux(&batchService{}, config.ServerConfig{})
+
+	rec := serve(mux, http.MethodPost, "/models/batch", batchBody(5))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+	var body struct {
+		Models []map[string]any `json:"models"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Models) != 3 {
+		t.Errorf("returned %d models, want a/m0, a/m2 and a/m4", len(body.Models))
+	}
+}
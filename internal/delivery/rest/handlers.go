@@ -2,28 +2,98 @@
 package rest
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"hf-scraper/internal/config"
 	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
 )
 
 // dataService defines the interface required by the handlers from the core service.
 // This keeps the delivery layer decoupled from the full service implementation.
 type dataService interface {
 	GetModelByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error)
+	GetStatus(ctx context.Context) (*domain.StatusReport, error)
+	DeleteModelsByAuthor(ctx context.Context, author string) (int64, error)
+	GetSummary(ctx context.Context) (*domain.StatsSummary, error)
+	GetRelatedModels(ctx context.Context, id string, limit int) ([]domain.HuggingFaceModel, error)
+	GetShardRecommendation(ctx context.Context) (*domain.ShardRecommendation, error)
+	GetRandomModels(ctx context.Context, n int) ([]domain.HuggingFaceModel, error)
+	SearchModels(ctx context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+	GetModelsByTask(ctx context.Context, tag string, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+	GetBrokerTopics() []domain.TopicSubscribers
+	ImportModels(ctx context.Context, r io.Reader) (int, error)
+	GetGatedModels(ctx context.Context, gated bool, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+	GetModelDiff(ctx context.Context, id string, from, to time.Time) (*domain.ModelDiff, error)
+	GetRecentlyModified(ctx context.Context, window time.Duration, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+	GetAuthorCounts(ctx context.Context, opts service.SearchOptions) ([]domain.AuthorCount, int64, error)
+	RescanWatch(since time.Time)
+	GetTrend(ctx context.Context, field, interval string) ([]domain.TrendPoint, error)
+	GetModelsByIDs(ctx context.Context, ids []string) ([]domain.HuggingFaceModel, error)
+	GetDiscovery(ctx context.Context, interval string) ([]domain.TimeCount, error)
+	GetDuplicates(ctx context.Context, opts service.SearchOptions) ([]domain.DuplicateGroup, int64, error)
 }
 
+// Limits for the number of related models returned by GetRelatedModels.
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+// Limits for the sample size returned by GetRandomModels.
+const (
+	defaultRandomCount = 10
+	maxRandomCount     = 50
+)
+
 // ModelHandlers holds dependencies for model-related HTTP handlers.
 type ModelHandlers struct {
-	service dataService
+	service      dataService
+	cfg          config.ServerConfig
+	deniedFields map[string]bool
 }
 
 // NewModelHandlers creates a new handler struct.
-func NewModelHandlers(s dataService) *ModelHandlers {
-	return &ModelHandlers{service: s}
+func NewModelHandlers(s dataService, cfg config.ServerConfig) *ModelHandlers {
+	return &ModelHandlers{service: s, cfg: cfg, deniedFields: cfg.DeniedFields()}
+}
+
+// RegisterRoutes registers the JSON API routes on the given ServeMux.
+// The model detail route is left out because the UI owns "/models/" on the shared mux.
+func (h *ModelHandlers) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /status", h.GetStatus)
+	mux.HandleFunc("GET /stats/summary", h.GetSummary)
+	mux.HandleFunc("GET /stats/authors", h.GetAuthorCounts)
+	mux.HandleFunc("GET /stats/trends", h.GetTrend)
+	mux.HandleFunc("GET /stats/discovery", h.GetDiscovery)
+	mux.HandleFunc("GET /models", h.ListModels)
+	mux.HandleFunc("GET /tasks/{pipeline_tag}", h.GetModelsByTask)
+	mux.HandleFunc("GET /models/{author}/{name}/related", h.GetRelatedModels)
+	mux.HandleFunc("GET /models/{author}/{name}/diff", h.GetModelDiff)
+	mux.HandleFunc("GET /models/random", h.GetRandomModels)
+	mux.HandleFunc("GET /models/recent", h.GetRecentlyModified)
+	mux.HandleFunc("GET /models/gated", h.GetGatedModels)
+	mux.HandleFunc("POST /models/batch", h.limitBody(h.GetModelsBatch))
+
+	// Admin endpoints
+	mux.HandleFunc("GET /admin/models/{author}/{name}", h.requireAdmin(h.GetRawModel))
+	mux.HandleFunc("GET /admin/shard-recommendation", h.requireAdmin(h.GetShardRecommendation))
+	mux.HandleFunc("GET /admin/broker", h.requireAdmin(h.GetBrokerTopics))
+	mux.HandleFunc("GET /admin/duplicates", h.requireAdmin(h.GetDuplicates))
+	// Restores can be far larger than MaxRequestBytes, so the import is not limitBody'd.
+	mux.HandleFunc("POST /admin/import", h.requireAdmin(h.ImportModels))
+	mux.HandleFunc("POST /admin/rescan-watch", h.requireAdmin(h.RescanWatch))
+	mux.HandleFunc("DELETE /authors/{author}/models", h.requireAdmin(h.limitBody(h.DeleteModelsByAuthor)))
 }
 
 // GetModelByID handles the request for a single model.
@@ -31,15 +101,14 @@ func NewModelHandlers(s dataService) *ModelHandlers {
 func (h *ModelHandlers) GetModelByID(w http.ResponseWriter, r *http.Request) {
 	// Re-construct the model ID from the path segments.
 	// Example: /models/google-bert/bert-base-uncased -> "google-bert/bert-base-uncased"
-	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/models/"), "/")
-	if len(parts) != 2 {
-		http.Error(w, "Invalid model ID format. Expected {author}/{modelName}", http.StatusBadRequest)
+	modelID, err := domain.ParseModelID(strings.TrimPrefix(r.URL.EscapedPath(), "/models/"), h.cfg.LowercaseModelIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	modelID := strings.Join(parts, "/")
 
 	model, err := h.service.GetModelByID(r.Context(), modelID)
-	if err != nil {
+	if err != nil && !markStale(w, err) {
 		// Log the internal error
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
@@ -51,5 +120,291 @@ func (h *ModelHandlers) GetModelByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(model)
-}
\ No newline at end of file
+	json.NewEncoder(w).Encode(h.publicView(model))
+}
+
+// GetRawModel serves the full stored record of a model, including the fields
+// hidden from the public API.
+// Path: /admin/models/{author}/{name}
+func (h *ModelHandlers) GetRawModel(w http.ResponseWriter, r *http.Request) {
+	modelID := r.PathValue("author") + "/" + r.PathValue("name")
+	model, err := h.service.GetModelByID(r.Context(), modelID)
+	if err != nil && !markStale(w, err) {
+		log.Printf("Error reading model %s: %v", modelID, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if model == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model)
+}
+
+// GetStatus reports the daemon's current mode and scraper health.
+// Path: /status
+func (h *ModelHandlers) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetStatus(r.Context())
+	if err != nil {
+		log.Printf("Error reading service status: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetSummary serves aggregate counts over the whole collection.
+// Path: /stats/summary
+func (h *ModelHandlers) GetSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.service.GetSummary(r.Context())
+	if err != nil {
+		log.Printf("Error computing stats summary: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// GetRelatedModels lists the models sharing the most tags with the given model.
+// The optional "limit" query parameter is clamped to maxRelatedLimit.
+// Path: /models/{author}/{name}/related
+func (h *ModelHandlers) GetRelatedModels(w http.ResponseWriter, r *http.Request) {
+	modelID := r.PathValue("author") + "/" + r.PathValue("name")
+
+	limit := defaultRelatedLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxRelatedLimit)
+	}
+
+	related, err := h.service.GetRelatedModels(r.Context(), modelID, limit)
+	if err != nil {
+		log.Printf("Error finding models related to %s: %v", modelID, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if related == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.publicViews(related))
+}
+
+// GetTrend serves a time series of the total downloads or likes of the
+// models with recorded history, for trend charts. Without WATCHER.RECORD_HISTORY
+// the series is empty.
+// Path: /stats/trends?field=downloads|likes&interval=hour|day|week|month
+func (h *ModelHandlers) GetTrend(w http.ResponseWriter, r *http.Request) {
+	field := cmp.Or(r.URL.Query().Get("field"), "downloads")
+	interval := cmp.Or(r.URL.Query().Get("interval"), "day")
+
+	points, err := h.service.GetTrend(r.Context(), field, interval)
+	if errors.Is(err, service.ErrInvalidTrend) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error computing the %s trend: %v", field, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if points == nil {
+		points = []domain.TrendPoint{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"field":    field,
+		"interval": interval,
+		"series":   points,
+	})
+}
+
+// GetDiscovery serves the number of models first stored per time bucket, to
+// chart the discovery rate.
+// Path: /stats/discovery?interval=hour|day|week|month
+func (h *ModelHandlers) GetDiscovery(w http.ResponseWriter, r *http.Request) {
+	interval := cmp.Or(r.URL.Query().Get("interval"), "day")
+
+	counts, err := h.service.GetDiscovery(r.Context(), interval)
+	if errors.Is(err, service.ErrInvalidTrend) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error counting models by first-seen %s: %v", interval, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if counts == nil {
+		counts = []domain.TimeCount{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"interval": interval,
+		"series":   counts,
+	})
+}
+
+// GetModelDiff reports how a model's likes and downloads changed between the
+// snapshots closest to the "from" and "to" RFC 3339 timestamps. "to" defaults
+// to now. Snapshots are only recorded with WATCHER.RECORD_HISTORY.
+// Path: /models/{author}/{name}/diff?from=&to=
+func (h *ModelHandlers) GetModelDiff(w http.ResponseWriter, r *http.Request) {
+	modelID := r.PathValue("author") + "/" + r.PathValue("name")
+
+	from, err := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "from must be an RFC 3339 timestamp like 2024-01-02T15:04:05Z", http.StatusBadRequest)
+		return
+	}
+	var to time.Time
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "to must be an RFC 3339 timestamp like 2024-01-02T15:04:05Z", http.StatusBadRequest)
+			return
+		}
+		if to.Before(from) {
+			http.Error(w, "to must not be before from", http.StatusBadRequest)
+			return
+		}
+	}
+
+	diff, err := h.service.GetModelDiff(r.Context(), modelID, from, to)
+	if err != nil {
+		log.Printf("Error computing the diff of %s: %v", modelID, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if diff == nil {
+		http.Error(w, "No history recorded for this model", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// GetRandomModels serves a random sample of models, e.g. for spot-checking data quality.
+// The optional "n" query parameter is clamped to maxRandomCount.
+// Path: /models/random
+func (h *ModelHandlers) GetRandomModels(w http.ResponseWriter, r *http.Request) {
+	n := defaultRandomCount
+	if raw := r.URL.Query().Get("n"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			http.Error(w, "n must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		n = min(parsed, maxRandomCount)
+	}
+
+	models, err := h.service.GetRandomModels(r.Context(), n)
+	if err != nil {
+		log.Printf("Error sampling models: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.publicViews(models))
+}
+
+// DeleteModelsByAuthor purges all models of an author and reports how many were removed.
+// Path: DELETE /authors/{author}/models
+func (h *ModelHandlers) DeleteModelsByAuthor(w http.ResponseWriter, r *http.Request) {
+	author := r.PathValue("author")
+	deleted, err := h.service.DeleteModelsByAuthor(r.Context(), author)
+	if err != nil {
+		log.Printf("Error deleting models by author %s: %v", author, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"author":       author,
+		"deletedCount": deleted,
+	})
+}
+
+// GetShardRecommendation analyzes the collection size and suggests a shard key and indexes.
+// Path: /admin/shard-recommendation
+func (h *ModelHandlers) GetShardRecommendation(w http.ResponseWriter, r *http.Request) {
+	recommendation, err := h.service.GetShardRecommendation(r.Context())
+	if err != nil {
+		log.Printf("Error computing shard recommendation: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recommendation)
+}
+
+// RescanWatch makes the next watch cycle re-examine the models of the listing
+// changed after the optional "since" RFC 3339 timestamp, following at most
+// WATCHER.MAX_PAGES_PER_CYCLE pages, or all of those pages if it is omitted.
+// Past the limit, later cycles only pick up models newer than the stored ones.
+// Path: /admin/rescan-watch?since=
+func (h *ModelHandlers) RescanWatch(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, raw); err != nil {
+			http.Error(w, "since must be an RFC 3339 timestamp like 2024-01-02T15:04:05Z", http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.service.RescanWatch(since)
+	writeJSON(w, http.StatusAccepted, map[string]any{"since": since})
+}
+
+// ImportModels restores models from a JSON Lines request body. A malformed
+// record is answered with 400; the batches before it stay written.
+// Path: /admin/import
+func (h *ModelHandlers) ImportModels(w http.ResponseWriter, r *http.Request) {
+	imported, err := h.service.ImportModels(r.Context(), r.Body)
+	if errors.Is(err, service.ErrMalformedImport) {
+		http.Error(w, fmt.Sprintf("%v (imported %d models before it)", err, imported), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error importing models after %d written: %v", imported, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"importedCount": imported})
+}
+
+// GetBrokerTopics lists the event broker's active topics and their
+// subscriber counts, to help diagnose events that never arrive.
+// Path: /admin/broker
+func (h *ModelHandlers) GetBrokerTopics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"topics": h.service.GetBrokerTopics()})
+}
+
+// markStale reports whether err is a *service.StaleError, in which case the
+// accompanying model may still be served. The response is then flagged with
+// the Warning and Age headers.
+func markStale(w http.ResponseWriter, err error) bool {
+	var stale *service.StaleError
+	if !errors.As(err, &stale) {
+		return false
+	}
+	w.Header().Set("Warning", `110 - "Response is Stale"`)
+	w.Header().Set("Age", strconv.Itoa(int(stale.Age.Seconds())))
+	return true
+}
+
+// writeJSON encodes v as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,358 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// fakeService implements the dataService methods the tests use. Calling any
+// other method panics on the nil embedded interface.
+type fakeService struct {
+	dataService
+	models  map[string]*domain.HuggingFaceModel
+	summary *domain.StatsSummary
+	// requestedIDs records the IDs passed to GetModelByID.
+	requestedIDs []string
+}
+
+func (f *fakeService) GetModelByID(_ context.Context, id string) (*domain.HuggingFaceModel, error) {
+	f.requestedIDs = append(f.requestedIDs, id)
+	return f.models[id], nil
+}
+
+// newTestMux registers the handlers over svc the way the daemon does, with
+// the model detail route the API server adds.
+func newTestMux(svc dataService, cfg config.ServerConfig) *http.ServeMux {
+	h := NewModelHandlers(svc, cfg)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/models/", h.GetModelByID)
+	h.RegisterRoutes(mux)
+	return mux
+}
+
+// serve sends a request with the given method, target and body to mux.
+func serve(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestGetModelByIDDecodesEscapedPaths(t *testing.T) {
+	for _, tc := range []struct {
+		target string
+		wantID string
+	}{
+		{target: "/models/google-bert/bert-base-uncased", wantID: "google-bert/bert-base-uncased"},
+		{target: "/models/google-bert%2Fbert-base-uncased", wantID: "google-bert/bert-base-uncased"},
+		{target: "/models/org/model%20v2", wantID: "org/model v2"},
+		{target: "/models/gpt2", wantID: "gpt2"},
+	} {
+		svc := &fakeService{models: map[string]*domain.HuggingFaceModel{tc.wantID: {ID: tc.wantID}}}
+		rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, tc.target, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200: %s", tc.target, rec.Code, rec.Body)
+			continue
+		}
+		if len(svc.requestedIDs) != 1 || svc.requestedIDs[0] != tc.wantID {
+			t.Errorf("%s: looked up %q, want %q", tc.target, svc.requestedIDs, tc.wantID)
+		}
+	}
+}
+
+func TestGetModelByIDNormalizesTrailingSlashAndCase(t *testing.T) {
+	for _, tc := range []struct {
+		target    string
+		lowercase bool
+		wantID    string
+	}{
+		{target: "/models/google-bert/bert-base-uncased/", wantID: "google-bert/bert-base-uncased"},
+		{target: "/models/gpt2/", wantID: "gpt2"},
+		{target: "/models/Qwen/Qwen2-7B", lowercase: false, wantID: "Qwen/Qwen2-7B"},
+		{target: "/models/Qwen/Qwen2-7B/", lowercase: true, wantID: "qwen/qwen2-7b"},
+		{target: "/models/Qwen%2FQwen2-7B", lowercase: true, wantID: "qwen/qwen2-7b"},
+	} {
+		svc := &fakeService{models: map[string]*domain.HuggingFaceModel{tc.wantID: {ID: tc.wantID}}}
+		rec := serve(newTestMux(svc, config.ServerConfig{LowercaseModelIDs: tc.lowercase}), http.MethodGet, tc.target, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s (lowercase %v): status = %d, want 200: %s", tc.target, tc.lowercase, rec.Code, rec.Body)
+			continue
+		}
+		if len(svc.requestedIDs) != 1 || svc.requestedIDs[0] != tc.wantID {
+			t.Errorf("%s (lowercase %v): looked up %q, want %q", tc.target, tc.lowercase, svc.requestedIDs, tc.wantID)
+		}
+	}
+}
+
+func TestGetModelByIDRejectsMalformedPaths(t *testing.T) {
+	for _, target := range []string{
+		"/models/" + strings.Repeat("a", domain.MaxModelIDLength) + "/b",
+		"/models/" + strings.Repeat("%61", domain.MaxModelIDLength+1),
+		"/models/a/b/c",
+	} {
+		svc := &fakeService{}
+		rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, target, "")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%.40s...: status = %d, want 400", target, rec.Code)
+		}
+		if len(svc.requestedIDs) != 0 {
+			t.Errorf("%.40s...: the service was queried for %q", target, svc.requestedIDs)
+		}
+	}
+}
+
+func (f *fakeService) GetSummary(context.Context) (*domain.StatsSummary, error) {
+	return f.summary, nil
+}
+
+func TestGetSummaryResponseShape(t *testing.T) {
+	svc := &fakeService{summary: &domain.StatsSummary{
+		TotalModels:        3,
+		GatedModels:        1,
+		TotalDownloads:     42,
+		TopPipelineTags:    []domain.TagCount{{Tag: "text-generation", Count: 2}},
+		NewestLastModified: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, "/stats/summary", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"totalModels", "gatedModels", "privateModels", "totalDownloads", "topPipelineTags", "newestLastModified"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("the response has no %q key: %s", key, rec.Body)
+		}
+	}
+	tags, _ := body["topPipelineTags"].([]any)
+	if len(tags) != 1 || tags[0].(map[string]any)["count"] != float64(2) {
+		t.Errorf("topPipelineTags = %v, want the one tag counted", body["topPipelineTags"])
+	}
+}
+
+func (f *fakeService) GetModelsByIDs(_ context.Context, ids []string) ([]domain.HuggingFaceModel, error) {
+	var models []domain.HuggingFaceModel
+	for _, id := range ids {
+		if model, ok := f.models[id]; ok {
+			models = append(models, *model)
+		}
+	}
+	return models, nil
+}
+
+func TestOversizedBodiesAreRejected(t *testing.T) {
+	mux := newTestMux(&fakeService{}, config.ServerConfig{MaxRequestBytes: 64})
+	body := `{"ids": ["` + strings.Repeat("a", 100) + `/model"]}`
+
+	rec := serve(mux, http.MethodPost, "/models/batch", body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d for a declared oversized body, want 413", rec.Code)
+	}
+
+	// Without a Content-Length the limit is only hit while decoding.
+	req := httptest.NewRequest(http.MethodPost, "/models/batch", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d for an oversized streamed body, want 413", rec.Code)
+	}
+
+	rec = serve(mux, http.MethodPost, "/models/batch", `{"ids": ["a/model"]}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d for a body under the limit, want 200: %s", rec.Code, rec.Body)
+	}
+}
+
+// decodeObject decodes a JSON object response body.
+func decodeObject(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body, err)
+	}
+	return body
+}
+
+func TestDenylistHidesFieldsFromPublicResponsesOnly(t *testing.T) {
+	model := &domain.HuggingFaceModel{ID: "a/model", SHA: "abc123", Likes: 3, Siblings: []domain.Sibling{{Rfilename: "config.json"}}}
+	svc := &fakeService{models: map[string]*domain.HuggingFaceModel{"a/model": model}}
+	mux := newTestMux(svc, config.ServerConfig{PublicFieldDenylist: []string{"sha", "siblings"}, AdminToken: "secret"})
+
+	public := decodeObject(t, serve(mux, http.MethodGet, "/models/a/model", ""))
+	for _, field := range []string{"sha", "siblings"} {
+		if _, ok := public[field]; ok {
+			t.Errorf("the public response has the denied field %q", field)
+		}
+	}
+	if public["likes"] != float64(3) {
+		t.Errorf("public likes = %v, want 3", public["likes"])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/models/a/model", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("admin status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	admin := decodeObject(t, rec)
+	if admin["sha"] != "abc123" || admin["siblings"] == nil {
+		t.Errorf("the admin response = %v, want the denied fields included", admin)
+	}
+}
+
+// samplingService records the sample sizes GetRandomModels is asked for.
+type samplingService struct {
+	fakeService
+	sizes []int
+}
+
+func (f *samplingService) GetRandomModels(_ context.Context, n int) ([]domain.HuggingFaceModel, error) {
+	f.sizes = append(f.sizes, n)
+	return []domain.HuggingFaceModel{}, nil
+}
+
+func TestGetRandomModelsClampsSampleSize(t *testing.T) {
+	for _, tc := range []struct {
+		target   string
+		wantCode int
+		wantSize int
+	}{
+		{target: "/models/random", wantCode: http.StatusOK, wantSize: defaultRandomCount},
+		{target: "/models/random?n=3", wantCode: http.StatusOK, wantSize: 3},
+		{target: "/models/random?n=100000", wantCode: http.StatusOK, wantSize: maxRandomCount},
+		{target: "/models/random?n=0", wantCode: http.StatusBadRequest},
+		{target: "/models/random?n=ten", wantCode: http.StatusBadRequest},
+	} {
+		svc := &samplingService{}
+		rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, tc.target, "")
+		if rec.Code != tc.wantCode {
+			t.Errorf("%s: status = %d, want %d", tc.target, rec.Code, tc.wantCode)
+			continue
+		}
+		if tc.wantCode == http.StatusOK && (len(svc.sizes) != 1 || svc.sizes[0] != tc.wantSize) {
+			t.Errorf("%s: sampled %v, want %d", tc.target, svc.sizes, tc.wantSize)
+		}
+	}
+}
+
+// failingService answers GetModelByID with model and err.
+type failingService struct {
+	fakeService
+	model *domain.HuggingFaceModel
+	err   error
+}
+
+func (f *failingService) GetModelByID(context.Context, string) (*domain.HuggingFaceModel, error) {
+	return f.model, f.err
+}
+
+func TestStaleModelsAreServedWithWarning(t *testing.T) {
+	dbDown := errors.New("server selection timeout")
+	cached := &domain.HuggingFaceModel{ID: "org/model"}
+
+	svc := &failingService{model: cached, err: &service.StaleError{Age: 90 * time.Second, Err: dbDown}}
+	rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, "/models/org/model", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale model: status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Warning"); !strings.HasPrefix(got, "110 ") {
+		t.Errorf("Warning = %q, want a 110 stale warning", got)
+	}
+	if got := rec.Header().Get("Age"); got != "90" {
+		t.Errorf("Age = %q, want 90", got)
+	}
+
+	svc = &failingService{err: dbDown}
+	rec = serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, "/models/org/model", "")
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Warning") != "" {
+		t.Errorf("uncached model: status = %d, Warning = %q, want a plain 500", rec.Code, rec.Header().Get("Warning"))
+	}
+}
+
+// brokerService reports fixed broker topics.
+type brokerService struct {
+	fakeService
+	topics []domain.TopicSubscribers
+}
+
+func (f *brokerService) GetBrokerTopics() []domain.TopicSubscribers {
+	return f.topics
+}
+
+func TestGetBrokerTopicsRequiresAdminAndReportsCounts(t *testing.T) {
+	svc := &brokerService{topics: []domain.TopicSubscribers{{Topic: "model:new", Subscribers: 2}, {Topic: "mode_change", Subscribers: 1}}}
+	mux := newTestMux(svc, config.ServerConfig{AdminToken: "secret"})
+
+	if rec := serve(mux, http.MethodGet, "/admin/broker", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a token: status = %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/broker", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Topics []domain.TopicSubscribers `json:"topics"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(body.Topics, svc.topics) {
+		t.Errorf("topics = %+v, want %+v", body.Topics, svc.topics)
+	}
+}
+
+// rescanService records the rescans requested.
+type rescanService struct {
+	fakeService
+	rescans []time.Time
+}
+
+func (f *rescanService) RescanWatch(since time.Time) {
+	f.rescans = append(f.rescans, since)
+}
+
+func TestRescanWatchSetsTheOverride(t *testing.T) {
+	svc := &rescanService{}
+	mux := newTestMux(svc, config.ServerConfig{AdminToken: "secret"})
+	rescan := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/rescan-watch"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := rescan("?since=2025-06-01T10:00:00Z"); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	if rec := rescan(""); rec.Code != http.StatusAccepted {
+		t.Fatalf("without since: status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	if rec := rescan("?since=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed since: status = %d, want 400", rec.Code)
+	}
+	want := []time.Time{time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), {}}
+	if !slices.EqualFunc(svc.rescans, want, time.Time.Equal) {
+		t.Errorf("rescans = %v, want %v", svc.rescans, want)
+	}
+}
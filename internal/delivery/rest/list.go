@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// Default and maximum look-back window of GetRecentlyModified.
+const (
+	defaultRecentWindow = 24 * time.Hour
+	maxRecentWindow     = 30 * 24 * time.Hour
+)
+
+// Defaults and limits for the paginated model list. maxListPage keeps the
+// skip, (page-1)*limit, far from overflowing.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	maxListPage      = 100_000
+	defaultListSort  = "likes"
+)
+
+// listSortKeys are the fields the model list can be sorted by.
+var listSortKeys = map[string]bool{
+	"likes":        true,
+	"downloads":    true,
+	"lastModified": true,
+}
+
+// ListModels serves a page of models matching the optional search query.
+// Unlike the UI search, malformed parameters are rejected with a 400 rather
+// than replaced by defaults; omitted ones still use the defaults.
+// Path: /models?q=&literal=&page=&limit=&sort=&order=
+func (h *ModelHandlers) ListModels(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	models, total, err := h.service.SearchModels(r.Context(), opts)
+	if errors.Is(err, service.ErrInvalidSearchPattern) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error listing models: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if models == nil {
+		models = []domain.HuggingFaceModel{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"models": h.publicViews(models),
+		"total":  total,
+		"page":   opts.Page,
+		"limit":  opts.Limit,
+	})
+}
+
+// GetModelsByTask serves a page of the models for one pipeline tag, with the
+// total count for the task. Paging and sorting take the same parameters as
+// ListModels; an unknown tag returns an empty page and a zero total.
+// Path: /tasks/{pipeline_tag}?page=&limit=&sort=&order=
+func (h *ModelHandlers) GetModelsByTask(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("pipeline_tag")
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	models, total, err := h.service.GetModelsByTask(r.Context(), tag, opts)
+	if errors.Is(err, service.ErrInvalidSearchPattern) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error listing models for task %s: %v", tag, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if models == nil {
+		models = []domain.HuggingFaceModel{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pipelineTag": tag,
+		"models":      h.publicViews(models),
+		"total":       total,
+		"page":        opts.Page,
+		"limit":       opts.Limit,
+	})
+}
+
+// GetRecentlyModified serves a page of the models modified within a window,
+// most recent first. The optional "window" query parameter is a Go duration
+// such as "24h" or "90m", clamped to maxRecentWindow; page and limit work as
+// for ListModels.
+// Path: /models/recent?window=&page=&limit=
+func (h *ModelHandlers) GetRecentlyModified(w http.ResponseWriter, r *http.Request) {
+	window := defaultRecentWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("window must be a positive duration like 24h, got %q", raw), http.StatusBadRequest)
+			return
+		}
+		window = min(parsed, maxRecentWindow)
+	}
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	models, total, err := h.service.GetRecentlyModified(r.Context(), window, opts)
+	if err != nil {
+		log.Printf("Error listing recently modified models: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if models == nil {
+		models = []domain.HuggingFaceModel{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"window": window.String(),
+		"models": h.publicViews(models),
+		"total":  total,
+		"page":   opts.Page,
+		"limit":  opts.Limit,
+	})
+}
+
+// GetGatedModels serves the same page of the gated models (auto, manual or
+// true) and of the ungated ones, each with its total. page, limit, sort and
+// order work as for ListModels.
+// Path: /models/gated?page=&limit=&sort=&order=
+func (h *ModelHandlers) GetGatedModels(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groups := make(map[string]any, 2)
+	for name, gated := range map[string]bool{"gated": true, "ungated": false} {
+		models, total, err := h.service.GetGatedModels(r.Context(), gated, opts)
+		if err != nil {
+			log.Printf("Error listing %s models: %v", name, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if models == nil {
+			models = []domain.HuggingFaceModel{}
+		}
+		groups[name] = map[string]any{"models": h.publicViews(models), "total": total}
+	}
+	groups["page"], groups["limit"] = opts.Page, opts.Limit
+
+	writeJSON(w, http.StatusOK, groups)
+}
+
+// GetAuthorCounts serves an authors leaderboard: a page of the authors of
+// the models matching the search, by descending number of models, with the
+// total number of authors. q, literal, page and limit work as for ListModels.
+// Path: /stats/authors?q=&literal=&page=&limit=
+func (h *ModelHandlers) GetAuthorCounts(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	authors, total, err := h.service.GetAuthorCounts(r.Context(), opts)
+	if errors.Is(err, service.ErrInvalidSearchPattern) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error counting models by author: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if authors == nil {
+		authors = []domain.AuthorCount{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"authors": authors,
+		"total":   total,
+		"page":    opts.Page,
+		"limit":   opts.Limit,
+	})
+}
+
+// GetDuplicates serves a page of the SHAs shared by several models matching
+// a search, with the IDs sharing each, to find content stored twice.
+// Path: /admin/duplicates?q=&literal=&page=&limit=
+func (h *ModelHandlers) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groups, total, err := h.service.GetDuplicates(r.Context(), opts)
+	if errors.Is(err, service.ErrInvalidSearchPattern) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding duplicate models: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if groups == nil {
+		groups = []domain.DuplicateGroup{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"duplicates": groups,
+		"total":      total,
+		"page":       opts.Page,
+		"limit":      opts.Limit,
+	})
+}
+
+// parseListParams builds the search options of a list request, reporting the
+// first malformed or out-of-range parameter.
+func parseListParams(query url.Values) (service.SearchOptions, error) {
+	opts := service.SearchOptions{
+		Query:     query.Get("q"),
+		Literal:   query.Get("literal") == "true",
+		SortBy:    defaultListSort,
+		SortOrder: -1,
+		Page:      1,
+		Limit:     defaultListLimit,
+	}
+
+	if raw := query.Get("page"); raw != "" {
+		page, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("page must be an integer, got %q", raw)
+		}
+		if page < 1 || page > maxListPage {
+			return opts, fmt.Errorf("page must be between 1 and %d, got %d", maxListPage, page)
+		}
+		opts.Page = page
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("limit must be an integer, got %q", raw)
+		}
+		if limit < 1 || limit > maxListLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d, got %d", maxListLimit, limit)
+		}
+		opts.Limit = limit
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		if !listSortKeys[raw] {
+			return opts, fmt.Errorf("sort must be one of likes, downloads or lastModified, got %q", raw)
+		}
+		opts.SortBy = raw
+	}
+
+	if raw := query.Get("order"); raw != "" {
+		switch raw {
+		case "1":
+			opts.SortOrder = 1
+		case "-1":
+			opts.SortOrder = -1
+		default:
+			return opts, fmt.Errorf("order must be 1 or -1, got %q", raw)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// listingService records the options of the list queries it receives.
+type listingService struct {
+	fakeService
+	searches []service.SearchOptions
+	// err is returned by every query.
+	err error
+}
+
+func (f *listingService) SearchModels(_ context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	f.searches = append(f.searches, opts)
+	return nil, 0, f.err
+}
+
+func (f *listingService) GetModelsByTask(_ context.Context, _ string, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	f.searches = append(f.searches, opts)
+	return nil, 0, f.err
+}
+
+func TestListRejectsMalformedParams(t *testing.T) {
+	for _, query := range []string{
+		"page=abc",
+		"page=0",
+		"page=-3",
+		"page=1.5",
+		"page=100001",
+		"page=9223372036854775807",
+		"page=99999999999999999999",
+		"limit=ten",
+		"limit=0",
+		"limit=101",
+		"sort=name",
+		"order=asc",
+	} {
+		for _, path := range []string{"/models", "/tasks/text-generation"} {
+			svc := &listingService{}
+			rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, path+"?"+query, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s?%s: status = %d, want 400", path, query, rec.Code)
+			}
+			if name, _, _ := strings.Cut(query, "="); !strings.Contains(rec.Body.String(), name) {
+				t.Errorf("%s?%s: message %q does not name the parameter", path, query, rec.Body)
+			}
+			if len(svc.searches) != 0 {
+				t.Errorf("%s?%s: the service was queried", path, query)
+			}
+		}
+	}
+}
+
+func TestListUsesDefaultsForOmittedParams(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  service.SearchOptions
+	}{
+		{query: "", want: service.SearchOptions{SortBy: defaultListSort, SortOrder: -1, Page: 1, Limit: defaultListLimit}},
+		{
+			query: "q=llama&page=100000&limit=100&sort=downloads&order=1",
+			want:  service.SearchOptions{Query: "llama", SortBy: "downloads", SortOrder: 1, Page: maxListPage, Limit: maxListLimit},
+		},
+	} {
+		svc := &listingService{}
+		rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, "/models?"+tc.query, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%q: status = %d, want 200: %s", tc.query, rec.Code, rec.Body)
+			continue
+		}
+		if len(svc.searches) != 1 || svc.searches[0] != tc.want {
+			t.Errorf("%q: searched %+v, want %+v", tc.query, svc.searches, tc.want)
+		}
+	}
+}
+
+func TestListRejectsInvalidSearchPatterns(t *testing.T) {
+	for _, path := range []string{"/models", "/tasks/text-generation"} {
+		svc := &listingService{err: fmt.Errorf("%w: missing closing ]", service.ErrInvalidSearchPattern)}
+		rec := serve(newTestMux(svc, config.ServerConfig{}), http.MethodGet, path+"?q=%5B", "")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, rec.Code)
+		}
+	}
+}
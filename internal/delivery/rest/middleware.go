@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// requireAdmin guards a handler behind the configured admin bearer token.
+// When no token is configured the admin endpoints are disabled entirely.
+func (h *ModelHandlers) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.AdminToken == "" {
+			http.Error(w, "Admin API is disabled", http.StatusForbidden)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// limitBody caps the request body of mutating endpoints at the configured
+// MaxRequestBytes. Requests that declare a larger body are rejected with 413
+// up front; for the rest, reads past the limit fail with *http.MaxBytesError,
+// which handlers must answer with 413 as well.
+func (h *ModelHandlers) limitBody(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.MaxRequestBytes > 0 {
+			if r.ContentLength > h.cfg.MaxRequestBytes {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxRequestBytes)
+		}
+		next(w, r)
+	}
+}
+
+// bodyTooLarge answers 413 and returns true if err came from reading past
+// the limit set by limitBody.
+func bodyTooLarge(w http.ResponseWriter, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+	return true
+}
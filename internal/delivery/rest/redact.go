@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"encoding/json"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+)
+
+// publicModel serializes a model without the fields on the public denylist.
+// It wraps the model instead of changing its struct tags, so admin endpoints
+// can still serve the full record.
+type publicModel struct {
+	model  any
+	denied map[string]bool
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p publicModel) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(p.model)
+	if err != nil || len(p.denied) == 0 {
+		return raw, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for field := range p.denied {
+		delete(fields, field)
+	}
+	return json.Marshal(fields)
+}
+
+// publicView returns model in the shape served by public endpoints: in the
+// configured response format, without the denylisted fields.
+func (h *ModelHandlers) publicView(model *domain.HuggingFaceModel) any {
+	var view any = model
+	if h.cfg.ResponseFormat == config.ResponseFormatHF {
+		view = model.HFView()
+	}
+	if len(h.deniedFields) == 0 {
+		return view
+	}
+	return publicModel{model: view, denied: h.deniedFields}
+}
+
+// publicViews is the slice variant of publicView.
+func (h *ModelHandlers) publicViews(models []domain.HuggingFaceModel) any {
+	if len(h.deniedFields) == 0 && h.cfg.ResponseFormat != config.ResponseFormatHF {
+		return models
+	}
+	views := make([]any, len(models))
+	for i := range models {
+		views[i] = h.publicView(&models[i])
+	}
+	return views
+}
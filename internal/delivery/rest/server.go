@@ -5,6 +5,8 @@ import (
 	"context"
 	"net/http"
 	"time"
+
+	"hf-scraper/internal/config"
 )
 
 // Server is the HTTP server for the read-only API.
@@ -13,15 +15,16 @@ type Server struct {
 }
 
 // NewServer creates and configures a new API server.
-func NewServer(port string, service dataService) *Server {
-	modelHandlers := NewModelHandlers(service)
+func NewServer(cfg config.ServerConfig, service dataService) *Server {
+	modelHandlers := NewModelHandlers(service, cfg)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/models/", modelHandlers.GetModelByID) // Trailing slash handles sub-paths
+	modelHandlers.RegisterRoutes(mux)
 
 	return &Server{
 		httpServer: &http.Server{
-			Addr:         ":" + port,
+			Addr:         ":" + cfg.Port,
 			Handler:      mux,
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: 10 * time.Second,
@@ -38,4 +41,4 @@ func (s *Server) Start() error {
 // Stop gracefully shuts down the server.
 func (s *Server) Stop(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
-}
\ No newline at end of file
+}
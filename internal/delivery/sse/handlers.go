@@ -0,0 +1,102 @@
+// Path: internal/delivery/sse/handlers.go
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/events"
+	"hf-scraper/internal/service"
+)
+
+// keepAliveInterval is how often an idle stream sends a comment line, so
+// proxies don't close it.
+const keepAliveInterval = 30 * time.Second
+
+// retryAfterSeconds is suggested to clients refused because the cap is reached.
+const retryAfterSeconds = "5"
+
+// Handlers streams broker events to clients as Server-Sent Events.
+type Handlers struct {
+	broker   *events.Broker
+	maxConns int64
+	// active counts the open streams.
+	active atomic.Int64
+}
+
+// NewHandlers creates the SSE handlers. cfg.MaxSSEConnections caps the open streams.
+func NewHandlers(broker *events.Broker, cfg config.ServerConfig) *Handlers {
+	return &Handlers{broker: broker, maxConns: cfg.MaxSSEConnections}
+}
+
+// RegisterRoutes registers the event stream route on the given ServeMux.
+func (h *Handlers) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /events", h.Stream)
+}
+
+// Stream subscribes to a broker topic and forwards its events until the
+// client disconnects. The optional "topic" query parameter defaults to the
+// service mode changes.
+// Path: /events?topic=
+func (h *Handlers) Stream(w http.ResponseWriter, r *http.Request) {
+	if h.active.Add(1) > h.maxConns && h.maxConns > 0 {
+		h.active.Add(-1)
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		http.Error(w, "Too many event streams, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.active.Add(-1)
+
+	topic := r.URL.Query().Get("topic")
+	if topic == "" {
+		topic = service.EventModeChange
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		log.Printf("Error clearing the event stream write deadline: %v", err)
+	}
+
+	sub := h.broker.Subscribe(topic)
+	defer h.broker.Unsubscribe(topic, sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("Error starting event stream: %v", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-sub:
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				log.Printf("Error encoding %s event: %v", event.Topic, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
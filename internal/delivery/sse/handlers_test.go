@@ -0,0 +1,70 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/events"
+	"hf-scraper/internal/metrics"
+)
+
+// openStream starts an event stream request and returns its response once
+// the headers arrive. Cancelling ctx closes the stream.
+func openStream(t *testing.T, ctx context.Context, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStreamsPastTheCapAreRefused(t *testing.T) {
+	broker := events.NewBroker(metrics.Noop{})
+	t.Cleanup(broker.Close)
+	mux := http.NewServeMux()
+	NewHandlers(broker, config.ServerConfig{MaxSSEConnections: 2}).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	first, closeFirst := context.WithCancel(context.Background())
+	defer closeFirst()
+	for range 2 {
+		if resp := openStream(t, first, server.URL); resp.StatusCode != http.StatusOK {
+			t.Fatalf("stream under the cap: status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	refused := openStream(t, context.Background(), server.URL)
+	if refused.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("stream past the cap: status = %d, want 503", refused.StatusCode)
+	}
+	if got := refused.Header.Get("Retry-After"); got != retryAfterSeconds {
+		t.Errorf("Retry-After = %q, want %q", got, retryAfterSeconds)
+	}
+
+	// Closing the open streams frees their slots.
+	closeFirst()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := openStream(t, ctx, server.URL)
+		cancel()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %d after the streams closed, want a freed slot", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
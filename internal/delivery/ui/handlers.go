@@ -2,16 +2,17 @@
 package ui
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
-	"math"
 	"net/http"
-	"reflect"
 	"strconv"
 	"strings"
 
+	"hf-scraper/internal/config"
 	"hf-scraper/internal/domain"
 	"hf-scraper/internal/service"
 )
@@ -26,12 +27,39 @@ type dataService interface {
 type Handlers struct {
 	service   dataService
 	templates *template.Template
+	// hidden holds the model fields the templates must not render.
+	hidden map[string]bool
+	// sortBy and sortOrder order the index page and searches that don't pick a sort.
+	sortBy    string
+	sortOrder int
+	// staticMaxAge is how long browsers may cache static assets, in seconds.
+	staticMaxAge int
+	// lowercaseIDs lowercases model IDs parsed from detail page paths.
+	lowercaseIDs bool
+}
+
+// pageSize is the number of models per page of the index and search results.
+const pageSize = 20
+
+// lastPage returns the number of the last page of total results. An empty
+// result still has a first page.
+func lastPage(total int64) int64 {
+	return max((total+pageSize-1)/pageSize, 1)
+}
+
+// requiredTemplates lists every template the handlers render, directly or via includes.
+var requiredTemplates = []string{
+	"layout.html",
+	"index.html",
+	"model.html",
+	"model_table.html",
+	"pagination.html",
+	"search_results.html",
 }
 
 // NewHandlers creates a new UI handler struct.
-func NewHandlers(s dataService) *Handlers {
-	tpl := template.Must(template.ParseGlob("web/template/*.html"))
-	tpl = template.Must(tpl.ParseGlob("web/template/fragments/*.html"))
+func NewHandlers(s dataService, cfg config.ServerConfig) *Handlers {
+	tpl := template.Must(parseTemplates())
 	// Debug: Print all template names
 	fmt.Println("Loaded templates:")
 	for _, t := range tpl.Templates() {
@@ -39,20 +67,50 @@ func NewHandlers(s dataService) *Handlers {
 	}
 
 	return &Handlers{
-		service:   s,
-		templates: tpl,
+		service:      s,
+		templates:    tpl,
+		hidden:       cfg.DeniedFields(),
+		sortBy:       cfg.IndexSort,
+		sortOrder:    cfg.IndexSortOrder,
+		staticMaxAge: cfg.StaticMaxAgeSeconds,
+		lowercaseIDs: cfg.LowercaseModelIDs,
 	}
 }
 
+// parseTemplates loads all page and fragment templates from disk.
+func parseTemplates() (*template.Template, error) {
+	tpl, err := template.ParseGlob("web/template/*.html")
+	if err != nil {
+		return nil, err
+	}
+	return tpl.ParseGlob("web/template/fragments/*.html")
+}
+
+// CheckTemplates verifies that the templates on disk still parse and that
+// every required template is present, so a broken deploy fails readiness
+// instead of failing each request.
+func (h *Handlers) CheckTemplates() error {
+	tpl, err := parseTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+	for _, name := range requiredTemplates {
+		if tpl.Lookup(name) == nil || h.templates.Lookup(name) == nil {
+			return fmt.Errorf("required template %q is missing", name)
+		}
+	}
+	return nil
+}
+
 // RegisterRoutes registers all UI routes on the given ServeMux.
 func (h *Handlers) RegisterRoutes(mux *http.ServeMux) {
 	// Register most specific routes first.
 
 	// 1. Static files: Handles "/static/..."
-	fileServer := http.FileServer(http.Dir("./web/static"))
-	mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
+	mux.Handle("/static/", http.StripPrefix("/static/", staticFiles("./web/static", h.staticMaxAge)))
 	// 2. API-like endpoints for HTMX
 	mux.HandleFunc("/search", h.handleSearch)
+	mux.HandleFunc("GET /readyz", h.handleReadyz)
 
 	// 3. Model detail pages: Handles "/model/author/name"
 	mux.HandleFunc("/models/", h.handleShowModel)
@@ -61,6 +119,16 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/", h.handleShowIndex)
 }
 
+// handleReadyz reports whether the UI is able to render its pages.
+func (h *Handlers) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := h.CheckTemplates(); err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
 // handleShowIndex serves the main search page.
 func (h *Handlers) handleShowIndex(w http.ResponseWriter, r *http.Request) {
 	// This ensures that only the exact path "/" is handled here.
@@ -71,58 +139,80 @@ func (h *Handlers) handleShowIndex(w http.ResponseWriter, r *http.Request) {
 
 	models, total, _ := h.service.SearchModels(r.Context(), service.SearchOptions{
 		Page:      1,
-		Limit:     20,
-		SortBy:    "likes",
-		SortOrder: -1,
+		Limit:     pageSize,
+		SortBy:    h.sortBy,
+		SortOrder: h.sortOrder,
 	})
 
-	data := h.buildTemplateData(r, models, total)
-	fmt.Printf("Executing template: index.html\n")
-	fmt.Printf("Data keys: %v\n", reflect.ValueOf(data).MapKeys())
+	data := h.buildTemplateData(r, models, total, 1)
 
-	err := h.templates.ExecuteTemplate(w, "index.html", data)
-	if err != nil {
-		fmt.Printf("Template execution error: %v\n", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	h.render(w, "index.html", data)
 }
 
 // handleSearch is an HTMX endpoint that returns the search results.
 func (h *Handlers) handleSearch(w http.ResponseWriter, r *http.Request) {
 	// *** FIX 2: Correctly render a single response for HTMX ***
 	page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 64)
-	if page == 0 {
-		page = 1
-	}
+	page = max(page, 1)
 
+	sortBy, sortOrder := h.sortParams(r)
 	opts := service.SearchOptions{
-		Query:     r.URL.Query().Get("q"),
-		SortBy:    r.URL.Query().Get("sort"),
-		SortOrder: -1, // Default desc
-		Page:      page,
-		Limit:     20,
+		Query:         r.URL.Query().Get("q"),
+		CaseSensitive: r.URL.Query().Get("case") == "sensitive",
+		Literal:       r.URL.Query().Get("literal") == "true",
+		License:       r.URL.Query().Get("license"),
+		Library:       r.URL.Query().Get("library"),
+		Language:      r.URL.Query().Get("language"),
+		SortBy:        sortBy,
+		SortOrder:     sortOrder,
+		Page:          page,
+		Limit:         pageSize,
 	}
-	if r.URL.Query().Get("order") == "1" {
-		opts.SortOrder = 1
+	if opts.SortBy == "relevance" {
+		// Best match: exact and prefix ID matches first, then by likes.
+		opts.Relevance = true
+		opts.SortBy = "likes"
 	}
 
 	models, total, err := h.service.SearchModels(r.Context(), opts)
+	if errors.Is(err, service.ErrInvalidSearchPattern) {
+		http.Error(w, "Invalid search pattern. Tick \"Plain text\" to search for it literally.", http.StatusBadRequest)
+		return
+	}
+	if last := lastPage(total); err == nil && page > last {
+		// Past the end, e.g. a stale link after models were deleted: show
+		// the last page rather than an empty one.
+		opts.Page = last
+		models, total, err = h.service.SearchModels(r.Context(), opts)
+	}
 	if err != nil {
 		log.Printf("Error searching models: %v", err)
 		http.Error(w, "Failed to search models", http.StatusInternalServerError)
 		return
 	}
 
-	data := h.buildTemplateData(r, models, total)
+	data := h.buildTemplateData(r, models, total, opts.Page)
+	setPaginationHeaders(w, data)
+	w.Header().Set("X-Page-Hash", service.PageHash(models))
 	// Render the new wrapper template which contains both the table and pagination.
-	h.templates.ExecuteTemplate(w, "search_results.html", data)
+	h.render(w, "search_results.html", data)
 }
 
 // handleShowModel serves the model details page.
 func (h *Handlers) handleShowModel(w http.ResponseWriter, r *http.Request) {
-	modelID := strings.TrimPrefix(r.URL.Path, "/models/")
-	model, err := h.service.GetModelByID(r.Context(), modelID)
+	modelID, err := domain.ParseModelID(strings.TrimPrefix(r.URL.EscapedPath(), "/models/"), h.lowercaseIDs)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	model, err := h.service.GetModelByID(r.Context(), modelID)
+	var stale *service.StaleError
+	if errors.As(err, &stale) {
+		// The database is unreachable, show the last cached copy instead.
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+		w.Header().Set("Age", strconv.Itoa(int(stale.Age.Seconds())))
+	} else if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
@@ -132,37 +222,77 @@ func (h *Handlers) handleShowModel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := map[string]interface{}{
-		"Model": model,
+		"Model":       model,
+		"Hidden":      h.hidden,
+		"IsModelPage": true,
 	}
-	h.templates.ExecuteTemplate(w, "model.html", data)
+	h.render(w, "model.html", data)
 }
 
-// buildTemplateData is a helper to construct the data map for templates.
-func (h *Handlers) buildTemplateData(r *http.Request, models []domain.HuggingFaceModel, total int64) map[string]interface{} {
-	const pageSize = 20
-	page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 64)
-	if page == 0 {
-		page = 1
-	}
-	sortOrder, _ := strconv.Atoi(r.URL.Query().Get("order"))
-	if sortOrder == 0 {
-		sortOrder = -1 // Default to descending if not specified
+// render executes the named template into a buffer and writes it only if it
+// succeeded. A missing or failing template is logged and answered with a 500,
+// rather than a blank or truncated page sent with a 200. HTMX does not swap
+// error responses, so the search results keep their previous content.
+func (h *Handlers) render(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Printf("Error rendering template %s: %v", name, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// setPaginationHeaders exposes the pagination state of a search response as
+// headers, so client-side scripts can update controls without parsing the HTML.
+func setPaginationHeaders(w http.ResponseWriter, data map[string]any) {
+	w.Header().Set("X-Total-Count", fmt.Sprint(data["Total"]))
+	w.Header().Set("X-Page", fmt.Sprint(data["CurrentPage"]))
+	w.Header().Set("X-Total-Pages", fmt.Sprint(data["TotalPages"]))
+}
 
-	sortBy := r.URL.Query().Get("sort")
+// sortParams returns the requested sort field and order. A request without a
+// sort uses the configured index sort; an explicit sort defaults to descending.
+func (h *Handlers) sortParams(r *http.Request) (string, int) {
+	sortBy, sortOrder := r.URL.Query().Get("sort"), h.sortOrder
 	if sortBy == "" {
-		sortBy = "likes" // Default sort
+		sortBy = h.sortBy
+	} else {
+		sortOrder = -1
+	}
+	switch r.URL.Query().Get("order") {
+	case "1":
+		sortOrder = 1
+	case "-1":
+		sortOrder = -1
 	}
+	return sortBy, sortOrder
+}
+
+// buildTemplateData is a helper to construct the data map for templates.
+// page is clamped to the available pages, and so are the previous and next
+// page links built from it.
+func (h *Handlers) buildTemplateData(r *http.Request, models []domain.HuggingFaceModel, total int64, page int64) map[string]interface{} {
+	last := lastPage(total)
+	page = min(max(page, 1), last)
+	sortBy, sortOrder := h.sortParams(r)
 
 	return map[string]any{
 		"Models":      models,
 		"Query":       r.URL.Query().Get("q"),
+		"Case":        r.URL.Query().Get("case"),
+		"Literal":     r.URL.Query().Get("literal"),
+		"License":     r.URL.Query().Get("license"),
+		"Library":     r.URL.Query().Get("library"),
+		"Language":    r.URL.Query().Get("language"),
 		"SortBy":      sortBy,
 		"SortOrder":   sortOrder,
 		"Total":       total,
 		"CurrentPage": page,
-		"TotalPages":  int64(math.Ceil(float64(total) / float64(pageSize))),
-		"NextPage":    page + 1,
-		"PrevPage":    page - 1,
+		"TotalPages":  last,
+		"NextPage":    min(page+1, last),
+		"PrevPage":    max(page-1, 1),
+		"Hidden":      h.hidden,
 	}
 }
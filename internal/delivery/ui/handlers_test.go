@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// TestMain runs the tests from the repository root, where the handlers load
+// their templates from.
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../../.."); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeService serves total models named model-0, model-1, ... and records
+// the options of the searches it answered.
+type fakeService struct {
+	total    int64
+	models   map[string]*domain.HuggingFaceModel
+	searches []service.SearchOptions
+}
+
+func (f *fakeService) GetModelByID(_ context.Context, id string) (*domain.HuggingFaceModel, error) {
+	return f.models[id], nil
+}
+
+func (f *fakeService) SearchModels(_ context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	f.searches = append(f.searches, opts)
+	var models []domain.HuggingFaceModel
+	for i := (opts.Page - 1) * opts.Limit; i < min(opts.Page*opts.Limit, f.total); i++ {
+		models = append(models, domain.HuggingFaceModel{ID: fmt.Sprintf("model-%d", i)})
+	}
+	return models, f.total, nil
+}
+
+// newTestHandlers returns handlers over svc with their routes registered.
+func newTestHandlers(t *testing.T, svc dataService, cfg config.ServerConfig) (*Handlers, *http.ServeMux) {
+	t.Helper()
+	h := NewHandlers(svc, cfg)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, mux
+}
+
+// get serves a GET request for target.
+func get(mux http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestSearchSetsPaginationHeaders(t *testing.T) {
+	_, mux := newTestHandlers(t, &fakeService{total: 45}, config.ServerConfig{})
+
+	rec := get(mux, "/search?q=bert&page=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	for header, want := range map[string]string{
+		"X-Total-Count": "45",
+		"X-Page":        "2",
+		"X-Total-Pages": "3",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+// copyTemplates copies the templates into a new directory, except the ones
+// named in skip, and returns the directory.
+func copyTemplates(t *testing.T, skip ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, pattern := range []string{"web/template/*.html", "web/template/fragments/*.html"} {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range paths {
+			if slices.Contains(skip, filepath.Base(path)) {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			target := filepath.Join(dir, path)
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(target, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+func TestReadyzFailsWhenRequiredTemplateIsMissing(t *testing.T) {
+	_, mux := newTestHandlers(t, &fakeService{}, config.ServerConfig{})
+	if rec := get(mux, "/readyz"); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d with every template present, want 200: %s", rec.Code, rec.Body)
+	}
+
+	t.Chdir(copyTemplates(t, "model.html"))
+	rec := get(mux, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d with model.html missing, want 503", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "model.html") {
+		t.Errorf("body = %q, want it to name the missing template", rec.Body)
+	}
+}
+
+// patternService rejects queries the way the service does for a pattern
+// that is not a valid regular expression, unless it is searched literally.
+type patternService struct {
+	fakeService
+}
+
+func (f *patternService) SearchModels(ctx context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	if !opts.Literal && strings.Contains(opts.Query, "[") && !strings.Contains(opts.Query, "]") {
+		f.searches = append(f.searches, opts)
+		return nil, 0, fmt.Errorf("%w: missing closing ]", service.ErrInvalidSearchPattern)
+	}
+	return f.fakeService.SearchModels(ctx, opts)
+}
+
+func TestSearchRejectsInvalidPatternUnlessLiteral(t *testing.T) {
+	svc := &patternService{fakeService{total: 1}}
+	_, mux := newTestHandlers(t, svc, config.ServerConfig{})
+
+	rec := get(mux, "/search?q=%5Bunclosed")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid pattern: status = %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Plain text") {
+		t.Errorf("invalid pattern: body %q does not suggest a literal search", rec.Body)
+	}
+
+	rec = get(mux, "/search?q=%5Bunclosed&literal=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("literal search: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if last := svc.searches[len(svc.searches)-1]; !last.Literal || last.Query != "[unclosed" {
+		t.Errorf("literal search: searched %+v, want the raw query searched literally", last)
+	}
+}
+
+func TestIndexUsesConfiguredDefaultSort(t *testing.T) {
+	svc := &fakeService{total: 3}
+	_, mux := newTestHandlers(t, svc, config.ServerConfig{IndexSort: "lastModified", IndexSortOrder: -1})
+
+	if rec := get(mux, "/"); rec.Code != http.StatusOK {
+		t.Fatalf("index: status = %d, want 200", rec.Code)
+	}
+	if rec := get(mux, "/search?q=bert&sort=likes&order=1"); rec.Code != http.StatusOK {
+		t.Fatalf("search: status = %d, want 200", rec.Code)
+	}
+	if rec := get(mux, "/search?q=bert"); rec.Code != http.StatusOK {
+		t.Fatalf("search: status = %d, want 200", rec.Code)
+	}
+
+	if len(svc.searches) != 3 {
+		t.Fatalf("answered %d searches, want 3", len(svc.searches))
+	}
+	for i, want := range []struct {
+		sortBy string
+		order  int
+	}{
+		{sortBy: "lastModified", order: -1},
+		{sortBy: "likes", order: 1},
+		{sortBy: "lastModified", order: -1},
+	} {
+		if got := svc.searches[i]; got.SortBy != want.sortBy || got.SortOrder != want.order {
+			t.Errorf("search %d sorted by %s %d, want %s %d", i, got.SortBy, got.SortOrder, want.sortBy, want.order)
+		}
+	}
+}
+
+func TestBrokenTemplatesAnswerWithServerError(t *testing.T) {
+	svc := &fakeService{total: 3, models: map[string]*domain.HuggingFaceModel{"a/b": {ID: "a/b"}}}
+	h, mux := newTestHandlers(t, svc, config.ServerConfig{})
+	h.templates = template.Must(template.New("search_results.html").Parse(`partial results{{template "missing.html"}}`))
+	template.Must(h.templates.New("model.html").Parse(`partial model{{template "missing.html"}}`))
+
+	for _, target := range []string{"/search?q=a", "/models/a/b"} {
+		rec := get(mux, target)
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("%s: status = %d, want 500", target, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "partial") {
+			t.Errorf("%s: the partly rendered page was sent: %q", target, rec.Body)
+		}
+	}
+}
+
+func TestSearchClampsOutOfRangePages(t *testing.T) {
+	svc := &fakeService{total: 45}
+	_, mux := newTestHandlers(t, svc, config.ServerConfig{})
+
+	rec := get(mux, "/search?q=bert&page=999999")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("X-Page"); got != "3" {
+		t.Errorf("X-Page = %q, want the last page, 3", got)
+	}
+	if last := svc.searches[len(svc.searches)-1]; last.Page != 3 {
+		t.Errorf("the results shown are for page %d, want 3", last.Page)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Page 3 of 3") || !strings.Contains(body, "model-44") {
+		t.Errorf("the last page is not shown: %s", body)
+	}
+	if strings.Contains(body, ">Next<") || !strings.Contains(body, "page=2") {
+		t.Errorf("want a link back to page 2 and none forward: %s", body)
+	}
+
+	rec = get(mux, "/search?q=bert&page=-4")
+	if got := rec.Header().Get("X-Page"); got != "1" {
+		t.Errorf("negative page: X-Page = %q, want 1", got)
+	}
+	if body := rec.Body.String(); strings.Contains(body, ">Previous<") || !strings.Contains(body, "page=2") {
+		t.Errorf("negative page: want a link forward to page 2 and none back: %s", body)
+	}
+}
+
+func TestEmptySearchHasOnePage(t *testing.T) {
+	_, mux := newTestHandlers(t, &fakeService{}, config.ServerConfig{})
+
+	rec := get(mux, "/search?q=nothing&page=3")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if page, pages := rec.Header().Get("X-Page"), rec.Header().Get("X-Total-Pages"); page != "1" || pages != "1" {
+		t.Errorf("X-Page = %q, X-Total-Pages = %q, want page 1 of 1", page, pages)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Page 1 of 1") || strings.Contains(body, ">Next<") {
+		t.Errorf("want page 1 of 1 without a next link: %s", body)
+	}
+}
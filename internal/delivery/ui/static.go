@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// staticFiles serves the files under dir with caching headers. Each file gets
+// an ETag derived from its size and modification time, so a redeploy changes
+// it and browsers revalidating after maxAge get a 304 while it is unchanged.
+// A maxAge of zero makes browsers revalidate on every use.
+func staticFiles(dir string, maxAge int) http.Handler {
+	fileServer := http.FileServer(http.Dir(dir))
+	cacheControl := "no-cache"
+	if maxAge > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", maxAge)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			// The file server answers If-None-Match from this header.
+			w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticFilesSetCachingHeaders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.css"), []byte("body {}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		maxAge int
+		want   string
+	}{
+		{maxAge: 3600, want: "public, max-age=3600"},
+		{maxAge: 0, want: "no-cache"},
+	} {
+		handler := staticFiles(dir, tc.maxAge)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app.css", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("max age %d: status = %d, want 200", tc.maxAge, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tc.want {
+			t.Errorf("max age %d: Cache-Control = %q, want %q", tc.maxAge, got, tc.want)
+		}
+		etag := rec.Header().Get("ETag")
+		if etag == "" {
+			t.Fatalf("max age %d: no ETag", tc.maxAge)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/app.css", nil)
+		req.Header.Set("If-None-Match", etag)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("max age %d: revalidating with the ETag answered %d, want 304", tc.maxAge, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	staticFiles(dir, 3600).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing.css", nil))
+	if got := rec.Header().Get("Cache-Control"); rec.Code != http.StatusNotFound || got != "" {
+		t.Errorf("missing file: status %d with Cache-Control %q, want an uncached 404", rec.Code, got)
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// HFModelJSON mirrors the JSON shape of a model in the Hugging Face API, so
+// responses can be used as a drop-in replacement for the Hub's own.
+// Fields derived by this service (license, languages, ...) have no Hub
+// equivalent and are left out; the library is reported as library_name.
+type HFModelJSON struct {
+	ID           string    `json:"id"`
+	ModelID      string    `json:"modelId"`
+	Author       string    `json:"author,omitempty"`
+	SHA          string    `json:"sha,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Private      bool      `json:"private"`
+	// Gated is false, or the gating mode "auto" or "manual", as on the Hub.
+	Gated       any       `json:"gated"`
+	Likes       int       `json:"likes"`
+	Downloads   int       `json:"downloads"`
+	Tags        []string  `json:"tags"`
+	PipelineTag string    `json:"pipeline_tag,omitempty"`
+	LibraryName string    `json:"library_name,omitempty"`
+	Siblings    []Sibling `json:"siblings,omitempty"`
+}
+
+// HFView converts the model to the Hugging Face API's JSON shape.
+func (m HuggingFaceModel) HFView() HFModelJSON {
+	var gated any
+	switch m.Gated {
+	case GatedStatusAuto, GatedStatusManual:
+		gated = string(m.Gated)
+	case GatedStatusTrue:
+		gated = true
+	default:
+		gated = false
+	}
+
+	tags := m.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return HFModelJSON{
+		ID:           m.ID,
+		ModelID:      m.ID,
+		Author:       m.Author,
+		SHA:          m.SHA,
+		LastModified: m.LastModified,
+		CreatedAt:    m.CreatedAt,
+		Private:      bool(m.Private),
+		Gated:        gated,
+		Likes:        int(m.Likes),
+		Downloads:    int(m.Downloads),
+		Tags:         tags,
+		PipelineTag:  m.PipelineTag,
+		LibraryName:  m.Library,
+		Siblings:     m.Siblings,
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxModelIDLength bounds the length of an accepted model ID. Hub repo names
+// are limited to 96 characters, so anything far beyond that is pathological.
+const MaxModelIDLength = 256
+
+// ErrInvalidModelID is returned by ParseModelID for malformed model IDs.
+var ErrInvalidModelID = errors.New("invalid model ID")
+
+// ParseModelID reconstructs a model ID from the escaped path segment that
+// follows a route prefix, e.g. "google-bert/bert-base-uncased". Escaped
+// characters (including an encoded slash) are decoded first, so both spellings
+// of an ID resolve to the same value. Both the canonical "author/name" form and
+// the legacy org-less "name" form are accepted, and a trailing slash is ignored.
+//
+// Hub IDs preserve case, e.g. "Qwen/Qwen2-7B", and are stored that way. The
+// Hub itself resolves them case-insensitively, but the store does not, so
+// lowercase should only be set when the stored IDs are lowercase too.
+func ParseModelID(escapedPath string, lowercase bool) (string, error) {
+	escapedPath = strings.TrimSuffix(escapedPath, "/")
+	if len(escapedPath) > 3*MaxModelIDLength {
+		return "", fmt.Errorf("%w: too long", ErrInvalidModelID)
+	}
+
+	id, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidModelID, err)
+	}
+	if len(id) > MaxModelIDLength {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidModelID, MaxModelIDLength)
+	}
+
+	parts := strings.Split(id, "/")
+	if len(parts) > 2 {
+		return "", fmt.Errorf("%w: expected {author}/{modelName}", ErrInvalidModelID)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("%w: expected {author}/{modelName}", ErrInvalidModelID)
+		}
+	}
+	if lowercase {
+		id = strings.ToLower(id)
+	}
+	return id, nil
+}
@@ -4,7 +4,9 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +22,15 @@ const (
 	GatedStatusManual GatedStatus = "manual"
 )
 
+// GatedStatuses lists the statuses of gated models, whatever the kind of approval.
+var GatedStatuses = []GatedStatus{GatedStatusTrue, GatedStatusAuto, GatedStatusManual}
+
+// IsGated reports whether access to the model requires accepting its
+// conditions, with automatic or manual approval.
+func (gs GatedStatus) IsGated() bool {
+	return slices.Contains(GatedStatuses, gs)
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface for GatedStatus.
 // It can handle JSON booleans (true/false) or JSON strings ("true", "false", "auto","auto").
 func (gs *GatedStatus) UnmarshalJSON(data []byte) error {
@@ -76,6 +87,34 @@ func (fb *FlexibleBool) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// --- Custom Type for the "likes" and "downloads" fields ---
+
+// FlexibleInt is a custom integer type that can be unmarshaled from a JSON
+// number or a JSON string holding one ("1234"), which the Hub sends for some
+// counters.
+type FlexibleInt int
+
+// UnmarshalJSON implements the json.Unmarshaler interface for FlexibleInt.
+func (fi *FlexibleInt) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*fi = FlexibleInt(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsedInt, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*fi = FlexibleInt(parsedInt)
+	return nil
+}
+
 // ServiceStatus represents the operational state of the daemon.
 type ServiceStatus string
 
@@ -95,16 +134,115 @@ const (
 type HuggingFaceModel struct {
 	ID           string       `json:"id" bson:"_id"`
 	Author       string       `json:"author" bson:"author"`
-	SHA          string       `json:"sha" bson:"sha"`
+	SHA          string       `json:"sha" bson:"sha,omitempty"`
 	LastModified time.Time    `json:"lastModified" bson:"lastModified"`
 	CreatedAt    time.Time    `json:"createdAt" bson:"createdAt"`
 	Private      FlexibleBool `json:"private" bson:"private"`
 	Gated        GatedStatus  `json:"gated" bson:"gated"`
-	Likes        int          `json:"likes" bson:"likes"`
-	Downloads    int          `json:"downloads" bson:"downloads"`
+	Likes        FlexibleInt  `json:"likes" bson:"likes"`
+	Downloads    FlexibleInt  `json:"downloads" bson:"downloads"`
 	Tags         []string     `json:"tags" bson:"tags"`
 	PipelineTag  string       `json:"pipeline_tag" bson:"pipeline_tag"`
-	Siblings     []Sibling    `json:"siblings" bson:"siblings"`
+	Siblings     []Sibling    `json:"siblings" bson:"siblings,omitempty"`
+	// License, Library and Languages are derived from the tags during ingest.
+	License   string   `json:"license,omitempty" bson:"license,omitempty"`
+	Library   string   `json:"library,omitempty" bson:"library,omitempty"`
+	Languages []string `json:"languages,omitempty" bson:"languages,omitempty"`
+	// TagsTruncated is set when only the first INGEST.MAX_TAGS tags were stored.
+	TagsTruncated bool `json:"tagsTruncated,omitempty" bson:"tagsTruncated,omitempty"`
+	// FirstSeen is when the model was first stored. Later writes keep it.
+	// Models stored before it was introduced don't have it.
+	FirstSeen *time.Time `json:"firstSeen,omitempty" bson:"firstSeen,omitempty"`
+	// DeletedAt is set once the model has disappeared from the Hub. Deleted
+	// models are kept but no longer show up in searches.
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	// Badges are popularity tiers computed when the model is read, such as
+	// BadgePopular. They are never stored.
+	Badges []string `json:"badges,omitempty" bson:"-"`
+}
+
+// Badges a model can be given in responses.
+const (
+	// BadgeTrending marks the most-liked models.
+	BadgeTrending = "Trending"
+	// BadgePopular marks the most-downloaded models.
+	BadgePopular = "Popular"
+	// BadgeNew marks recently discovered models.
+	BadgeNew = "New"
+)
+
+// OrgAndName splits the model ID into the owning organization (or user) and
+// the repository name. Legacy IDs without an owner return an empty org.
+func (m HuggingFaceModel) OrgAndName() (org, name string) {
+	org, name, found := strings.Cut(m.ID, "/")
+	if !found {
+		return "", m.ID
+	}
+	return org, name
+}
+
+// TimestampOf returns the timestamp field named by its JSON name: CreatedAt
+// for "createdAt" and LastModified for anything else.
+func (m HuggingFaceModel) TimestampOf(field string) time.Time {
+	if field == "createdAt" {
+		return m.CreatedAt
+	}
+	return m.LastModified
+}
+
+// CountOf returns the counter field named by its JSON name: Likes for
+// "likes" and Downloads for anything else.
+func (m HuggingFaceModel) CountOf(field string) int64 {
+	if field == "likes" {
+		return int64(m.Likes)
+	}
+	return int64(m.Downloads)
+}
+
+// Org returns the owning organization (or user) of the model, if any.
+func (m HuggingFaceModel) Org() string {
+	org, _ := m.OrgAndName()
+	return org
+}
+
+// DisplayName returns the repository name without its owner prefix.
+func (m HuggingFaceModel) DisplayName() string {
+	_, name := m.OrgAndName()
+	return name
+}
+
+// ModelSnapshot records a model's popularity counters at the time it was scraped.
+type ModelSnapshot struct {
+	ModelID   string    `json:"modelId" bson:"modelId"`
+	ScrapedAt time.Time `json:"scrapedAt" bson:"scrapedAt"`
+	Likes     int       `json:"likes" bson:"likes"`
+	Downloads int       `json:"downloads" bson:"downloads"`
+}
+
+// TrendPoint is the total of a counter over the models snapshotted in one
+// time bucket.
+type TrendPoint struct {
+	Bucket time.Time `json:"bucket" bson:"_id"`
+	Total  int64     `json:"total" bson:"total"`
+	// Models is the number of models contributing to Total.
+	Models int64 `json:"models" bson:"models"`
+}
+
+// TimeCount is the number of items falling into one time bucket.
+type TimeCount struct {
+	Bucket time.Time `json:"bucket" bson:"_id"`
+	Count  int64     `json:"count" bson:"count"`
+}
+
+// ModelDiff is the change of a model's counters between two snapshots.
+type ModelDiff struct {
+	ID             string        `json:"id"`
+	From           ModelSnapshot `json:"from"`
+	To             ModelSnapshot `json:"to"`
+	LikesDelta     int           `json:"likesDelta"`
+	DownloadsDelta int           `json:"downloadsDelta"`
+	// Notes flags snapshots used in place of one near a requested time.
+	Notes []string `json:"notes,omitempty"`
 }
 
 // StatusDocument represents the state of the service, stored in the database.
@@ -113,6 +251,139 @@ type StatusDocument struct {
 	ID        string        `bson:"_id"` // A constant key, e.g., "service_status"
 	Status    ServiceStatus `bson:"status"`
 	UpdatedAt time.Time     `bson:"updatedAt"`
-	// BackfillCursor stores the 'NextURL' to resume scraping from.
+	// BackfillCursor is the single cursor written before per-shard cursors
+	// existed. It is still read as the default shard's cursor.
 	BackfillCursor string `bson:"backfillCursor,omitempty"`
+	// BackfillCursors maps each backfill shard to the 'NextURL' to resume it from.
+	BackfillCursors map[string]string `bson:"backfillCursors,omitempty"`
+}
+
+// DefaultCursorShard names the backfill shard of an unsharded deployment.
+const DefaultCursorShard = "default"
+
+// Cursor returns the saved backfill cursor of a shard, or an empty string.
+// The default shard falls back to the legacy single cursor.
+func (d *StatusDocument) Cursor(shard string) string {
+	if cursor, ok := d.BackfillCursors[shard]; ok {
+		return cursor
+	}
+	if shard == DefaultCursorShard {
+		return d.BackfillCursor
+	}
+	return ""
+}
+
+// SetCursor saves the backfill cursor of a shard. Setting the default shard
+// migrates the legacy single cursor into the map.
+func (d *StatusDocument) SetCursor(shard, cursorURL string) {
+	if d.BackfillCursors == nil {
+		d.BackfillCursors = make(map[string]string)
+	}
+	d.BackfillCursors[shard] = cursorURL
+	if shard == DefaultCursorShard {
+		d.BackfillCursor = ""
+	}
+}
+
+// ClearCursor removes the cursor of a shard that has finished its backfill.
+func (d *StatusDocument) ClearCursor(shard string) {
+	delete(d.BackfillCursors, shard)
+	if len(d.BackfillCursors) == 0 {
+		d.BackfillCursors = nil
+	}
+	if shard == DefaultCursorShard {
+		d.BackfillCursor = ""
+	}
+}
+
+// StatusReport is the read-only view of the daemon's runtime state served by the API.
+type StatusReport struct {
+	Mode           ServiceStatus `json:"mode"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+	BackfillCursor string        `json:"backfillCursor,omitempty"`
+	// BackfillCursors holds the cursors of every shard still backfilling.
+	BackfillCursors map[string]string `json:"backfillCursors,omitempty"`
+	ScraperBreaker  string            `json:"scraperBreaker"`
+	// SkippedUnchanged counts the models the watcher did not rewrite because
+	// their content was unchanged, since the daemon started.
+	SkippedUnchanged int64 `json:"skippedUnchanged"`
+	// IndexBuilding is true while an index build holds back backfill writes.
+	IndexBuilding bool `json:"indexBuilding"`
+	// DroppedEvents counts the events the broker dropped for slow subscribers
+	// since the daemon started.
+	DroppedEvents int64 `json:"droppedEvents"`
+	// WatchInterval is the time between watch cycle starts.
+	WatchInterval WatchInterval `json:"watchInterval"`
+}
+
+// WatchInterval compares the configured time between watch cycle starts with
+// the times actually achieved, which grow when fetches run long. The achieved
+// values are zero until two cycles have run.
+type WatchInterval struct {
+	ConfiguredSeconds float64 `json:"configuredSeconds"`
+	LastSeconds       float64 `json:"lastSeconds"`
+	AverageSeconds    float64 `json:"averageSeconds"`
+}
+
+// TopicSubscribers is the number of subscribers of an event broker topic.
+type TopicSubscribers struct {
+	Topic       string `json:"topic"`
+	Subscribers int    `json:"subscribers"`
+}
+
+// TagCount is the number of models sharing a tag value.
+type TagCount struct {
+	Tag   string `json:"tag" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// AuthorCount is the number of models published by an author.
+type AuthorCount struct {
+	Author string `json:"author" bson:"_id"`
+	Count  int64  `json:"count" bson:"count"`
+}
+
+// DuplicateGroup is a commit SHA shared by several stored models, which
+// usually means the same content was stored under different IDs.
+type DuplicateGroup struct {
+	SHA   string   `json:"sha" bson:"_id"`
+	IDs   []string `json:"ids" bson:"ids"`
+	Count int64    `json:"count" bson:"count"`
+}
+
+// StatsSummary holds aggregate counts over the whole model collection.
+type StatsSummary struct {
+	TotalModels        int64      `json:"totalModels" bson:"totalModels"`
+	GatedModels        int64      `json:"gatedModels" bson:"gatedModels"`
+	PrivateModels      int64      `json:"privateModels" bson:"privateModels"`
+	TotalDownloads     int64      `json:"totalDownloads" bson:"totalDownloads"`
+	TopPipelineTags    []TagCount `json:"topPipelineTags" bson:"topPipelineTags"`
+	NewestLastModified time.Time  `json:"newestLastModified" bson:"newestLastModified"`
+}
+
+// StatsSnapshot is a StatsSummary computed at a point in time and stored,
+// so dashboards can chart it and the summary can be served without
+// recomputing it.
+type StatsSnapshot struct {
+	TakenAt time.Time    `json:"takenAt" bson:"takenAt"`
+	Summary StatsSummary `json:"summary" bson:"summary"`
+}
+
+// CollectionStats describes the size of the model collection as reported by the database.
+type CollectionStats struct {
+	Count            int64            `json:"count"`
+	SizeBytes        int64            `json:"sizeBytes"`
+	AvgObjSizeBytes  int64            `json:"avgObjSizeBytes"`
+	StorageSizeBytes int64            `json:"storageSizeBytes"`
+	IndexSizes       map[string]int64 `json:"indexSizes"`
+	Sharded          bool             `json:"sharded"`
+}
+
+// ShardRecommendation is the outcome of analyzing the collection for sharding.
+type ShardRecommendation struct {
+	Stats          CollectionStats `json:"stats"`
+	ShouldShard    bool            `json:"shouldShard"`
+	ShardKey       string          `json:"shardKey,omitempty"`
+	MissingIndexes []string        `json:"missingIndexes"`
+	Notes          []string        `json:"notes"`
 }
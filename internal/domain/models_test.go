@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOrgAndNameAndDisplayName(t *testing.T) {
+	for _, tc := range []struct {
+		id, org, name string
+	}{
+		{id: "google-bert/bert-base-uncased", org: "google-bert", name: "bert-base-uncased"},
+		{id: "gpt2", org: "", name: "gpt2"},
+		{id: "", org: "", name: ""},
+		{id: "org/", org: "org", name: ""},
+	} {
+		model := HuggingFaceModel{ID: tc.id}
+		org, name := model.OrgAndName()
+		if org != tc.org || name != tc.name {
+			t.Errorf("OrgAndName(%q) = %q, %q, want %q, %q", tc.id, org, name, tc.org, tc.name)
+		}
+		if got := model.DisplayName(); got != tc.name {
+			t.Errorf("DisplayName(%q) = %q, want %q", tc.id, got, tc.name)
+		}
+	}
+}
+
+func TestFlexibleIntAcceptsNumbersAndNumericStrings(t *testing.T) {
+	for _, tc := range []struct {
+		json    string
+		want    FlexibleInt
+		wantErr bool
+	}{
+		{json: `42`, want: 42},
+		{json: `"1534"`, want: 1534},
+		{json: `null`, want: 0},
+		{json: `"many"`, wantErr: true},
+		{json: `4.5`, wantErr: true},
+	} {
+		var got FlexibleInt
+		err := json.Unmarshal([]byte(tc.json), &got)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d (error %t)", tc.json, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+// jsonKinds maps the keys of a JSON object to the kind of their values.
+func jsonKinds(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]string, len(object))
+	for key, value := range object {
+		kinds[key] = fmt.Sprintf("%T", value)
+	}
+	return kinds
+}
+
+func TestHFViewMatchesCapturedHubResponse(t *testing.T) {
+	captured, err := os.ReadFile("testdata/hf_model.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var model HuggingFaceModel
+	if err := json.Unmarshal(captured, &model); err != nil {
+		t.Fatal(err)
+	}
+	model.Library = "transformers"
+
+	ours, err := json.Marshal(model.HFView())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hubKinds, ourKinds := jsonKinds(t, captured), jsonKinds(t, ours)
+	// The Hub's internal document ID and moderation flag are not mirrored.
+	delete(hubKinds, "_id")
+	delete(hubKinds, "disabled")
+	if !maps.Equal(ourKinds, hubKinds) {
+		t.Errorf("HFView keys and kinds = %v, want the Hub's %v", ourKinds, hubKinds)
+	}
+}
+
+func TestStatusDocumentKeepsACursorPerShard(t *testing.T) {
+	var doc StatusDocument
+	doc.SetCursor("a-m", "https://hub/a-m?cursor=1")
+	doc.SetCursor("n-z", "https://hub/n-z?cursor=1")
+	doc.SetCursor("a-m", "https://hub/a-m?cursor=2")
+
+	for shard, want := range map[string]string{
+		"a-m":              "https://hub/a-m?cursor=2",
+		"n-z":              "https://hub/n-z?cursor=1",
+		DefaultCursorShard: "",
+		"unknown":          "",
+	} {
+		if got := doc.Cursor(shard); got != want {
+			t.Errorf("Cursor(%q) = %q, want %q", shard, got, want)
+		}
+	}
+
+	doc.ClearCursor("a-m")
+	if got := doc.Cursor("a-m"); got != "" {
+		t.Errorf("Cursor(a-m) after ClearCursor = %q, want empty", got)
+	}
+	doc.ClearCursor("n-z")
+	if doc.BackfillCursors != nil {
+		t.Errorf("BackfillCursors = %v after clearing every shard, want nil", doc.BackfillCursors)
+	}
+}
+
+func TestStatusDocumentMigratesLegacyCursor(t *testing.T) {
+	legacy, err := bson.Marshal(bson.M{"_id": "service_status", "status": StatusNeedsBackfill, "backfillCursor": "https://hub/legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc StatusDocument
+	if err := bson.Unmarshal(legacy, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Cursor(DefaultCursorShard); got != "https://hub/legacy" {
+		t.Fatalf("default cursor = %q, want the legacy cursor", got)
+	}
+	if got := doc.Cursor("a-m"); got != "" {
+		t.Errorf("Cursor(a-m) = %q, want the legacy cursor to stay on the default shard", got)
+	}
+
+	doc.SetCursor(DefaultCursorShard, "https://hub/next")
+	if doc.BackfillCursor != "" {
+		t.Errorf("BackfillCursor = %q after SetCursor, want it migrated away", doc.BackfillCursor)
+	}
+	if got := doc.Cursor(DefaultCursorShard); got != "https://hub/next" {
+		t.Errorf("default cursor = %q, want https://hub/next", got)
+	}
+
+	doc = StatusDocument{BackfillCursor: "https://hub/legacy"}
+	doc.ClearCursor(DefaultCursorShard)
+	if got := doc.Cursor(DefaultCursorShard); got != "" {
+		t.Errorf("default cursor = %q after ClearCursor, want the legacy cursor cleared", got)
+	}
+}
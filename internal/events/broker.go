@@ -1,7 +1,13 @@
-// Path: internal/events/broker.go
 package events
 
-import "sync"
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hf-scraper/internal/metrics"
+)
 
 // Event represents a message passed through the broker.
 type Event struct {
@@ -13,15 +19,38 @@ type Event struct {
 type Broker struct {
 	mu          sync.RWMutex
 	subscribers map[string][]chan Event
+	metrics     metrics.Metrics
+	// dropped counts the events not delivered because a subscriber's buffer was full.
+	dropped atomic.Int64
+
+	// Batching state, only used by brokers created with NewBatchingBroker.
+	batchInterval time.Duration
+	batchMu       sync.Mutex
+	pending       map[string][]any
+	done          chan struct{}
+	closeOnce     sync.Once
 }
 
-// NewBroker creates a new event broker.
-func NewBroker() *Broker {
+// NewBroker creates a new event broker. Dropped events are counted in m.
+func NewBroker(m metrics.Metrics) *Broker {
 	return &Broker{
 		subscribers: make(map[string][]chan Event),
+		metrics:     m,
 	}
 }
 
+// NewBatchingBroker creates a broker that coalesces all events published to a
+// topic within the given interval and delivers them as a single event whose
+// Data is a []any holding the individual payloads in publish order.
+func NewBatchingBroker(interval time.Duration, m metrics.Metrics) *Broker {
+	b := NewBroker(m)
+	b.batchInterval = interval
+	b.pending = make(map[string][]any)
+	b.done = make(chan struct{})
+	go b.runBatcher()
+	return b
+}
+
 // Subscribe creates a new subscription to a topic.
 // It returns a read-only channel where events for that topic will be sent.
 func (b *Broker) Subscribe(topic string) <-chan Event {
@@ -33,8 +62,73 @@ func (b *Broker) Subscribe(topic string) <-chan Event {
 	return ch
 }
 
+// Unsubscribe removes a subscription created by Subscribe and closes its channel.
+func (b *Broker) Unsubscribe(topic string, sub <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscribers := b.subscribers[topic]
+	for i, ch := range subscribers {
+		if ch == sub {
+			b.subscribers[topic] = append(subscribers[:i], subscribers[i+1:]...)
+			close(ch)
+			break
+		}
+	}
+	if len(b.subscribers[topic]) == 0 {
+		delete(b.subscribers, topic)
+	}
+}
+
+// SubscriberCount returns the number of current subscribers of a topic.
+func (b *Broker) SubscriberCount(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[topic])
+}
+
+// Topics returns the topics that currently have subscribers, sorted by name.
+func (b *Broker) Topics() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	topics := make([]string, 0, len(b.subscribers))
+	for topic := range b.subscribers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Dropped returns the number of events dropped so far because a subscriber
+// was not keeping up. A growing value points at a stuck consumer.
+func (b *Broker) Dropped() int64 {
+	return b.dropped.Load()
+}
+
 // Publish sends an event to all subscribers of a topic.
+// On a batching broker the event is queued until the next flush.
 func (b *Broker) Publish(topic string, data interface{}) {
+	if b.batchInterval > 0 {
+		b.batchMu.Lock()
+		b.pending[topic] = append(b.pending[topic], data)
+		b.batchMu.Unlock()
+		return
+	}
+	b.deliver(topic, data)
+}
+
+// Close stops the batching goroutine after flushing any pending events.
+// It is a no-op for unbatched brokers.
+func (b *Broker) Close() {
+	if b.done == nil {
+		return
+	}
+	b.closeOnce.Do(func() { close(b.done) })
+}
+
+// deliver fans an event out to the current subscribers of a topic.
+func (b *Broker) deliver(topic string, data any) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
@@ -46,7 +140,37 @@ func (b *Broker) Publish(topic string, data interface{}) {
 			case ch <- event:
 			default:
 				// Subscriber is not ready, drop the event to avoid blocking.
+				b.dropped.Add(1)
+				b.metrics.IncCounter(metrics.BrokerEventsDropped)
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// runBatcher flushes pending events on every tick until the broker is closed.
+func (b *Broker) runBatcher() {
+	ticker := time.NewTicker(b.batchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.flush()
+		case <-b.done:
+			b.flush()
+			return
+		}
+	}
+}
+
+// flush delivers every non-empty topic batch as a single event.
+func (b *Broker) flush() {
+	b.batchMu.Lock()
+	pending := b.pending
+	b.pending = make(map[string][]any)
+	b.batchMu.Unlock()
+
+	for topic, batch := range pending {
+		b.deliver(topic, batch)
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+)
+
+// receive returns the next event on sub, failing the test after a second.
+func receive(t *testing.T, sub <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-sub:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestBatchingBrokerDeliversOneBatch(t *testing.T) {
+	broker := NewBatchingBroker(50*time.Millisecond, metrics.Noop{})
+	defer broker.Close()
+	sub := broker.Subscribe("model:new")
+
+	broker.Publish("model:new", "a")
+	broker.Publish("model:new", "b")
+	broker.Publish("model:new", "c")
+
+	event := receive(t, sub)
+	if want := []any{"a", "b", "c"}; !reflect.DeepEqual(event.Data, want) {
+		t.Fatalf("batch = %#v, want %#v", event.Data, want)
+	}
+	select {
+	case extra := <-sub:
+		t.Fatalf("unexpected second event %#v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFullSubscriberBufferCountsDroppedEvents(t *testing.T) {
+	recorder := metricstest.NewRecorder()
+	broker := NewBroker(recorder)
+	defer broker.Close()
+	stuck := broker.Subscribe("model:new")
+	reading := broker.Subscribe("model:new")
+
+	// The first event fills the stuck subscriber's buffer.
+	broker.Publish("model:new", "a")
+	receive(t, reading)
+	if dropped := broker.Dropped(); dropped != 0 {
+		t.Fatalf("dropped = %d before any buffer was full, want 0", dropped)
+	}
+
+	broker.Publish("model:new", "b")
+	if dropped := broker.Dropped(); dropped != 1 {
+		t.Errorf("dropped = %d, want 1 for the stuck subscriber", dropped)
+	}
+	if got := recorder.Counter(metrics.BrokerEventsDropped); got != 1 {
+		t.Errorf("%s = %v, want 1", metrics.BrokerEventsDropped, got)
+	}
+	if event := receive(t, reading); event.Data != "b" {
+		t.Errorf("the reading subscriber got %v, want b", event.Data)
+	}
+	if event := receive(t, stuck); event.Data != "a" {
+		t.Errorf("the stuck subscriber got %v, want the buffered a", event.Data)
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+// Metrics is the small set of instrumentation primitives used across the
+// daemon. It keeps the core decoupled from any particular metrics backend.
+type Metrics interface {
+	// IncCounter increments the named counter by one.
+	IncCounter(name string)
+	// AddCounter increments the named counter by delta.
+	AddCounter(name string, delta float64)
+	// ObserveHistogram records a single observation, e.g. a duration in seconds.
+	ObserveHistogram(name string, value float64)
+	// SetGauge sets the named gauge to value.
+	SetGauge(name string, value float64)
+}
+
+// Noop is a Metrics implementation that discards everything. It is the
+// default when no metrics backend is configured.
+type Noop struct{}
+
+func (Noop) IncCounter(string)                {}
+func (Noop) AddCounter(string, float64)       {}
+func (Noop) ObserveHistogram(string, float64) {}
+func (Noop) SetGauge(string, float64)         {}
+
+// Metric names shared by the instrumented components.
+const (
+	ScraperRequests        = "scraper_requests_total"
+	ScraperRequestErrors   = "scraper_request_errors_total"
+	ScraperRequestDuration = "scraper_request_duration_seconds"
+	ScraperBreakerOpen     = "scraper_breaker_open"
+	ScraperBytes           = "scraper_downloaded_bytes_total"
+
+	BackfillPages      = "backfill_pages_total"
+	WatchCycles        = "watch_cycles_total"
+	WatchCycleDuration = "watch_cycle_duration_seconds"
+	// WatchIntervalLast and WatchIntervalAverage are the achieved times
+	// between watch cycle starts, to compare with the configured interval.
+	WatchIntervalLast    = "watch_interval_last_seconds"
+	WatchIntervalAverage = "watch_interval_average_seconds"
+	ModelsUpserted       = "models_upserted_total"
+	ModelsSkipped        = "models_skipped_unchanged_total"
+	ModelsSkippedNoID    = "models_skipped_no_id_total"
+	ModelUpsertErrors    = "model_upsert_errors_total"
+	ServiceWatching      = "service_watching"
+
+	ReconcileChecked = "reconcile_checked_total"
+	ReconcileDeleted = "reconcile_deleted_total"
+
+	BrokerEventsDropped = "broker_events_dropped_total"
+)
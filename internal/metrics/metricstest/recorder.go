@@ -0,0 +1,74 @@
+// Package metricstest provides a Metrics implementation that records every
+// call, for tests asserting what a component reported.
+package metricstest
+
+import (
+	"sync"
+
+	"hf-scraper/internal/metrics"
+)
+
+var _ metrics.Metrics = (*Recorder)(nil)
+
+// Recorder is a metrics.Metrics that keeps counter totals, the last value of
+// each gauge and every histogram observation. It is safe for concurrent use.
+type Recorder struct {
+	mu         sync.Mutex
+	counters   map[string]float64
+	gauges     map[string]float64
+	histograms map[string][]float64
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		counters:   make(map[string]float64),
+		gauges:     make(map[string]float64),
+		histograms: make(map[string][]float64),
+	}
+}
+
+func (r *Recorder) IncCounter(name string) {
+	r.AddCounter(name, 1)
+}
+
+func (r *Recorder) AddCounter(name string, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += delta
+}
+
+func (r *Recorder) ObserveHistogram(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.histograms[name] = append(r.histograms[name], value)
+}
+
+func (r *Recorder) SetGauge(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = value
+}
+
+// Counter returns the total added to the named counter.
+func (r *Recorder) Counter(name string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counters[name]
+}
+
+// Gauge returns the last value the named gauge was set to, and whether it
+// was set at all.
+func (r *Recorder) Gauge(name string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.gauges[name]
+	return value, ok
+}
+
+// Observations returns a copy of the values observed by the named histogram.
+func (r *Recorder) Observations(name string) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]float64(nil), r.histograms[name]...)
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric exported to Prometheus.
+const namespace = "hf_scraper"
+
+// Prometheus is a Metrics implementation backed by a Prometheus registry.
+// Metrics are registered lazily the first time they are used.
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	mu         sync.Mutex
+	counters   map[string]prometheus.Counter
+	histograms map[string]prometheus.Histogram
+	gauges     map[string]prometheus.Gauge
+}
+
+// NewPrometheus creates a Prometheus-backed Metrics with its own registry.
+func NewPrometheus() *Prometheus {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return &Prometheus{
+		registry:   registry,
+		counters:   make(map[string]prometheus.Counter),
+		histograms: make(map[string]prometheus.Histogram),
+		gauges:     make(map[string]prometheus.Gauge),
+	}
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *Prometheus) IncCounter(name string) {
+	p.counter(name).Inc()
+}
+
+func (p *Prometheus) AddCounter(name string, delta float64) {
+	p.counter(name).Add(delta)
+}
+
+func (p *Prometheus) ObserveHistogram(name string, value float64) {
+	p.mu.Lock()
+	histogram, ok := p.histograms[name]
+	if !ok {
+		histogram = prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: namespace, Name: name, Help: name})
+		p.registry.MustRegister(histogram)
+		p.histograms[name] = histogram
+	}
+	p.mu.Unlock()
+	histogram.Observe(value)
+}
+
+func (p *Prometheus) SetGauge(name string, value float64) {
+	p.mu.Lock()
+	gauge, ok := p.gauges[name]
+	if !ok {
+		gauge = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: name})
+		p.registry.MustRegister(gauge)
+		p.gauges[name] = gauge
+	}
+	p.mu.Unlock()
+	gauge.Set(value)
+}
+
+// counter returns the named counter, registering it on first use.
+func (p *Prometheus) counter(name string) prometheus.Counter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	counter, ok := p.counters[name]
+	if !ok {
+		counter = prometheus.NewCounter(prometheus.CounterOpts{Namespace: namespace, Name: name, Help: name})
+		p.registry.MustRegister(counter)
+		p.counters[name] = counter
+	}
+	return counter
+}
@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// minAdaptiveRate is the slowest rate the adaptive controller will throttle to.
+const minAdaptiveRate = 0.05
+
+// adaptiveThrottle slows the scraper down as the API's remaining rate-limit
+// quota approaches zero and restores the configured limit once the quota
+// window resets, so we avoid 429s proactively instead of reacting to them.
+type adaptiveThrottle struct {
+	mu        sync.Mutex
+	threshold int
+	scraper   *Scraper
+
+	// rate and burst are the limit configured through SetLimit, which the
+	// throttle restores. They follow mode changes made while throttled.
+	rate  float64
+	burst int
+
+	throttled     bool
+	throttledRate float64
+	restoreAt     time.Time
+	now           func() time.Time
+}
+
+// newAdaptiveThrottle creates a controller that starts throttling when the
+// remaining quota drops to threshold or below, starting from the configured
+// rate and burst. A threshold of zero or less disables adaptive throttling.
+func newAdaptiveThrottle(s *Scraper, threshold int, rate float64, burst int) *adaptiveThrottle {
+	return &adaptiveThrottle{
+		threshold: threshold,
+		scraper:   s,
+		rate:      rate,
+		burst:     burst,
+		now:       time.Now,
+	}
+}
+
+// configure sets the limit the throttle restores to. While throttled, the
+// throttled rate stays in effect, lowered further if the new limit is slower.
+func (a *adaptiveThrottle) configure(rate float64, burst int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rate, a.burst = rate, burst
+	if a.throttled {
+		a.throttledRate = min(a.throttledRate, rate)
+		a.scraper.setLimiter(a.throttledRate, 1)
+		return
+	}
+	a.scraper.setLimiter(rate, burst)
+}
+
+// observe inspects the rate-limit headers of a response and adjusts the limit.
+func (a *adaptiveThrottle) observe(header http.Header) {
+	if a.threshold <= 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset := parseRateLimitReset(header.Get("X-RateLimit-Reset"), a.now())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if remaining > a.threshold {
+		a.restoreLocked()
+		return
+	}
+
+	// Spread the remaining quota evenly over what is left of the window.
+	rps := a.rate
+	if reset > 0 {
+		rps = min(rps, float64(remaining)/reset.Seconds())
+	}
+	rps = max(rps, minAdaptiveRate)
+
+	a.throttled = true
+	a.throttledRate = rps
+	a.restoreAt = a.now().Add(reset)
+	a.scraper.setLimiter(rps, 1)
+	log.Printf("Rate limit quota low (%d remaining, resets in %s). Throttling to %.2f requests/s.", remaining, reset, rps)
+}
+
+// restoreIfDue restores the configured limit once the quota window has reset.
+func (a *adaptiveThrottle) restoreIfDue() {
+	if a.threshold <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.throttled && !a.now().Before(a.restoreAt) {
+		a.restoreLocked()
+	}
+}
+
+// restoreLocked lifts an active throttle, going back to the limit currently
+// configured rather than the one in effect when throttling began, which a
+// mode change may have replaced since. Callers must hold the lock.
+func (a *adaptiveThrottle) restoreLocked() {
+	if !a.throttled {
+		return
+	}
+	a.throttled = false
+	a.scraper.setLimiter(a.rate, a.burst)
+	log.Printf("Rate limit quota restored. Back to %.2f requests/s.", a.rate)
+}
+
+// parseRateLimitReset parses an X-RateLimit-Reset value, which APIs express
+// either as seconds until the reset or as a Unix timestamp.
+func parseRateLimitReset(value string, now time.Time) time.Duration {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	// Anything past a day is treated as an absolute Unix timestamp.
+	if seconds > 24*60*60 {
+		return max(time.Unix(seconds, 0).Sub(now), 0)
+	}
+	return time.Duration(seconds) * time.Second
+}
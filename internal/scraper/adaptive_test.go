@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+)
+
+// quotaHeader returns rate-limit headers with remaining requests left in a
+// window resetting in resetSeconds.
+func quotaHeader(remaining, resetSeconds int) http.Header {
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(resetSeconds))
+	return header
+}
+
+func TestAdaptiveThrottleRestoresConfiguredLimit(t *testing.T) {
+	s, _ := newTestScraper(t, nil, func(cfg *config.ScraperConfig) {
+		cfg.RequestsPerSecond, cfg.BurstLimit = 100, 5
+		cfg.AdaptiveThreshold = 10
+	})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.throttle.now = func() time.Time { return now }
+
+	var previous float64 = 100
+	for _, remaining := range []int{8, 4, 2} {
+		s.throttle.observe(quotaHeader(remaining, 10))
+		rps, burst := s.Limit()
+		if rps >= previous || burst != 1 {
+			t.Fatalf("with %d remaining, Limit() = %v, %d, want below %v with a burst of one", remaining, rps, burst, previous)
+		}
+		previous = rps
+	}
+
+	// A mode change while throttled must not lift the throttle early, and is
+	// what the throttle restores to.
+	s.SetLimit(50, 3)
+	if rps, _ := s.Limit(); rps > previous {
+		t.Errorf("a mode change lifted the throttle to %v requests/s", rps)
+	}
+
+	now = now.Add(10 * time.Second)
+	s.throttle.restoreIfDue()
+	if rps, burst := s.Limit(); rps != 50 || burst != 3 {
+		t.Errorf("restored Limit() = %v, %d, want the configured 50, 3", rps, burst)
+	}
+}
+
+func TestAdaptiveThrottleLiftsWhenQuotaRecovers(t *testing.T) {
+	s, _ := newTestScraper(t, nil, func(cfg *config.ScraperConfig) {
+		cfg.RequestsPerSecond, cfg.BurstLimit = 100, 5
+		cfg.AdaptiveThreshold = 10
+	})
+
+	s.throttle.observe(quotaHeader(1, 60))
+	if rps, _ := s.Limit(); rps >= 100 {
+		t.Fatalf("Limit() = %v with the quota nearly spent, want it throttled", rps)
+	}
+	s.throttle.observe(quotaHeader(500, 60))
+	if rps, burst := s.Limit(); rps != 100 || burst != 5 {
+		t.Errorf("Limit() = %v, %d after the quota recovered, want 100, 5", rps, burst)
+	}
+}
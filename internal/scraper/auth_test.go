@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+)
+
+func TestAPITokenIsSentAsBearerOnlyWhenConfigured(t *testing.T) {
+	for _, tc := range []struct {
+		token string
+		want  string
+	}{
+		{token: "hf_secret", want: "Bearer hf_secret"},
+		{token: "", want: ""},
+	} {
+		var got []string
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Values("Authorization")
+			w.Write([]byte(`[{"id":"a/b"}]`))
+		}), func(cfg *config.ScraperConfig) { cfg.APIToken = tc.token })
+
+		if _, err := s.FetchModels(context.Background(), server.URL+"/api/models"); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tc.want == "" && len(got) != 0:
+			t.Errorf("without a token: Authorization = %q, want none", got)
+		case tc.want != "" && (len(got) != 1 || got[0] != tc.want):
+			t.Errorf("with token %q: Authorization = %q, want %q", tc.token, got, tc.want)
+		}
+	}
+}
+
+func TestAPITokenIsNeverLogged(t *testing.T) {
+	buf := captureLog(t)
+	s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}), func(cfg *config.ScraperConfig) {
+		cfg.APIToken = "hf_secret"
+		cfg.DebugURLs = true
+	})
+
+	_, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+	if err == nil {
+		t.Fatal("a 401 was not reported")
+	}
+	if strings.Contains(err.Error(), "hf_secret") {
+		t.Errorf("the error %q leaks the token", err)
+	}
+	if strings.Contains(buf.String(), "hf_secret") {
+		t.Errorf("the log %q leaks the token", buf)
+	}
+}
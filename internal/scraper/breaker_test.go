@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	failure := errors.New("boom")
+
+	expect := func(want BreakerState) {
+		t.Helper()
+		if got := b.State(); got != want {
+			t.Fatalf("state = %s, want %s", got, want)
+		}
+	}
+
+	// Closed: failures below the threshold keep requests flowing.
+	if !b.allow() {
+		t.Fatal("closed breaker refused a request")
+	}
+	b.record(failure)
+	expect(BreakerClosed)
+
+	// The threshold-th consecutive failure opens it.
+	b.allow()
+	b.record(failure)
+	expect(BreakerOpen)
+	if b.allow() {
+		t.Fatal("open breaker let a request through during the cooldown")
+	}
+
+	// After the cooldown a single probe is let through.
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker refused the probe after the cooldown")
+	}
+	expect(BreakerHalfOpen)
+	if b.allow() {
+		t.Fatal("half-open breaker let a second request through")
+	}
+
+	// A failed probe reopens it for another cooldown.
+	b.record(failure)
+	expect(BreakerOpen)
+	if b.allow() {
+		t.Fatal("reopened breaker let a request through")
+	}
+
+	// A successful probe closes it again.
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker refused the second probe")
+	}
+	b.record(nil)
+	expect(BreakerClosed)
+	if !b.allow() {
+		t.Fatal("closed breaker refused a request")
+	}
+}
+
+func TestRateLimitedProbeLeavesBreakerHalfOpen(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(errors.New("boom"))
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker refused the probe after the cooldown")
+	}
+
+	// The probe was rate-limited: neither a recovery nor a failure, so
+	// another probe may be sent.
+	b.record(&RateLimitError{StatusCode: 429, RetryAfter: time.Second})
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("state = %s after a rate-limited probe, want half-open", got)
+	}
+	if !b.allow() {
+		t.Error("breaker refused a new probe after a rate-limited one")
+	}
+}
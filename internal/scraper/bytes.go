@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent with every request. Setting it explicitly turns off
+// the transport's transparent gzip handling, so readBody decompresses the
+// body itself and the compressed bytes can be counted.
+const acceptEncoding = "gzip, deflate"
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readBody reads a response body, decompressing it according to its
+// Content-Encoding. It returns the body together with the number of bytes
+// read off the wire.
+func readBody(resp *http.Response) ([]byte, int64, error) {
+	wire := &countingReader{r: resp.Body}
+	var body io.Reader = wire
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			return nil, wire.n, err
+		}
+		defer gz.Close()
+		body = gz
+	case "deflate":
+		inflated, err := inflate(wire)
+		if err != nil {
+			return nil, wire.n, err
+		}
+		defer inflated.Close()
+		body = inflated
+	default:
+		return nil, wire.n, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	data, err := io.ReadAll(body)
+	return data, wire.n, err
+}
+
+// inflate decompresses a deflate-encoded body. HTTP's deflate is a zlib
+// stream, but some servers send raw deflate data, so the zlib header is
+// checked before choosing the reader.
+func inflate(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	isZlib := header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+	if isZlib {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
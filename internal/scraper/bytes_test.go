@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+)
+
+func TestDownloadedBytesCountServedBody(t *testing.T) {
+	body := []byte(`[{"id":"a/one"},{"id":"a/two"},{"id":"a/three"}]` + strings.Repeat(" ", 512))
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(body)
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	for _, tc := range []struct {
+		byteCount string
+		want      int
+	}{
+		{byteCount: config.ByteCountDecompressed, want: len(body)},
+		{byteCount: config.ByteCountWire, want: compressed.Len()},
+	} {
+		recorder := metricstest.NewRecorder()
+		s := NewScraper(config.ScraperConfig{
+			BaseURL:           server.URL,
+			RequestsPerSecond: 1000,
+			BurstLimit:        1000,
+			ByteCount:         tc.byteCount,
+		}, recorder)
+
+		if _, err := s.FetchModels(context.Background(), server.URL+"/api/models"); err != nil {
+			t.Fatalf("%s: %v", tc.byteCount, err)
+		}
+		if got := recorder.Counter(metrics.ScraperBytes); got != float64(tc.want) {
+			t.Errorf("%s: counted %v bytes, want %d", tc.byteCount, got, tc.want)
+		}
+	}
+}
+
+func TestCompressedListingsAreDecodedWithTheirLinkHeader(t *testing.T) {
+	body := []byte(`[{"id":"a/one"},{"id":"a/two"}]`)
+	encode := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw deflate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+		"uncompressed": nil,
+	}
+	for name, newWriter := range encode {
+		var acceptEncoding string
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/models?cursor=x,y>; rel="next"`, r.Host))
+			if newWriter == nil {
+				w.Write(body)
+				return
+			}
+			w.Header().Set("Content-Encoding", strings.TrimPrefix(name, "raw "))
+			encoder := newWriter(w)
+			encoder.Write(body)
+			encoder.Close()
+		}), nil)
+
+		result, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(acceptEncoding, "gzip") {
+			t.Errorf("%s: Accept-Encoding = %q, want gzip offered", name, acceptEncoding)
+		}
+		if len(result.Models) != 2 || result.Models[1].ID != "a/two" {
+			t.Errorf("%s: decoded %+v, want both models", name, result.Models)
+		}
+		if want := server.URL + "/api/models?cursor=x,y"; result.NextURL != want {
+			t.Errorf("%s: NextURL = %q, want %q", name, result.NextURL, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// redacted replaces secrets in logged URLs.
+const redacted = "REDACTED"
+
+// redactURL hides credentials in a URL before it is logged: the userinfo
+// password and the value of any query parameter whose name contains "token".
+// Unparseable input is returned as is, and an empty string stays empty.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return raw
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	query := u.Query()
+	changed := false
+	for key := range query {
+		if strings.Contains(strings.ToLower(key), "token") {
+			query.Set(key, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
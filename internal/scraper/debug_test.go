@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestDebugURLsLogsFetchedAndNextURLs(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		buf := captureLog(t)
+		var serverURL string
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/models?cursor=abc&access_token=next-secret>; rel="next"`, serverURL))
+			w.Write([]byte(`[{"id":"a/b"}]`))
+		}), func(cfg *config.ScraperConfig) { cfg.DebugURLs = enabled })
+		serverURL = server.URL
+
+		if _, err := s.FetchModels(context.Background(), server.URL+"/api/models?sort=lastModified&token=secret"); err != nil {
+			t.Fatal(err)
+		}
+		logged := buf.String()
+
+		if !enabled {
+			if strings.Contains(logged, "/api/models") {
+				t.Errorf("DebugURLs off: logged %q", logged)
+			}
+			continue
+		}
+		for _, want := range []string{"sort=lastModified", "cursor=abc", "token=" + redacted, "access_token=" + redacted} {
+			if !strings.Contains(logged, want) {
+				t.Errorf("DebugURLs on: log %q is missing %q", logged, want)
+			}
+		}
+		if strings.Contains(logged, "secret") {
+			t.Errorf("DebugURLs on: log %q leaks a token", logged)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrHostNotAllowed is returned by FetchModels for URLs outside the host allowlist.
+var ErrHostNotAllowed = errors.New("host is not in the scraper allowlist")
+
+// hostAllowlist holds the lowercased host names the scraper may contact.
+type hostAllowlist map[string]bool
+
+// newHostAllowlist builds the allowlist from the configured hosts plus the
+// host of the base URL, which the operator chose explicitly.
+func newHostAllowlist(hosts []string, baseURL string) hostAllowlist {
+	allowed := make(hostAllowlist, len(hosts)+1)
+	for _, host := range hosts {
+		allowed[strings.ToLower(host)] = true
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		allowed[strings.ToLower(u.Hostname())] = true
+	}
+	return allowed
+}
+
+// check rejects URLs that are not plain HTTP(S) or point at a host outside
+// the allowlist, so a poisoned next-page link can't redirect the scraper.
+func (a hostAllowlist) check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrHostNotAllowed, u.Scheme)
+	}
+	if !a[strings.ToLower(u.Hostname())] {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// ErrTooManyRedirects is returned when a request is redirected more often than allowed.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// checkRedirect returns an http.Client CheckRedirect policy that follows at
+// most maxRedirects redirects, each to an allowed host.
+func (a hostAllowlist) checkRedirect(maxRedirects int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+		}
+		return a.check(req.URL.String())
+	}
+}
@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"hf-scraper/internal/config"
+)
+
+func TestHostAllowlistCheck(t *testing.T) {
+	allowed := newHostAllowlist([]string{"HuggingFace.co"}, "http://127.0.0.1:8080")
+	for rawURL, wantAllowed := range map[string]bool{
+		"https://huggingface.co/api/models?cursor=x": true,
+		"https://HUGGINGFACE.CO/api/models":          true,
+		"http://127.0.0.1:9999/api/models":           true,
+		"https://evil.example/api/models":            false,
+		"https://huggingface.co.evil.example/":       false,
+		"file:///etc/passwd":                         false,
+		"gopher://huggingface.co/":                   false,
+	} {
+		err := allowed.check(rawURL)
+		if wantAllowed && err != nil {
+			t.Errorf("%s: %v, want it allowed", rawURL, err)
+		}
+		if !wantAllowed && !errors.Is(err, ErrHostNotAllowed) {
+			t.Errorf("%s: err = %v, want ErrHostNotAllowed", rawURL, err)
+		}
+	}
+}
+
+func TestFetchModelsRefusesHostsOutsideAllowlist(t *testing.T) {
+	var requests atomic.Int32
+	s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`[{"id":"a/b"}]`))
+	}), func(cfg *config.ScraperConfig) { cfg.AllowedHosts = []string{"huggingface.co"} })
+
+	// localhost reaches the same server under a host that is not allowed.
+	offList := fmt.Sprintf("http://localhost:%d/api/models", server.Listener.Addr().(*net.TCPAddr).Port)
+	if _, err := s.FetchModels(context.Background(), offList); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("off-allowlist URL: err = %v, want ErrHostNotAllowed", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("the refused URL reached the server %d times", n)
+	}
+
+	result, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+	if err != nil {
+		t.Fatalf("allowlisted URL: %v", err)
+	}
+	if len(result.Models) != 1 || requests.Load() != 1 {
+		t.Errorf("allowlisted URL: got %d models from %d requests, want 1 from 1", len(result.Models), requests.Load())
+	}
+}
+
+// redirecting serves a listing at /api/models and redirects /hop/N to
+// /hop/N-1, and /hop/0 to the listing.
+func redirecting(w http.ResponseWriter, r *http.Request) {
+	if hops, ok := strings.CutPrefix(r.URL.Path, "/hop/"); ok {
+		n, _ := strconv.Atoi(hops)
+		target := "/api/models"
+		if n > 0 {
+			target = fmt.Sprintf("/hop/%d", n-1)
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+	w.Write([]byte(`[{"id":"a/b"}]`))
+}
+
+func TestRedirectsAreCappedAndKeptOnAllowedHosts(t *testing.T) {
+	offHost := httptest.NewServer(http.HandlerFunc(redirecting))
+	t.Cleanup(offHost.Close)
+	// The other server is only reachable as localhost, which is not allowed.
+	offHostURL := strings.Replace(offHost.URL, "127.0.0.1", "localhost", 1)
+
+	s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/leave" {
+			http.Redirect(w, r, offHostURL+"/api/models", http.StatusFound)
+			return
+		}
+		redirecting(w, r)
+	}), func(cfg *config.ScraperConfig) { cfg.MaxRedirects = 2 })
+	ctx := context.Background()
+
+	if _, err := s.FetchModels(ctx, server.URL+"/leave"); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("off-host redirect: err = %v, want ErrHostNotAllowed", err)
+	}
+	if _, err := s.FetchModels(ctx, server.URL+"/hop/1"); err != nil {
+		t.Errorf("2 redirects with a cap of 2: %v", err)
+	}
+	if _, err := s.FetchModels(ctx, server.URL+"/hop/2"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("3 redirects with a cap of 2: err = %v, want ErrTooManyRedirects", err)
+	}
+}
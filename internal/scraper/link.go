@@ -0,0 +1,84 @@
+package scraper
+
+import (
+	"strings"
+)
+
+// link is one entry of a Link header (RFC 8288).
+type link struct {
+	url string
+	// rels holds the lowercased relation types of the link.
+	rels []string
+}
+
+// nextLink returns the URL of the first link with relation "next" across the
+// given Link header values, or "" if there is none.
+func nextLink(headerValues []string) string {
+	for _, value := range headerValues {
+		for _, l := range parseLinks(value) {
+			for _, rel := range l.rels {
+				if rel == "next" {
+					return l.url
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// parseLinks splits a Link header value into its links. URLs are read up to
+// their closing angle bracket and quoted parameter values up to their closing
+// quote, so commas and semicolons inside either don't split a link. Entries
+// that are not of the form <url>; params are skipped.
+func parseLinks(value string) []link {
+	var links []link
+	for rest := value; ; {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			return links
+		}
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			return links
+		}
+		l := link{url: rest[start+1 : start+end]}
+		params, remaining := splitParams(rest[start+end+1:])
+		for _, param := range params {
+			name, val, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(name), "rel") {
+				val = strings.Trim(strings.TrimSpace(val), `"`)
+				l.rels = append(l.rels, strings.Fields(strings.ToLower(val))...)
+			}
+		}
+		links = append(links, l)
+		rest = remaining
+	}
+}
+
+// splitParams reads the ";"-separated parameters following a link's URL up to
+// the "," ending the link, ignoring separators inside quoted strings. It
+// returns the parameters and what follows the link.
+func splitParams(s string) (params []string, rest string) {
+	quoted := false
+	begin := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case (c == ';' || c == ',') && !quoted:
+			if param := strings.TrimSpace(s[begin:i]); param != "" {
+				params = append(params, param)
+			}
+			begin = i + 1
+			if c == ',' {
+				return params, s[i+1:]
+			}
+		}
+	}
+	if param := strings.TrimSpace(s[begin:]); param != "" {
+		params = append(params, param)
+	}
+	return params, ""
+}
@@ -0,0 +1,47 @@
+package scraper
+
+import "testing"
+
+func TestNextLinkIgnoresCommasInsideURLsAndQuotes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{
+			name:    "comma in the next URL",
+			headers: []string{`<https://hf.co/api/models?cursor=a,b&expand=likes,downloads>; rel="next"`},
+			want:    "https://hf.co/api/models?cursor=a,b&expand=likes,downloads",
+		},
+		{
+			name: "comma in an earlier URL",
+			headers: []string{`<https://hf.co/api/models?expand=likes,downloads&page=1>; rel="prev", ` +
+				`<https://hf.co/api/models?expand=likes,downloads&page=3>; rel="next"`},
+			want: "https://hf.co/api/models?expand=likes,downloads&page=3",
+		},
+		{
+			name:    "comma and semicolon in a quoted parameter",
+			headers: []string{`<https://hf.co/a>; title="one, two; three"; rel="prev", <https://hf.co/b>; rel="next"`},
+			want:    "https://hf.co/b",
+		},
+		{
+			name:    "several relation types",
+			headers: []string{`<https://hf.co/first>; rel="first", <https://hf.co/n?x=1,2>; rel="last Next"`},
+			want:    "https://hf.co/n?x=1,2",
+		},
+		{
+			name:    "next in a second header value",
+			headers: []string{`<https://hf.co/p?a=1,2>; rel="prev"`, `<https://hf.co/n?a=3,4>; rel=next`},
+			want:    "https://hf.co/n?a=3,4",
+		},
+		{
+			name:    "no next link",
+			headers: []string{`<https://hf.co/p?a=1,2>; rel="prev"`},
+			want:    "",
+		},
+	} {
+		if got := nextLink(tc.headers); got != tc.want {
+			t.Errorf("%s: nextLink = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
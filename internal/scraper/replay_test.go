@@ -0,0 +1,132 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+)
+
+// replayFixture is a captured Hub API response. "{{server}}" in a header
+// value is replaced with the URL of the replaying server.
+type replayFixture struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// newReplayScraper starts a server answering each request URI in routes with
+// the named fixture from testdata/replay, and returns a scraper that talks
+// to it through the server's own client.
+func newReplayScraper(t *testing.T, routes map[string]string) (*Scraper, *httptest.Server) {
+	t.Helper()
+	fixtures := make(map[string]replayFixture, len(routes))
+	for uri, name := range routes {
+		data, err := os.ReadFile(filepath.Join("testdata", "replay", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fixture replayFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		fixtures[uri] = fixture
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := fixtures[r.URL.RequestURI()]
+		if !ok {
+			t.Errorf("no fixture for %s", r.URL.RequestURI())
+			http.NotFound(w, r)
+			return
+		}
+		for key, value := range fixture.Headers {
+			w.Header().Set(key, strings.ReplaceAll(value, "{{server}}", server.URL))
+		}
+		w.WriteHeader(fixture.Status)
+		if fixture.Status == http.StatusOK {
+			w.Write(fixture.Body)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.ScraperConfig{BaseURL: server.URL, RequestsPerSecond: 1000, BurstLimit: 1000, MaxRedirects: 5}
+	return NewScraper(cfg, metrics.Noop{}, WithHTTPClient(server.Client())), server
+}
+
+func TestReplayFollowsCapturedPages(t *testing.T) {
+	const page2 = "/api/models?sort=lastModified&direction=-1&full=true&cursor=eyIkb3IiOlt7Imxhc3RNb2RpZmllZCI6IjIwMjUtMDYtMDFUMTE6NTk6MDAuMDAwWiJ9XX0%3D"
+	s, server := newReplayScraper(t, map[string]string{
+		"/api/models?sort=lastModified&direction=-1&full=true": "models_page1.json",
+		page2: "models_page2.json",
+	})
+	ctx := context.Background()
+
+	first, err := s.FetchModels(ctx, server.URL+"/api/models?sort=lastModified&direction=-1&full=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Models) != 2 || first.Models[0].ID != "google-bert/bert-base-uncased" || first.Models[0].Downloads != 61234567 {
+		t.Fatalf("first page = %+v, want bert-base-uncased with its downloads first", first.Models)
+	}
+	if first.NextURL != server.URL+page2 {
+		t.Fatalf("NextURL = %q, want the cursor link", first.NextURL)
+	}
+
+	second, err := s.FetchModels(ctx, first.NextURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.NextURL != "" {
+		t.Errorf("NextURL = %q on the last page, want none", second.NextURL)
+	}
+	want := []struct {
+		id        string
+		gated     domain.GatedStatus
+		likes     domain.FlexibleInt
+		downloads domain.FlexibleInt
+	}{
+		{id: "meta-llama/Llama-3.1-8B", gated: domain.GatedStatusManual, likes: 1534, downloads: 905123},
+		{id: "bigcode/starcoder2-15b", gated: domain.GatedStatusAuto, likes: 601, downloads: 20456},
+		{id: "acme/internal-preview", gated: domain.GatedStatusTrue},
+	}
+	if len(second.Models) != len(want) {
+		t.Fatalf("second page has %d models, want %d", len(second.Models), len(want))
+	}
+	for i, model := range second.Models {
+		if model.ID != want[i].id || model.Gated != want[i].gated || model.Likes != want[i].likes || model.Downloads != want[i].downloads {
+			t.Errorf("model %d = %s gated %q, %d likes, %d downloads, want %+v", i, model.ID, model.Gated, model.Likes, model.Downloads, want[i])
+		}
+	}
+}
+
+func TestReplayDecodesModelWithMissingFields(t *testing.T) {
+	s, _ := newReplayScraper(t, map[string]string{"/api/models/someone/sparse-upload": "model_sparse.json"})
+
+	model, err := s.FetchModelByID(context.Background(), "someone/sparse-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.ID != "someone/sparse-upload" || model.Author != "" || model.Tags != nil || !model.LastModified.IsZero() {
+		t.Errorf("model = %+v, want only the ID set", model)
+	}
+}
+
+func TestInjectedClientKeepsHostAllowlist(t *testing.T) {
+	s, server := newReplayScraper(t, map[string]string{"/api/models": "redirect_off_host.json"})
+
+	_, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+	if !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("following an off-host redirect = %v, want ErrHostNotAllowed", err)
+	}
+}
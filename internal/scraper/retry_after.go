@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultRetryAfter is used when a rate-limited response has no usable
+// Retry-After header.
+const defaultRetryAfter = 30 * time.Second
+
+// defaultMaxRetryAfter caps the Retry-After delay when
+// SCRAPER.MAX_RETRY_AFTER_SECONDS is not set.
+const defaultMaxRetryAfter = 5 * time.Minute
+
+// RateLimitError is returned when the API refuses a request with 429, or with
+// 503 and a Retry-After header. RetryAfter is how long the API asked us to
+// wait before the next request.
+type RateLimitError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited with status %d, retry after %s", e.StatusCode, e.RetryAfter)
+}
+
+// Unwrap exposes the status as a *StatusError, so callers that only look at
+// the status code keep working.
+func (e *RateLimitError) Unwrap() error {
+	return &StatusError{StatusCode: e.StatusCode}
+}
+
+// statusError builds the error for a non-200 response. A 503 is only treated
+// as rate limiting when it says when to come back; otherwise it is an outage.
+// The requested delay is cut to maxRetryAfter, so a bogus header can't stall
+// the scrape for days.
+func statusError(resp *http.Response, now time.Time, maxRetryAfter time.Duration) error {
+	retryAfter := resp.Header.Get("Retry-After")
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusServiceUnavailable && retryAfter != "":
+		return &RateLimitError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: min(parseRetryAfter(retryAfter, now), maxRetryAfter),
+		}
+	default:
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+// parseRetryAfter parses a Retry-After value, which is either a number of
+// seconds or an HTTP date. A missing or malformed value yields
+// defaultRetryAfter, and a date in the past yields zero. A number of seconds
+// too large for a time.Duration saturates at the longest one.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	// ParseInt saturates out-of-range values along with the error.
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		switch {
+		case seconds < 0:
+			return defaultRetryAfter
+		case seconds > int64(math.MaxInt64/time.Second):
+			return math.MaxInt64
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "120", want: 2 * time.Minute},
+		{value: "0", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "", want: defaultRetryAfter},
+		{value: "-5", want: defaultRetryAfter},
+		{value: "soon", want: defaultRetryAfter},
+		{value: "99999999999", want: math.MaxInt64},
+		{value: "99999999999999999999", want: math.MaxInt64},
+		{value: "-99999999999999999999", want: defaultRetryAfter},
+	} {
+		if got := parseRetryAfter(tc.value, now); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	for _, tc := range []struct {
+		maxSeconds int
+		header     string
+		want       time.Duration
+	}{
+		{maxSeconds: 60, header: "999999", want: time.Minute},
+		{maxSeconds: 60, header: "20", want: 20 * time.Second},
+		{maxSeconds: 0, header: "999999", want: defaultMaxRetryAfter},
+		{maxSeconds: 60, header: "99999999999", want: time.Minute},
+	} {
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", tc.header)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}), func(cfg *config.ScraperConfig) { cfg.MaxRetryAfterSeconds = tc.maxSeconds })
+
+		_, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+		var rateLimited *RateLimitError
+		if !errors.As(err, &rateLimited) {
+			t.Fatalf("err = %v, want a *RateLimitError", err)
+		}
+		if rateLimited.RetryAfter != tc.want {
+			t.Errorf("max %ds, Retry-After %s: RetryAfter = %s, want %s", tc.maxSeconds, tc.header, rateLimited.RetryAfter, tc.want)
+		}
+	}
+}
+
+func TestRateLimitingDoesNotOpenTheBreaker(t *testing.T) {
+	s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}), func(cfg *config.ScraperConfig) {
+		cfg.BreakerThreshold = 2
+		cfg.BreakerCooldownSeconds = 60
+	})
+
+	for i := range 5 {
+		_, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+		var rateLimited *RateLimitError
+		if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 5*time.Second {
+			t.Fatalf("request %d: err = %v, want the API's rate limit with its delay", i+1, err)
+		}
+	}
+	if got := s.BreakerState(); got != BreakerClosed {
+		t.Errorf("breaker is %s after repeated rate limiting, want closed", got)
+	}
+}
@@ -2,24 +2,44 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
-	"regexp"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"hf-scraper/internal/config"
 	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 )
 
 const hfAPIBase = "https://huggingface.co"
 
-// linkHeaderRegex is used to parse the 'Link' HTTP header for pagination.
-var linkHeaderRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+var tracer = tracing.Tracer("hf-scraper/scraper")
+
+// ErrModelNotFound is returned by FetchModelByID when the Hub has no such model.
+var ErrModelNotFound = errors.New("model not found")
+
+// StatusError is returned when the API responds with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
 
 // ScrapeResult holds the data returned from a single API call.
 type ScrapeResult struct {
@@ -29,61 +49,240 @@ type ScrapeResult struct {
 
 // Scraper is a client for the Hugging Face API.
 type Scraper struct {
+	baseURL string
 	client  *http.Client
 	limiter *rate.Limiter
+	// limitMu serializes runtime limit changes. It is a pointer so copies of
+	// the Scraper share it along with the limiter.
+	limitMu *sync.Mutex
+	// onDemand rate-limits FetchModelOnDemand apart from the scrape. It is
+	// nil when on-demand fetches are disabled.
+	onDemand *rate.Limiter
+	breaker  *circuitBreaker
+	// maxRetryAfter caps the delay a rate-limited response may ask for.
+	maxRetryAfter time.Duration
+	// throttle adapts the rate limit to the API's remaining quota.
+	throttle *adaptiveThrottle
+	// fieldMappings maps lowercased incoming JSON keys to their canonical names.
+	fieldMappings map[string]string
+	metrics       metrics.Metrics
+	// allowedHosts lists the hosts FetchModels may contact.
+	allowedHosts hostAllowlist
+	// debugURLs logs every fetched page URL and the next URL parsed from it.
+	debugURLs bool
+	// countWireBytes makes the downloaded bytes metric count compressed bytes
+	// as transferred rather than the decompressed bodies.
+	countWireBytes bool
+	// accept is the Accept header sent with every request, if not empty.
+	accept string
+	// userAgent is the User-Agent sent with every request, if not empty.
+	userAgent string
+	// apiToken is sent as a bearer token with every request, if not empty.
+	// It must never be logged.
+	apiToken string
+}
+
+// Option customizes a Scraper created by NewScraper.
+type Option func(*Scraper)
+
+// WithHTTPClient makes the Scraper issue its requests through a copy of
+// client, e.g. one pointed at an httptest.Server replaying captured API
+// responses, or one with a custom transport. Redirects are still checked
+// against the host allowlist before any CheckRedirect policy of client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Scraper) {
+		allowlisted := *client
+		allowRedirect, clientPolicy := s.client.CheckRedirect, client.CheckRedirect
+		allowlisted.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := allowRedirect(req, via); err != nil {
+				return err
+			}
+			if clientPolicy != nil {
+				return clientPolicy(req, via)
+			}
+			return nil
+		}
+		s.client = &allowlisted
+	}
 }
 
 // NewScraper creates and configures a new Scraper.
-func NewScraper(cfg config.ScraperConfig) *Scraper {
-	return &Scraper{
+func NewScraper(cfg config.ScraperConfig, m metrics.Metrics, opts ...Option) *Scraper {
+	// Config keys are case-insensitive, so incoming keys are matched the same way.
+	fieldMappings := make(map[string]string, len(cfg.FieldMappings))
+	for incoming, canonical := range cfg.FieldMappings {
+		fieldMappings[strings.ToLower(incoming)] = canonical
+	}
+
+	allowedHosts := newHostAllowlist(cfg.AllowedHosts, cfg.BaseURL)
+	s := &Scraper{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:       30 * time.Second,
+			CheckRedirect: allowedHosts.checkRedirect(cfg.MaxRedirects),
 		},
 		limiter: rate.NewLimiter(
 			rate.Limit(cfg.RequestsPerSecond),
 			cfg.BurstLimit,
 		),
+		limitMu:  &sync.Mutex{},
+		onDemand: onDemandLimiter(cfg.OnDemandRequestsPerSecond, cfg.OnDemandBurstLimit),
+		breaker: newCircuitBreaker(
+			cfg.BreakerThreshold,
+			time.Duration(cfg.BreakerCooldownSeconds)*time.Second,
+		),
+		maxRetryAfter:  maxRetryAfter(cfg.MaxRetryAfterSeconds),
+		fieldMappings:  fieldMappings,
+		metrics:        m,
+		debugURLs:      cfg.DebugURLs,
+		allowedHosts:   allowedHosts,
+		countWireBytes: cfg.ByteCount == config.ByteCountWire,
+		accept:         cfg.Accept,
+		userAgent:      userAgent(cfg.UserAgent, cfg.Contact),
+		apiToken:       cfg.APIToken,
 	}
+	s.throttle = newAdaptiveThrottle(s, cfg.AdaptiveThreshold, float64(cfg.RequestsPerSecond), cfg.BurstLimit)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// maxRetryAfter returns the configured cap on Retry-After delays, or
+// defaultMaxRetryAfter if none is set.
+func maxRetryAfter(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultMaxRetryAfter
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// onDemandLimiter returns the limiter of FetchModelOnDemand, or nil when
+// requestsPerSecond disables on-demand fetches.
+func onDemandLimiter(requestsPerSecond, burst int) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), max(burst, 1))
+}
+
+// userAgent builds the User-Agent header from the configured product and an
+// optional contact, following the "product (contact)" scraping convention.
+func userAgent(product, contact string) string {
+	if product == "" || contact == "" {
+		return product
+	}
+	return fmt.Sprintf("%s (%s)", product, contact)
+}
+
+// SetLimit reconfigures the request rate limit at runtime, e.g. when the
+// service moves between backfill and watch mode. An active adaptive throttle
+// keeps the rate down until the quota resets, and then restores this limit.
+// It is safe to call concurrently with requests: the new rate and burst take
+// effect at the same instant for every Wait that starts afterwards, while a
+// Wait already sleeping on its reservation finishes with the delay it was
+// given. A burst below one is raised to one so requests can still proceed.
+func (s *Scraper) SetLimit(requestsPerSecond float64, burst int) {
+	s.throttle.configure(requestsPerSecond, max(burst, 1))
+}
+
+// setLimiter applies a rate and burst to the limiter.
+func (s *Scraper) setLimiter(requestsPerSecond float64, burst int) {
+	s.limitMu.Lock()
+	defer s.limitMu.Unlock()
+
+	now := time.Now()
+	s.limiter.SetLimitAt(now, rate.Limit(requestsPerSecond))
+	s.limiter.SetBurstAt(now, burst)
+}
+
+// Limit returns the currently effective requests per second and burst.
+func (s *Scraper) Limit() (float64, int) {
+	s.limitMu.Lock()
+	defer s.limitMu.Unlock()
+	return float64(s.limiter.Limit()), s.limiter.Burst()
+}
+
+// BreakerState reports the state of the scraper's circuit breaker.
+func (s *Scraper) BreakerState() BreakerState {
+	return s.breaker.State()
+}
+
+// Ping checks that the configured API is reachable and answers model
+// listings, by requesting a single model. It waits on the rate limiter like
+// any other request and bypasses the circuit breaker.
+func (s *Scraper) Ping(ctx context.Context) error {
+	body, _, err := s.get(ctx, s.baseURL+"/api/models?limit=1")
+	if err != nil {
+		return err
+	}
+	if _, err := s.decodeModels(body); err != nil {
+		return fmt.Errorf("unexpected response from %s: %w", s.baseURL, err)
+	}
+	return nil
 }
 
 // FetchModels fetches a single page of models from the given URL.
 // It respects the rate limit and parses the 'Link' header for the next page.
+// While the circuit breaker is open it fails fast with ErrCircuitOpen, and
+// URLs outside the host allowlist fail with ErrHostNotAllowed before any request.
 func (s *Scraper) FetchModels(ctx context.Context, url string) (*ScrapeResult, error) {
-	// ... (rate limiting and request creation are the same)
-	if err := s.limiter.Wait(ctx); err != nil {
+	ctx, span := tracer.Start(ctx, "scraper.FetchModels", trace.WithAttributes(attribute.String("url.full", url)))
+	defer span.End()
+
+	if err := s.allowedHosts.check(url); err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if !s.breaker.allow() {
+		span.SetStatus(codes.Error, ErrCircuitOpen.Error())
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
+	result, err := s.fetchModels(ctx, url)
+	s.breaker.record(err)
+	s.observeRequest(start, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("hf.models.count", len(result.Models)))
+	return result, nil
+}
 
-	resp, err := s.client.Do(req)
+// observeRequest records the outcome of a single API request.
+func (s *Scraper) observeRequest(start time.Time, err error) {
+	s.metrics.IncCounter(metrics.ScraperRequests)
+	s.metrics.ObserveHistogram(metrics.ScraperRequestDuration, time.Since(start).Seconds())
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		s.metrics.IncCounter(metrics.ScraperRequestErrors)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	breakerOpen := 0.0
+	if s.breaker.State() != BreakerClosed {
+		breakerOpen = 1
 	}
+	s.metrics.SetGauge(metrics.ScraperBreakerOpen, breakerOpen)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchModels performs the rate-limited request for FetchModels.
+func (s *Scraper) fetchModels(ctx context.Context, url string) (*ScrapeResult, error) {
+	body, header, err := s.get(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	var models []domain.HuggingFaceModel
-	if err := json.Unmarshal(body, &models); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json response: %w", err)
+	models, err := s.decodeModels(body)
+	if err != nil {
+		return nil, err
 	}
 
-	// Re-introducing the logic to parse the Link header for the next page URL.
-	nextURL := ""
-	linkHeader := resp.Header.Get("Link")
-	if matches := linkHeaderRegex.FindStringSubmatch(linkHeader); len(matches) > 1 {
-		nextURL = matches[1]
+	// The Link header carries the next page URL, possibly among other links.
+	nextURL := nextLink(header.Values("Link"))
+	if s.debugURLs {
+		log.Printf("Scraper Debug: fetched %s (%d models), next %q", redactURL(url), len(models), redactURL(nextURL))
 	}
 
 	return &ScrapeResult{
@@ -91,3 +290,250 @@ func (s *Scraper) FetchModels(ctx context.Context, url string) (*ScrapeResult, e
 		NextURL: nextURL,
 	}, nil
 }
+
+// decodeModels decodes a page of models. Some filtered queries make the API
+// return a single object instead of an array, so the first non-whitespace
+// byte decides how the body is decoded; a lone object becomes a one-element page.
+func (s *Scraper) decodeModels(body []byte) ([]domain.HuggingFaceModel, error) {
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		model, err := s.decodeModel(body)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.HuggingFaceModel{*model}, nil
+	}
+
+	body, err := s.remapFields(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remap response fields: %w", err)
+	}
+
+	var models []domain.HuggingFaceModel
+	if err := json.Unmarshal(body, &models); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal json response: %w", err)
+	}
+	return models, nil
+}
+
+// decodeModel decodes a single model object.
+func (s *Scraper) decodeModel(body []byte) (*domain.HuggingFaceModel, error) {
+	body, err := s.remapObjectFields(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remap response fields: %w", err)
+	}
+
+	var model domain.HuggingFaceModel
+	if err := json.Unmarshal(body, &model); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal json response: %w", err)
+	}
+	return &model, nil
+}
+
+// remapFields rewrites the keys of every model object in a raw JSON array
+// according to the configured field mappings, so upstream renames can be
+// absorbed without changing struct tags. It is a no-op without mappings.
+func (s *Scraper) remapFields(body []byte) ([]byte, error) {
+	if len(s.fieldMappings) == 0 {
+		return body, nil
+	}
+
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(body, &objects); err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		s.remapObject(object)
+	}
+	return json.Marshal(objects)
+}
+
+// remapObjectFields is the single-object variant of remapFields.
+func (s *Scraper) remapObjectFields(body []byte) ([]byte, error) {
+	if len(s.fieldMappings) == 0 {
+		return body, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	s.remapObject(object)
+	return json.Marshal(object)
+}
+
+// remapObject renames the keys of a single decoded JSON object in place.
+func (s *Scraper) remapObject(object map[string]json.RawMessage) {
+	renamed := make(map[string]json.RawMessage)
+	for key, value := range object {
+		if canonical, ok := s.fieldMappings[strings.ToLower(key)]; ok {
+			renamed[canonical] = value
+			delete(object, key)
+		}
+	}
+	for key, value := range renamed {
+		object[key] = value
+	}
+}
+
+// FetchModelByID fetches the full metadata of a single model from the Hub.
+// It returns ErrModelNotFound when the model does not exist.
+func (s *Scraper) FetchModelByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	if !s.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
+	model, err := s.fetchModelByID(ctx, id)
+	if errors.Is(err, ErrModelNotFound) {
+		// A missing model says nothing about the health of the API.
+		s.breaker.record(nil)
+		s.observeRequest(start, nil)
+	} else {
+		s.breaker.record(err)
+		s.observeRequest(start, err)
+	}
+	return model, err
+}
+
+// ErrOnDemandUnavailable is returned by FetchModelOnDemand when on-demand
+// fetches are disabled or their rate limit is used up.
+var ErrOnDemandUnavailable = errors.New("on-demand fetch not available right now")
+
+// FetchModelOnDemand fetches a model like FetchModelByID, for reads triggered
+// by users rather than by the scrape. It draws on its own rate limit, so user
+// traffic can neither starve the backfill and watcher nor trip the circuit
+// breaker: it fails at once with ErrOnDemandUnavailable instead of waiting
+// for a token, fails fast with ErrCircuitOpen while the breaker is open, and
+// its outcome is not recorded by the breaker.
+func (s *Scraper) FetchModelOnDemand(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	if s.onDemand == nil || !s.onDemand.Allow() {
+		return nil, ErrOnDemandUnavailable
+	}
+	if s.breaker.State() == BreakerOpen {
+		return nil, ErrCircuitOpen
+	}
+	start := time.Now()
+	body, _, err := s.send(ctx, s.modelURL(id))
+	model, err := s.modelFromResponse(body, err)
+	if errors.Is(err, ErrModelNotFound) {
+		s.observeRequest(start, nil)
+	} else {
+		s.observeRequest(start, err)
+	}
+	return model, err
+}
+
+// fetchModelByID performs the rate-limited request for FetchModelByID.
+func (s *Scraper) fetchModelByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	body, _, err := s.get(ctx, s.modelURL(id))
+	return s.modelFromResponse(body, err)
+}
+
+// modelURL returns the API URL of a single model.
+func (s *Scraper) modelURL(id string) string {
+	segments := strings.Split(id, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/api/models/" + strings.Join(segments, "/")
+}
+
+// modelFromResponse decodes the response to a single-model request, turning
+// a 404 into ErrModelNotFound.
+func (s *Scraper) modelFromResponse(body []byte, err error) (*domain.HuggingFaceModel, error) {
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, ErrModelNotFound
+		}
+		return nil, err
+	}
+	return s.decodeModel(body)
+}
+
+// get issues a rate-limited GET request and returns the body and headers of a
+// 200 response. Rate limiting is reported as a *RateLimitError and any other
+// status as a *StatusError.
+func (s *Scraper) get(ctx context.Context, url string) ([]byte, http.Header, error) {
+	s.throttle.restoreIfDue()
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	return s.send(ctx, url)
+}
+
+// send issues a GET request without waiting on the rate limiter and returns
+// the body and headers of a 200 response, with errors as described for get.
+func (s *Scraper) send(ctx context.Context, url string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if s.accept != "" {
+		req.Header.Set("Accept", s.accept)
+	}
+	if s.userAgent != "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+	if s.apiToken != "" {
+		// The client drops this header on redirects to another host.
+		req.Header.Set("Authorization", "Bearer "+s.apiToken)
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	s.throttle.observe(resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, statusError(resp, time.Now(), s.maxRetryAfter)
+	}
+
+	body, wireBytes, err := readBody(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if s.countWireBytes {
+		s.metrics.AddCounter(metrics.ScraperBytes, float64(wireBytes))
+	} else {
+		s.metrics.AddCounter(metrics.ScraperBytes, float64(len(body)))
+	}
+	return body, resp.Header, nil
+}
+
+// FetchStream follows pagination from startURL and streams the models one at a
+// time, so consumers can process large scrapes without holding whole pages.
+// The model channel is closed when the last page is done or on the first error,
+// which is then delivered on the error channel.
+func (s *Scraper) FetchStream(ctx context.Context, startURL string) (<-chan domain.HuggingFaceModel, <-chan error) {
+	models := make(chan domain.HuggingFaceModel)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(models)
+		defer close(errs)
+
+		for url := startURL; url != ""; {
+			result, err := s.FetchModels(ctx, url)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for _, model := range result.Models {
+				select {
+				case models <- model:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			url = result.NextURL
+		}
+	}()
+
+	return models, errs
+}
@@ -0,0 +1,222 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/metrics"
+)
+
+// newTestScraper starts a server running handler and returns a scraper whose
+// base URL points at it. cfg may adjust the configuration first.
+func newTestScraper(t *testing.T, handler http.Handler, cfg func(*config.ScraperConfig)) (*Scraper, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	scraperCfg := config.ScraperConfig{
+		BaseURL:           server.URL,
+		RequestsPerSecond: 1000,
+		BurstLimit:        1000,
+	}
+	if cfg != nil {
+		cfg(&scraperCfg)
+	}
+	return NewScraper(scraperCfg, metrics.Noop{}), server
+}
+
+func TestFieldMappingsRenameIncomingKeys(t *testing.T) {
+	s, _ := newTestScraper(t, nil, func(cfg *config.ScraperConfig) {
+		cfg.FieldMappings = map[string]string{"pipelineTag": "pipeline_tag", "DownloadCount": "downloads"}
+	})
+
+	models, err := s.decodeModels([]byte(`[{"id":"a/b","pipelineTag":"text-generation","downloadCount":42}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 {
+		t.Fatalf("decoded %d models, want 1", len(models))
+	}
+	if got := models[0].PipelineTag; got != "text-generation" {
+		t.Errorf("PipelineTag = %q, want text-generation", got)
+	}
+	if got := models[0].Downloads; got != 42 {
+		t.Errorf("Downloads = %d, want 42", got)
+	}
+}
+
+// pagedListing serves pages of model IDs, linking each page to the next
+// with a "page" query parameter.
+func pagedListing(t *testing.T, pages ...[]string) http.Handler {
+	t.Helper()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page+1 < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/models?page=%d>; rel="next"`, r.Host, page+1))
+		}
+		models := []map[string]string{}
+		if page < len(pages) {
+			for _, id := range pages[page] {
+				models = append(models, map[string]string{"id": id})
+			}
+		}
+		json.NewEncoder(w).Encode(models)
+	})
+}
+
+func TestFetchStreamFollowsPagination(t *testing.T) {
+	s, server := newTestScraper(t, pagedListing(t, []string{"a/1", "a/2"}, []string{"a/3"}, []string{"a/4"}), nil)
+
+	models, errs := s.FetchStream(context.Background(), server.URL+"/api/models")
+	var ids []string
+	for model := range models {
+		ids = append(ids, model.ID)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a/1", "a/2", "a/3", "a/4"}; !slices.Equal(ids, want) {
+		t.Errorf("streamed %v, want %v", ids, want)
+	}
+	if _, open := <-errs; open {
+		t.Error("error channel is still open")
+	}
+}
+
+func TestSetLimitChangesEffectiveRate(t *testing.T) {
+	s, _ := newTestScraper(t, nil, nil)
+
+	s.SetLimit(20, 0)
+	if rps, burst := s.Limit(); rps != 20 || burst != 1 {
+		t.Fatalf("Limit() = %v, %d, want 20, 1 with the burst raised to one", rps, burst)
+	}
+
+	// With a burst of one, each Wait after the first takes a 1/20 s token.
+	ctx := context.Background()
+	start := time.Now()
+	for range 4 {
+		if err := s.limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("four requests took %s, want at least 150ms at 20 requests/s", elapsed)
+	}
+}
+
+func TestDecodeModelsAcceptsArrayAndSingleObject(t *testing.T) {
+	s, _ := newTestScraper(t, nil, nil)
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "array", body: `[{"id":"a/one"},{"id":"a/two"}]`, want: []string{"a/one", "a/two"}},
+		{name: "empty array", body: `[]`, want: []string{}},
+		{name: "single object", body: "\n  {\"id\":\"a/one\"}", want: []string{"a/one"}},
+	} {
+		models, err := s.decodeModels([]byte(tc.body))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		got := []string{}
+		for _, model := range models {
+			got = append(got, model.ID)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: decoded %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := s.decodeModels([]byte(`"not a model"`)); err == nil {
+		t.Error("a JSON string decoded without an error")
+	}
+}
+
+func TestPingReportsWhetherTheHubIsReachable(t *testing.T) {
+	var pinged string
+	s, _ := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinged = r.URL.RequestURI()
+		w.Write([]byte(`[{"id":"a/b"}]`))
+	}), nil)
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("reachable hub: %v", err)
+	}
+	if pinged != "/api/models?limit=1" {
+		t.Errorf("pinged %q, want a single-model listing", pinged)
+	}
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"server error": func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+		"not the API":  func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("<html>login</html>")) },
+	} {
+		s, _ := newTestScraper(t, handler, nil)
+		if err := s.Ping(context.Background()); err == nil {
+			t.Errorf("%s: Ping succeeded, want an error", name)
+		}
+	}
+
+	s, server := newTestScraper(t, http.NotFoundHandler(), nil)
+	server.Close()
+	if err := s.Ping(context.Background()); err == nil {
+		t.Error("unreachable hub: Ping succeeded, want an error")
+	}
+}
+
+func TestConfiguredAcceptHeaderReachesTheHub(t *testing.T) {
+	for _, accept := range []string{"application/vnd.hf.v2+json", "application/json"} {
+		var got string
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("Accept")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"a/b"}]`))
+		}), func(cfg *config.ScraperConfig) { cfg.Accept = accept })
+
+		result, err := s.FetchModels(context.Background(), server.URL+"/api/models")
+		if err != nil {
+			t.Fatalf("%s: %v", accept, err)
+		}
+		if got != accept {
+			t.Errorf("Accept = %q, want %q", got, accept)
+		}
+		if len(result.Models) != 1 {
+			t.Errorf("%s: decoded %d models, want 1", accept, len(result.Models))
+		}
+	}
+}
+
+func TestUserAgentFollowsConfig(t *testing.T) {
+	for _, tc := range []struct {
+		userAgent, contact string
+		want               string
+	}{
+		{userAgent: "hf-scraper/1.0", contact: "ops@example.com", want: "hf-scraper/1.0 (ops@example.com)"},
+		{userAgent: "hf-scraper/1.0", want: "hf-scraper/1.0"},
+		{userAgent: "mirror-bot/2", contact: "https://example.com/bot", want: "mirror-bot/2 (https://example.com/bot)"},
+	} {
+		var got string
+		s, server := newTestScraper(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("User-Agent")
+			w.Write([]byte(`[{"id":"a/b"}]`))
+		}), func(cfg *config.ScraperConfig) {
+			cfg.UserAgent, cfg.Contact = tc.userAgent, tc.contact
+		})
+
+		if _, err := s.FetchModels(context.Background(), server.URL+"/api/models"); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("User-Agent = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	installProvider sync.Once
+	testProvider    *sdktrace.TracerProvider
+)
+
+// recordSpans installs an SDK tracer provider as the global one, once, and
+// returns a recorder of the spans ended until the test finishes.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	installProvider.Do(func() {
+		testProvider = sdktrace.NewTracerProvider()
+		otel.SetTracerProvider(testProvider)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	testProvider.RegisterSpanProcessor(recorder)
+	t.Cleanup(func() { testProvider.UnregisterSpanProcessor(recorder) })
+	return recorder
+}
+
+// spanAttributes returns the attributes of the only ended span named name.
+func spanAttributes(t *testing.T, recorder *tracetest.SpanRecorder, name string) map[attribute.Key]attribute.Value {
+	t.Helper()
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("ended %d %s spans, want 1", len(found), name)
+	}
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range found[0].Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func TestFetchModelsRecordsSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	s, server := newTestScraper(t, pagedListing(t, []string{"a/one", "a/two"}), nil)
+	url := server.URL + "/api/models"
+
+	if _, err := s.FetchModels(context.Background(), url); err != nil {
+		t.Fatal(err)
+	}
+
+	attributes := spanAttributes(t, recorder, "scraper.FetchModels")
+	if got := attributes["url.full"].AsString(); got != url {
+		t.Errorf("url.full = %q, want %q", got, url)
+	}
+	if got := attributes["http.response.status_code"].AsInt64(); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %d, want 200", got)
+	}
+	if got := attributes["hf.models.count"].AsInt64(); got != 2 {
+		t.Errorf("hf.models.count = %d, want 2", got)
+	}
+}
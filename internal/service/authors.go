@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// knownAuthors is an in-memory set of the authors already stored, refreshed
+// from the database once it is older than the configured interval.
+type knownAuthors struct {
+	mu          sync.Mutex
+	authors     map[string]bool
+	refreshedAt time.Time
+}
+
+// refresh reloads the set from storage when it has never been loaded or is
+// older than maxAge. On error the previous set is kept.
+func (k *knownAuthors) refresh(ctx context.Context, storage ModelStorage, maxAge time.Duration) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.authors != nil && time.Since(k.refreshedAt) < maxAge {
+		return nil
+	}
+
+	authors, err := storage.DistinctAuthors(ctx)
+	if err != nil {
+		return err
+	}
+	k.authors = make(map[string]bool, len(authors))
+	for _, author := range authors {
+		k.authors[author] = true
+	}
+	k.refreshedAt = time.Now()
+	return nil
+}
+
+// known reports whether author is in the set.
+func (k *knownAuthors) known(author string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.authors[author]
+}
+
+// add records author as known.
+func (k *knownAuthors) add(author string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.authors == nil {
+		k.authors = make(map[string]bool)
+	}
+	k.authors[author] = true
+}
+
+// modelAuthor returns the author of a model, falling back to the namespace
+// part of its ID when the API left the field empty.
+func modelAuthor(model domain.HuggingFaceModel) string {
+	if model.Author != "" {
+		return model.Author
+	}
+	if author, _, ok := strings.Cut(model.ID, "/"); ok {
+		return author
+	}
+	return ""
+}
+
+// enrichNewAuthors replaces the models of authors not seen before with their
+// full single-model record from the Hub. Models of known authors are returned
+// untouched. A failed fetch keeps the listing record, so nothing is lost, and
+// leaves the author unknown so a later model of theirs is enriched instead.
+func (s *Service) enrichNewAuthors(ctx context.Context, models []domain.HuggingFaceModel) []domain.HuggingFaceModel {
+	if !s.cfg.EnrichNewAuthors {
+		return models
+	}
+	maxAge := time.Duration(s.cfg.AuthorsRefreshMinutes) * time.Minute
+	if err := s.authors.refresh(ctx, s.modelStorage, maxAge); err != nil {
+		log.Printf("Watch Cycle Warning: could not load known authors, skipping enrichment: %v", err)
+		return models
+	}
+
+	enriched := 0
+	for i, model := range models {
+		author := modelAuthor(model)
+		if author == "" || s.authors.known(author) {
+			continue
+		}
+		full, err := s.scraper.FetchModelByID(ctx, model.ID)
+		if err != nil {
+			log.Printf("Watch Cycle Warning: could not enrich %s from new author %s: %v", model.ID, author, err)
+			continue
+		}
+		s.authors.add(author)
+		models[i] = *full
+		enriched++
+	}
+	if enriched > 0 {
+		log.Printf("Watch Cycle: Enriched %d models from new authors.", enriched)
+	}
+	return models
+}
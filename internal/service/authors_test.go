@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"hf-scraper/internal/domain"
+)
+
+// enriched returns the full record the hub serves for a model, marked so the
+// test can tell it apart from the listing record.
+func enriched(m domain.HuggingFaceModel) domain.HuggingFaceModel {
+	m.SHA = "full-record"
+	return m
+}
+
+func TestEnrichmentOnlyFetchesModelsOfNewAuthors(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("known/old", 0))
+	listed := []domain.HuggingFaceModel{model("known/new", 3), model("fresh/one", 2), model("fresh/two", 1)}
+	env.hub.setPages(listed)
+	for _, m := range listed {
+		env.hub.setModels(enriched(m))
+	}
+	env.watcher.EnrichNewAuthors = true
+	env.watcher.AuthorsRefreshMinutes = 60
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+
+	if n := env.hub.requestCount("/api/models/known/"); n != 0 {
+		t.Errorf("a known author's model was fetched %d times, want 0", n)
+	}
+	if n := env.hub.requestCount("/api/models/fresh/one"); n != 1 {
+		t.Errorf("the new author's first model was fetched %d times, want 1", n)
+	}
+	if n := env.hub.requestCount("/api/models/fresh/two"); n != 0 {
+		t.Errorf("the new author's second model was fetched %d times, want 0 once the author is known", n)
+	}
+	if sha := env.stored(t, "fresh/one").SHA; sha != "full-record" {
+		t.Errorf("fresh/one SHA = %q, want the enriched record", sha)
+	}
+	if sha := env.stored(t, "known/new").SHA; sha != "" {
+		t.Errorf("known/new SHA = %q, want the listing record", sha)
+	}
+}
+
+func TestFailedEnrichmentLeavesAuthorUnknown(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages([]domain.HuggingFaceModel{model("flaky/one", 1)})
+	env.hub.setModels(enriched(model("flaky/two", 2)))
+	env.hub.failWith(func(r *http.Request) int {
+		if r.URL.Path == "/api/models/flaky/one" {
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+	env.watcher.EnrichNewAuthors = true
+	env.watcher.AuthorsRefreshMinutes = 60
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+	env.stored(t, "flaky/one")
+
+	env.hub.setPages([]domain.HuggingFaceModel{model("flaky/two", 2), model("flaky/one", 1)})
+	svc.RunWatchCycle(context.Background())
+
+	if n := env.hub.requestCount("/api/models/flaky/two"); n != 1 {
+		t.Errorf("flaky/two was fetched %d times, want 1 after the author's first enrichment failed", n)
+	}
+	if sha := env.stored(t, "flaky/two").SHA; sha != "full-record" {
+		t.Errorf("flaky/two SHA = %q, want the enriched record", sha)
+	}
+}
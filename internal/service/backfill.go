@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+)
+
+// cursorCoordinator advances the saved backfill cursor as pages written by
+// parallel writers complete, possibly out of order. Each fetched page gets a
+// sequence number; completions are logged per page and the cursor only moves
+// through the contiguous prefix of written pages, so a crash never resumes
+// past a page that was fetched but not yet stored.
+type cursorCoordinator struct {
+	mu sync.Mutex
+	// next is the sequence number of the oldest page not yet written.
+	next int
+	// done maps pages written ahead of next to the cursor that follows them.
+	done map[int]string
+	// persist saves a cursor. It is only called with mu held, so saves are
+	// serialized and never move the cursor backwards.
+	persist func(cursor string) error
+}
+
+// newCursorCoordinator returns a coordinator expecting page 0 first.
+func newCursorCoordinator(persist func(cursor string) error) *cursorCoordinator {
+	return &cursorCoordinator{done: make(map[int]string), persist: persist}
+}
+
+// complete records that page seq has been written and that nextURL follows
+// it. If this extends the contiguous prefix, the cursor after its last page
+// is persisted. Completions from before the last restart are ignored.
+func (c *cursorCoordinator) complete(seq int, nextURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if seq < c.next {
+		return
+	}
+	c.done[seq] = nextURL
+	cursor, advanced := "", false
+	for {
+		next, ok := c.done[c.next]
+		if !ok {
+			break
+		}
+		delete(c.done, c.next)
+		cursor, advanced = next, true
+		c.next++
+	}
+	if !advanced {
+		return
+	}
+	if err := c.persist(cursor); err != nil {
+		// The next completion saves a newer cursor anyway.
+		log.Printf("CRITICAL: FAILED TO SAVE BACKFILL CURSOR. Error: %v", err)
+	}
+}
+
+// restart discards the pages logged so far and persists cursor, so the
+// backfill resumes there with page seq. Writes of older pages that are still
+// in flight no longer move the cursor when they complete.
+func (c *cursorCoordinator) restart(seq int, cursor string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next = seq
+	clear(c.done)
+	return c.persist(cursor)
+}
+
+// writeBackfillPage stores one backfill page, retrying until it succeeds or
+// ctx is cancelled. It returns false in the latter case. Models the database
+// rejects individually are logged and skipped rather than retried, so one bad
+// record cannot hold up the whole backfill.
+func (s *Service) writeBackfillPage(ctx context.Context, models []domain.HuggingFaceModel) bool {
+	for len(models) > 0 {
+		if s.indexBuild.isPaused() {
+			log.Println("Backfill: Index build in progress, waiting before storing the page.")
+		}
+		if err := s.indexBuild.wait(ctx); err != nil {
+			return false
+		}
+		log.Printf("Backfill: Storing %d models...", len(models))
+		err := s.bulkUpsert(ctx, models)
+		var partial *PartialWriteError
+		if errors.As(err, &partial) {
+			for id, reason := range partial.Failed {
+				log.Printf("Backfill: Skipping model %s, which the database rejected: %s", id, reason)
+			}
+			s.metrics.AddCounter(metrics.ModelUpsertErrors, float64(len(partial.Failed)))
+			models = slices.DeleteFunc(models, func(model domain.HuggingFaceModel) bool {
+				_, rejected := partial.Failed[model.ID]
+				return rejected
+			})
+			err = nil
+		}
+		if err == nil {
+			s.metrics.AddCounter(metrics.ModelsUpserted, float64(len(models)))
+			s.recordHistory(ctx, models)
+			break
+		}
+		log.Printf("CRITICAL: FAILED TO BULK UPSERT MODELS. Error: %v", err)
+		s.metrics.IncCounter(metrics.ModelUpsertErrors)
+		// We add a small sleep to avoid a rapid failure loop on DB issues.
+		select {
+		case <-time.After(10 * time.Second):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	s.metrics.IncCounter(metrics.BackfillPages)
+	return true
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// firstPage returns the "page" parameter of the first listing request.
+func firstPage(t *testing.T, hub *fakeHub) string {
+	t.Helper()
+	requests := hub.listRequests()
+	if len(requests) == 0 {
+		t.Fatal("the hub received no listing requests")
+	}
+	parsed, err := url.Parse(requests[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query().Get("page")
+}
+
+func TestBackfillStartsFromConfiguredURL(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/zero", 0)},
+		[]domain.HuggingFaceModel{model("a/one", 1)},
+		[]domain.HuggingFaceModel{model("a/two", 2)},
+	)
+	env.watcher.BackfillStartURL = env.hub.server.URL + "/api/models?page=1"
+	svc := env.newService()
+
+	if err := svc.RunBackfill(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if page := firstPage(t, env.hub); page != "1" {
+		t.Errorf("the backfill started at page %q, want the configured page 1", page)
+	}
+	if model, _ := env.memory.FindByID(context.Background(), "a/zero"); model != nil {
+		t.Error("the page before the configured start URL was scraped")
+	}
+	env.stored(t, "a/two")
+}
+
+func TestSavedCursorWinsOverConfiguredStartURL(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/zero", 0)},
+		[]domain.HuggingFaceModel{model("a/one", 1)},
+		[]domain.HuggingFaceModel{model("a/two", 2)},
+	)
+	env.watcher.BackfillStartURL = env.hub.server.URL + "/api/models?page=1"
+	svc := env.newService()
+
+	if err := svc.RunBackfill(context.Background(), env.hub.server.URL+"/api/models?page=2"); err != nil {
+		t.Fatal(err)
+	}
+	if page := firstPage(t, env.hub); page != "2" {
+		t.Errorf("the backfill started at page %q, want the saved cursor's page 2", page)
+	}
+}
+
+func TestGoneCursorRestartsFromConfiguredStartURL(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/zero", 0)},
+		[]domain.HuggingFaceModel{model("a/one", 1)},
+		[]domain.HuggingFaceModel{model("a/two", 2)},
+	)
+	env.hub.failWith(func(r *http.Request) int {
+		if r.URL.Query().Get("page") == "7" {
+			return http.StatusNotFound
+		}
+		return 0
+	})
+	env.watcher.BackfillStartURL = env.hub.server.URL + "/api/models?page=1"
+	svc := env.newService()
+
+	if err := svc.RunBackfill(context.Background(), env.hub.server.URL+"/api/models?page=7"); err != nil {
+		t.Fatal(err)
+	}
+	requests := env.hub.listRequests()
+	if len(requests) < 2 {
+		t.Fatalf("the hub received %v, want a restart after the stale cursor", requests)
+	}
+	restart, err := url.Parse(requests[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := restart.Query().Get("page"); page != "1" {
+		t.Errorf("the backfill restarted at page %q, want the configured page 1", page)
+	}
+	if model, _ := env.memory.FindByID(context.Background(), "a/zero"); model != nil {
+		t.Error("the restart scraped the page before the configured start URL")
+	}
+	env.stored(t, "a/two")
+}
+
+func TestBackfillStopsAfterMaxDuration(t *testing.T) {
+	env := newTestEnv(t)
+	pages := make([][]domain.HuggingFaceModel, 10)
+	for i := range pages {
+		pages[i] = []domain.HuggingFaceModel{model(fmt.Sprintf("a/page-%d", i), i)}
+	}
+	env.hub.setPages(pages...)
+	// Each listing request takes a minute on the fake clock.
+	var mu sync.Mutex
+	now := at(0)
+	env.hub.failWith(func(r *http.Request) int {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Minute)
+		return 0
+	})
+	env.watcher.MaxBackfillMinutes = 3
+	svc := env.newService(service.WithClock(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}))
+	ctx := context.Background()
+
+	if err := svc.RunBackfill(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(env.hub.listRequests()); n != 3 {
+		t.Errorf("the backfill fetched %d pages, want 3 in 3 minutes", n)
+	}
+	env.stored(t, "a/page-2")
+	doc, err := env.status.GetStatusDocument(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Status != domain.StatusWatching {
+		t.Errorf("status = %s, want %s after the time limit", doc.Status, domain.StatusWatching)
+	}
+	if cursor := doc.Cursor(domain.DefaultCursorShard); !strings.Contains(cursor, "page=3") {
+		t.Errorf("saved cursor = %q, want the first page not fetched", cursor)
+	}
+}
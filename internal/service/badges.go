@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+)
+
+// WithBadges makes the Service add the popularity badges configured by cfg to
+// the models it returns. RunBadgeThresholds keeps their thresholds current.
+func WithBadges(cfg config.BadgesConfig) Option {
+	return func(s *Service) {
+		s.badgesCfg = cfg
+	}
+}
+
+// PercentileRank returns the 1-based rank, counting from the highest value,
+// of the lowest of total values that still reaches percentile, e.g. rank 10
+// for the 99th percentile of 1000 values. It is at least 1.
+func PercentileRank(total int64, percentile float64) int64 {
+	rank := int64(math.Ceil(float64(total) * (100 - percentile) / 100))
+	return min(max(rank, 1), total)
+}
+
+// badgeThresholds holds the download and like counts a model must reach for
+// the Popular and Trending badges. Until they are first computed, neither
+// badge is given.
+type badgeThresholds struct {
+	mu        sync.RWMutex
+	ready     bool
+	downloads int64
+	likes     int64
+}
+
+// get returns the current thresholds.
+func (t *badgeThresholds) get() (downloads, likes int64, ready bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.downloads, t.likes, t.ready
+}
+
+// set replaces the thresholds.
+func (t *badgeThresholds) set(downloads, likes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.downloads, t.likes, t.ready = downloads, likes, true
+}
+
+// RunBadgeThresholds computes the percentile thresholds of the Popular and
+// Trending badges right away and then every BADGES.REFRESH_MINUTES, until ctx
+// is cancelled. It is meant to run in the background.
+func (s *Service) RunBadgeThresholds(ctx context.Context) {
+	if !s.badgesCfg.Enabled {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(s.badgesCfg.RefreshMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		s.refreshBadgeThresholds(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshBadgeThresholds recomputes the thresholds, keeping the previous ones
+// on error.
+func (s *Service) refreshBadgeThresholds(ctx context.Context) {
+	downloads, err := s.modelStorage.ValueAtPercentile(ctx, "downloads", s.badgesCfg.PopularPercentile)
+	if errors.Is(err, ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Badges Error: could not compute the downloads threshold: %v", err)
+		return
+	}
+	likes, err := s.modelStorage.ValueAtPercentile(ctx, "likes", s.badgesCfg.TrendingPercentile)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Printf("Badges Error: could not compute the likes threshold: %v", err)
+		return
+	}
+	s.badges.set(downloads, likes)
+	log.Printf("Badges: Popular from %d downloads, Trending from %d likes.", downloads, likes)
+}
+
+// withBadges sets the badges of models read for a response, in place.
+func (s *Service) withBadges(models []domain.HuggingFaceModel) []domain.HuggingFaceModel {
+	if !s.badgesCfg.Enabled {
+		return models
+	}
+	downloads, likes, ready := s.badges.get()
+	now := s.now()
+	for i := range models {
+		models[i].Badges = badgesFor(models[i], s.badgesCfg, downloads, likes, ready, now)
+	}
+	return models
+}
+
+// badgesFor returns the badges model earns under cfg, given the download and
+// like thresholds if they are ready. A zero threshold earns nothing, so
+// models without downloads or likes are never Popular or Trending.
+func badgesFor(model domain.HuggingFaceModel, cfg config.BadgesConfig, downloads, likes int64, ready bool, now time.Time) []string {
+	var badges []string
+	if ready && likes > 0 && int64(model.Likes) >= likes {
+		badges = append(badges, domain.BadgeTrending)
+	}
+	if ready && downloads > 0 && int64(model.Downloads) >= downloads {
+		badges = append(badges, domain.BadgePopular)
+	}
+	if cfg.NewWithinDays > 0 && model.FirstSeen != nil &&
+		now.Sub(*model.FirstSeen) <= time.Duration(cfg.NewWithinDays)*24*time.Hour {
+		badges = append(badges, domain.BadgeNew)
+	}
+	return badges
+}
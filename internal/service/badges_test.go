@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestBadgesFollowConfiguredThresholds(t *testing.T) {
+	env := newTestEnv(t)
+	now := at(0)
+	day := 24 * time.Hour
+	badged := func(id string, downloads, likes int, firstSeen time.Time) domain.HuggingFaceModel {
+		m := model(id, 0)
+		m.Downloads, m.Likes, m.FirstSeen = domain.FlexibleInt(downloads), domain.FlexibleInt(likes), &firstSeen
+		return m
+	}
+	old := now.Add(-30 * day)
+	// With 10 models, the 80th percentile is the second highest value:
+	// 900 downloads and 400 likes.
+	env.seed(t,
+		badged("a/both", 1000, 500, old),
+		badged("a/popular", 900, 1, old),
+		badged("a/trending", 1, 400, old),
+		badged("a/new", 0, 0, now.Add(-day)),
+		badged("a/new-edge", 0, 0, now.Add(-7*day)),
+		badged("a/old", 5, 5, now.Add(-8*day)),
+		badged("b/filler-0", 10, 10, old),
+		badged("b/filler-1", 10, 10, old),
+		badged("b/filler-2", 10, 10, old),
+		badged("b/filler-3", 10, 10, old),
+	)
+	svc := env.newService(
+		service.WithClock(func() time.Time { return now }),
+		service.WithBadges(config.BadgesConfig{
+			Enabled:            true,
+			PopularPercentile:  80,
+			TrendingPercentile: 80,
+			NewWithinDays:      7,
+			RefreshMinutes:     60,
+		}),
+	)
+	ids := []string{"a/both", "a/popular", "a/trending", "a/new", "a/new-edge", "a/old"}
+	badgesOf := func() map[string][]string {
+		t.Helper()
+		models, err := svc.GetModelsByIDs(context.Background(), ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string][]string, len(models))
+		for _, m := range models {
+			got[m.ID] = m.Badges
+		}
+		return got
+	}
+
+	// Until the thresholds are computed, only the New badge is given.
+	before := badgesOf()
+	for _, id := range []string{"a/both", "a/popular", "a/trending", "a/old"} {
+		if len(before[id]) != 0 {
+			t.Errorf("badges of %s before the thresholds = %v, want none", id, before[id])
+		}
+	}
+
+	svc.RefreshBadgeThresholds(context.Background())
+	want := map[string][]string{
+		"a/both":     {domain.BadgeTrending, domain.BadgePopular},
+		"a/popular":  {domain.BadgePopular},
+		"a/trending": {domain.BadgeTrending},
+		"a/new":      {domain.BadgeNew},
+		"a/new-edge": {domain.BadgeNew},
+		"a/old":      nil,
+	}
+	got := badgesOf()
+	for _, id := range ids {
+		if !slices.Equal(got[id], want[id]) {
+			t.Errorf("badges of %s = %v, want %v", id, got[id], want[id])
+		}
+	}
+}
+
+func TestBadgesAreLeftOutWhenDisabled(t *testing.T) {
+	env := newTestEnv(t)
+	m := model("a/model", 0)
+	m.Downloads, m.Likes = 1000, 1000
+	env.seed(t, m)
+	svc := env.newService()
+	svc.RefreshBadgeThresholds(context.Background())
+
+	models, err := svc.GetModelsByIDs(context.Background(), []string{"a/model"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Badges != nil {
+		t.Errorf("models = %+v, want a/model without badges", models)
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	for _, tc := range []struct {
+		total      int64
+		percentile float64
+		want       int64
+	}{
+		{1000, 99, 10},
+		{10, 80, 2},
+		{10, 99, 1},
+		{1, 50, 1},
+		{3, 1, 3},
+	} {
+		if got := service.PercentileRank(tc.total, tc.percentile); got != tc.want {
+			t.Errorf("PercentileRank(%d, %v) = %d, want %d", tc.total, tc.percentile, got, tc.want)
+		}
+	}
+}
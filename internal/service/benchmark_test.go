@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// benchmarkStorage answers every benchmark lookup with err.
+type benchmarkStorage struct {
+	service.ModelStorage
+	err error
+}
+
+func (s *benchmarkStorage) FindExtremeBy(context.Context, string, int) (*domain.HuggingFaceModel, error) {
+	return nil, s.err
+}
+
+func TestWatchCycleTellsAnEmptyDatabaseFromAFailedLookup(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		err        error
+		wantStored bool
+	}{
+		{name: "empty database", err: service.ErrNotFound, wantStored: true},
+		{name: "wrapped not found", err: errors.Join(errors.New("lookup"), service.ErrNotFound), wantStored: true},
+		{name: "failed lookup", err: errors.New("cannot decode document"), wantStored: false},
+	} {
+		env := newTestEnv(t)
+		env.store = &benchmarkStorage{ModelStorage: env.memory, err: tc.err}
+		env.hub.setPages([]domain.HuggingFaceModel{model("a/new", 2), model("a/newer", 1)})
+		svc := env.newService()
+
+		svc.RunWatchCycle(context.Background())
+
+		stored, err := env.memory.FindByID(context.Background(), "a/new")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := stored != nil; got != tc.wantStored {
+			t.Errorf("%s: stored the listed models = %v, want %v", tc.name, got, tc.wantStored)
+		}
+		if !tc.wantStored && len(env.hub.listRequests()) != 0 {
+			t.Errorf("%s: the skipped cycle still fetched %v", tc.name, env.hub.listRequests())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package service_test
+
+import (
+	"slices"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestBrokerTopicsReportSubscriberCounts(t *testing.T) {
+	env := newTestEnv(t)
+	svc := env.newService()
+	env.broker.Subscribe(service.EventModelNew)
+	env.broker.Subscribe(service.EventModelNew)
+	env.broker.Subscribe(service.EventModeChange)
+	gone := env.broker.Subscribe(service.EventModelUpdated)
+	env.broker.Unsubscribe(service.EventModelUpdated, gone)
+
+	// Topics are sorted by name; one without subscribers left is not listed.
+	want := []domain.TopicSubscribers{
+		{Topic: service.EventModelNew, Subscribers: 2},
+		{Topic: service.EventModeChange, Subscribers: 1},
+	}
+	if got := svc.GetBrokerTopics(); !slices.Equal(got, want) {
+		t.Errorf("topics = %+v, want %+v", got, want)
+	}
+}
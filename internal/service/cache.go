@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// modelCache is a small in-memory read-through cache for GetModelByID.
+type modelCache struct {
+	mu         sync.RWMutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	model    domain.HuggingFaceModel
+	storedAt time.Time
+}
+
+// newModelCache creates a cache holding at most maxEntries models for ttl.
+// A maxEntries of zero or less disables caching.
+func newModelCache(maxEntries int, ttl time.Duration) *modelCache {
+	return &modelCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// get returns the cached model for id if it is present and not expired.
+func (c *modelCache) get(id string) (*domain.HuggingFaceModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[id]
+	if !ok || time.Since(entry.storedAt) > c.ttl {
+		return nil, false
+	}
+	model := entry.model
+	return &model, true
+}
+
+// getStale returns the cached model for id even if it has expired, along with
+// its age. Expired entries stay in the cache until they are evicted or replaced.
+func (c *modelCache) getStale(id string) (*domain.HuggingFaceModel, time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, 0, false
+	}
+	model := entry.model
+	return &model, time.Since(entry.storedAt), true
+}
+
+// StaleError is returned alongside a cached model served because the
+// database could not be read. Callers may serve the model, flagged as stale.
+type StaleError struct {
+	// Age is how long ago the model was cached.
+	Age time.Duration
+	// Err is the database error that forced the fallback.
+	Err error
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("serving a cached copy from %s ago: %v", e.Age.Round(time.Second), e.Err)
+}
+
+func (e *StaleError) Unwrap() error { return e.Err }
+
+// set stores a model, evicting the oldest entry when the cache is full.
+func (c *modelCache) set(model domain.HuggingFaceModel) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[model.ID]; !exists && len(c.entries) >= c.maxEntries {
+		c.evictOldest()
+	}
+	c.entries[model.ID] = cacheEntry{model: model, storedAt: time.Now()}
+}
+
+// invalidate drops the cached copies of the given models, e.g. after they
+// were written.
+func (c *modelCache) invalidate(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.entries, id)
+	}
+}
+
+// clear drops every cached model.
+func (c *modelCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// evictOldest removes the least recently stored entry. Callers must hold the lock.
+func (c *modelCache) evictOldest() {
+	var oldestID string
+	var oldest time.Time
+	for id, entry := range c.entries {
+		if oldestID == "" || entry.storedAt.Before(oldest) {
+			oldestID, oldest = id, entry.storedAt
+		}
+	}
+	delete(c.entries, oldestID)
+}
+
+// summaryCacheTTL is how long a computed stats summary is served before it is recomputed.
+const summaryCacheTTL = 30 * time.Second
+
+// summaryCache holds the most recently computed stats summary.
+type summaryCache struct {
+	mu         sync.Mutex
+	summary    *domain.StatsSummary
+	computedAt time.Time
+}
+
+// get returns the cached summary if it is still fresh.
+func (c *summaryCache) get() (*domain.StatsSummary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.summary == nil || time.Since(c.computedAt) > summaryCacheTTL {
+		return nil, false
+	}
+	return c.summary, true
+}
+
+// set replaces the cached summary.
+func (c *summaryCache) set(summary *domain.StatsSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summary = summary
+	c.computedAt = time.Now()
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestWarmCacheLoadsMostLikedModels(t *testing.T) {
+	env := newTestEnv(t)
+	env.cache = config.CacheConfig{MaxEntries: 10, TTLSeconds: 300, WarmupCount: 2}
+	env.seed(t,
+		domain.HuggingFaceModel{ID: "a/top", Likes: 300},
+		domain.HuggingFaceModel{ID: "a/second", Likes: 200},
+		domain.HuggingFaceModel{ID: "a/third", Likes: 100},
+	)
+	counting := &countingStorage{ModelStorage: env.store}
+	env.store = counting
+	svc := env.newService()
+	ctx := context.Background()
+
+	svc.WarmCache(ctx)
+	for _, id := range []string{"a/top", "a/second"} {
+		if model, err := svc.GetModelByID(ctx, id); err != nil || model == nil {
+			t.Fatalf("GetModelByID(%s) = %v, %v", id, model, err)
+		}
+	}
+	if reads := counting.reads(); reads != 0 {
+		t.Errorf("warmed models were read from storage %d times, want 0", reads)
+	}
+
+	svc.GetModelByID(ctx, "a/third")
+	if reads := counting.reads(); reads != 1 {
+		t.Errorf("storage reads after a cold model = %d, want 1", reads)
+	}
+}
+
+func TestBulkWritesInvalidateCachedModels(t *testing.T) {
+	env := newTestEnv(t)
+	env.cache = config.CacheConfig{MaxEntries: 10, TTLSeconds: 300}
+	env.seed(t, domain.HuggingFaceModel{ID: "a/model", Likes: 1})
+	svc := env.newService()
+	ctx := context.Background()
+
+	if _, err := svc.GetModelByID(ctx, "a/model"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ImportModels(ctx, strings.NewReader(`{"id":"a/model","likes":2}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := svc.GetModelByID(ctx, "a/model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Likes != 2 {
+		t.Errorf("likes = %d after the import, want 2", model.Likes)
+	}
+}
+
+// downStorage fails every FindByID once down is set, like an unreachable database.
+type downStorage struct {
+	service.ModelStorage
+	down atomic.Bool
+}
+
+func (s *downStorage) FindByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	if s.down.Load() {
+		return nil, errors.New("server selection timeout")
+	}
+	return s.ModelStorage.FindByID(ctx, id)
+}
+
+func TestExpiredCacheIsServedStaleWhileDatabaseIsDown(t *testing.T) {
+	for _, serveStale := range []bool{true, false} {
+		env := newTestEnv(t)
+		// A zero TTL expires every entry at once, so only the stale path can serve it.
+		env.cache = config.CacheConfig{MaxEntries: 10, TTLSeconds: 0, ServeStale: serveStale}
+		env.seed(t, domain.HuggingFaceModel{ID: "a/model", Likes: 7})
+		down := &downStorage{ModelStorage: env.store}
+		env.store = down
+		svc := env.newService()
+		ctx := context.Background()
+
+		if _, err := svc.GetModelByID(ctx, "a/model"); err != nil {
+			t.Fatal(err)
+		}
+		down.down.Store(true)
+		model, err := svc.GetModelByID(ctx, "a/model")
+
+		var stale *service.StaleError
+		if !serveStale {
+			if err == nil || errors.As(err, &stale) || model != nil {
+				t.Errorf("ServeStale off: got %v, %v, want the database error", model, err)
+			}
+			continue
+		}
+		if !errors.As(err, &stale) {
+			t.Fatalf("ServeStale on: err = %v, want a StaleError", err)
+		}
+		if model == nil || model.Likes != 7 {
+			t.Errorf("ServeStale on: model = %v, want the cached copy", model)
+		}
+	}
+}
+
+func TestStaleCacheDoesNotHideUncachedModels(t *testing.T) {
+	env := newTestEnv(t)
+	env.cache = config.CacheConfig{MaxEntries: 10, TTLSeconds: 300, ServeStale: true}
+	down := &downStorage{ModelStorage: env.store}
+	down.down.Store(true)
+	env.store = down
+	svc := env.newService()
+
+	model, err := svc.GetModelByID(context.Background(), "a/never-cached")
+	var stale *service.StaleError
+	if err == nil || errors.As(err, &stale) || model != nil {
+		t.Errorf("got %v, %v, want the database error", model, err)
+	}
+}
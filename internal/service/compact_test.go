@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/scraper"
+)
+
+// fullModel returns a model with the fields compact mode drops.
+func fullModel(id string) domain.HuggingFaceModel {
+	m := model(id, 0)
+	m.SHA = "abc123"
+	m.Siblings = []domain.Sibling{{Rfilename: "config.json"}}
+	return m
+}
+
+func TestCompactModeStoresSubsetAndFetchesFullRecordOnDemand(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.CompactDocuments = true
+	env.scraper.OnDemandRequestsPerSecond, env.scraper.OnDemandBurstLimit = 1, 1
+	env.hub.setModels(fullModel("a/one"), fullModel("a/two"))
+	svc := env.newService()
+	ctx := context.Background()
+
+	imported := `{"id":"a/one","sha":"abc123","siblings":[{"rfilename":"config.json"}]}
+{"id":"a/two","sha":"abc123","siblings":[{"rfilename":"config.json"}]}`
+	if _, err := svc.ImportModels(ctx, strings.NewReader(imported)); err != nil {
+		t.Fatal(err)
+	}
+	if stored := env.stored(t, "a/one"); stored.SHA != "" || stored.Siblings != nil {
+		t.Fatalf("stored document keeps sha %q and siblings %v", stored.SHA, stored.Siblings)
+	}
+
+	one, err := svc.GetModelByID(ctx, "a/one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(one.Siblings) != 1 {
+		t.Errorf("a/one siblings = %v, want the full record from the Hub", one.Siblings)
+	}
+
+	// The on-demand budget of one request is spent, so the stored subset is
+	// served without touching the Hub.
+	two, err := svc.GetModelByID(ctx, "a/two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if two.Siblings != nil {
+		t.Errorf("a/two siblings = %v, want the stored subset", two.Siblings)
+	}
+	if requests := env.hub.requestCount("/api/models/"); requests != 1 {
+		t.Errorf("detail requests = %d, want 1", requests)
+	}
+}
+
+func TestOnDemandFetchFailuresDoNotTripTheBreaker(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.CompactDocuments = true
+	env.scraper.OnDemandRequestsPerSecond, env.scraper.OnDemandBurstLimit = 100, 100
+	env.scraper.BreakerThreshold, env.scraper.BreakerCooldownSeconds = 1, 60
+	env.hub.failWith(func(*http.Request) int { return http.StatusInternalServerError })
+	env.seed(t, model("a/one", 0))
+	svc := env.newService()
+	ctx := context.Background()
+
+	for range 3 {
+		if m, err := svc.GetModelByID(ctx, "a/one"); err != nil || m == nil {
+			t.Fatalf("GetModelByID = %v, %v, want the stored document", m, err)
+		}
+	}
+	status, err := svc.GetStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ScraperBreaker != string(scraper.BreakerClosed) {
+		t.Errorf("breaker is %s after failed on-demand fetches, want closed", status.ScraperBreaker)
+	}
+}
@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// failingWatchStatus fails the switch to watch mode at the end of a backfill.
+type failingWatchStatus struct {
+	service.StatusStorage
+}
+
+func (s failingWatchStatus) UpdateStatus(ctx context.Context, status domain.ServiceStatus) error {
+	if status == domain.StatusWatching {
+		return errors.New("status storage unavailable")
+	}
+	return s.StatusStorage.UpdateStatus(ctx, status)
+}
+
+// waitStored waits until every model in ids is stored.
+func waitStored(t *testing.T, env *testEnv, ids ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for _, id := range ids {
+		for {
+			if model, _ := env.memory.FindByID(context.Background(), id); model != nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s was never stored", id)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestConcurrentBackfillRunsBothLoops(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/newest", 2)},
+		[]domain.HuggingFaceModel{model("a/middle", 1)},
+		[]domain.HuggingFaceModel{model("a/oldest", 0)},
+	)
+	// The watcher only reads the first page and the backfill starts at the
+	// last, so each model is written by exactly one of the loops.
+	env.watcher.MaxPagesPerCycle = 1
+	env.watcher.ConcurrentBackfill = true
+	env.watcher.BackfillStartURL = env.hub.server.URL + "/api/models?page=2"
+	svc := env.newService()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(ctx) }()
+
+	waitStored(t, env, "a/newest", "a/oldest")
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the service did not stop")
+	}
+	if model, _ := env.memory.FindByID(context.Background(), "a/middle"); model != nil {
+		t.Error("a/middle was stored, but neither loop should have reached it")
+	}
+}
+
+func TestConcurrentBackfillErrorStopsTheWatcher(t *testing.T) {
+	env := newTestEnv(t)
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/one", 1)})
+	env.status = failingWatchStatus{env.status}
+	env.watcher.ConcurrentBackfill = true
+	svc := env.newService()
+
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Start succeeded, want the backfill's error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start waited for the watch loop after the backfill failed")
+	}
+}
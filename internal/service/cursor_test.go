@@ -0,0 +1,50 @@
+package service
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCursorAdvancesOnlyThroughContiguousPages(t *testing.T) {
+	var saved []string
+	c := newCursorCoordinator(func(cursor string) error {
+		saved = append(saved, cursor)
+		return nil
+	})
+
+	for _, step := range []struct {
+		seq  int
+		want []string
+	}{
+		{seq: 2, want: nil},
+		{seq: 1, want: nil},
+		{seq: 0, want: []string{"after-2"}},
+		{seq: 4, want: []string{"after-2"}},
+		{seq: 3, want: []string{"after-2", "after-4"}},
+	} {
+		c.complete(step.seq, "after-"+strconv.Itoa(step.seq))
+		if !slices.Equal(saved, step.want) {
+			t.Fatalf("after page %d completed: saved %v, want %v", step.seq, saved, step.want)
+		}
+	}
+}
+
+func TestCursorRestartIgnoresOlderCompletions(t *testing.T) {
+	var saved []string
+	c := newCursorCoordinator(func(cursor string) error {
+		saved = append(saved, cursor)
+		return nil
+	})
+
+	c.complete(1, "after-1")
+	if err := c.restart(3, "start"); err != nil {
+		t.Fatal(err)
+	}
+	c.complete(0, "after-0")
+	c.complete(3, "after-3")
+
+	if want := []string{"start", "after-3"}; !slices.Equal(saved, want) {
+		t.Errorf("saved %v, want %v", saved, want)
+	}
+}
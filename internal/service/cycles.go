@@ -0,0 +1,45 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// cycleTimes keeps the start times of the most recent watch cycles in a ring
+// buffer, to report the interval actually achieved between them.
+type cycleTimes struct {
+	mu     sync.Mutex
+	starts []time.Time
+	// next is the slot the next start is written to; count is how many are filled.
+	next  int
+	count int
+}
+
+// newCycleTimes returns a ring remembering the last size starts, at least two.
+func newCycleTimes(size int) *cycleTimes {
+	return &cycleTimes{starts: make([]time.Time, max(size, 2))}
+}
+
+// record adds the start time of a cycle, evicting the oldest if full.
+func (c *cycleTimes) record(start time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.starts[c.next] = start
+	c.next = (c.next + 1) % len(c.starts)
+	c.count = min(c.count+1, len(c.starts))
+}
+
+// intervals returns the time between the last two starts and the average
+// time between all remembered starts. Both are zero until two cycles ran.
+func (c *cycleTimes) intervals() (last, average time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.count < 2 {
+		return 0, 0
+	}
+	at := func(back int) time.Time {
+		return c.starts[(c.next-back+len(c.starts))%len(c.starts)]
+	}
+	newest, oldest := at(1), at(c.count)
+	return newest.Sub(at(2)), newest.Sub(oldest) / time.Duration(c.count-1)
+}
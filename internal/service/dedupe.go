@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+)
+
+// dropUnchanged removes the models whose stored copy already has the same
+// content, so a lastModified bump on the Hub doesn't cause an identical write.
+// Once the timestamp has moved by the dedupe window or more the model is
+// written anyway. The model with the newest benchmark timestamp is always
+// written, so the stored benchmark moves up to everything fetched and the
+// next cycle doesn't fetch the same unchanged models again.
+// If the stored copies cannot be read, every model is kept.
+func (s *Service) dropUnchanged(ctx context.Context, models []domain.HuggingFaceModel) []domain.HuggingFaceModel {
+	window := time.Duration(s.cfg.DedupeWindowMinutes) * time.Minute
+	if window <= 0 || len(models) == 0 {
+		return models
+	}
+
+	ids := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = model.ID
+	}
+	stored, err := s.modelStorage.FindByIDs(ctx, ids)
+	if err != nil {
+		log.Printf("Watch Cycle: could not load stored models for dedupe, writing all: %v", err)
+		return models
+	}
+	storedByID := make(map[string]domain.HuggingFaceModel, len(stored))
+	for _, model := range stored {
+		storedByID[model.ID] = model
+	}
+
+	benchmark := s.benchmarkField()
+	newest := 0
+	for i, model := range models {
+		if model.TimestampOf(benchmark).After(models[newest].TimestampOf(benchmark)) {
+			newest = i
+		}
+	}
+
+	changed := models[:0]
+	for i, model := range models {
+		previous, ok := storedByID[model.ID]
+		if i != newest && ok && model.LastModified.Sub(previous.LastModified) < window && sameContent(model, previous) {
+			continue
+		}
+		changed = append(changed, model)
+	}
+
+	if skipped := len(models) - len(changed); skipped > 0 {
+		s.skippedUnchanged.Add(int64(skipped))
+		s.metrics.AddCounter(metrics.ModelsSkipped, float64(skipped))
+		log.Printf("Watch Cycle: Skipped %d models with unchanged content.", skipped)
+	}
+	return changed
+}
+
+// sameContent reports whether two versions of a model differ only in fields
+// that carry no meaningful change, i.e. the timestamps. A soft-deleted copy
+// differs from a live one, so a model back on the Hub is written again.
+func sameContent(a, b domain.HuggingFaceModel) bool {
+	return (a.DeletedAt == nil) == (b.DeletedAt == nil) &&
+		a.SHA == b.SHA &&
+		a.Author == b.Author &&
+		a.Private == b.Private &&
+		a.Gated == b.Gated &&
+		a.Likes == b.Likes &&
+		a.Downloads == b.Downloads &&
+		a.PipelineTag == b.PipelineTag &&
+		slices.Equal(a.Tags, b.Tags) &&
+		slices.Equal(a.Siblings, b.Siblings)
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+)
+
+func TestTimestampOnlyChangesAdvanceTheBenchmark(t *testing.T) {
+	env := newTestEnv(t)
+	recorder := metricstest.NewRecorder()
+	env.metrics = recorder
+	env.watcher.DedupeWindowMinutes = 60
+	env.seed(t, model("a/newest", 0), model("a/older", -1))
+	// Both models only moved their timestamps on the Hub.
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/newest", 5), model("a/older", 4)})
+	svc := env.newService()
+	ctx := context.Background()
+
+	svc.RunWatchCycle(ctx)
+	if got := env.stored(t, "a/newest").LastModified; !got.Equal(at(5)) {
+		t.Errorf("the newest model's lastModified = %s, want it advanced to %s", got, at(5))
+	}
+	if got := env.stored(t, "a/older").LastModified; !got.Equal(at(-1)) {
+		t.Errorf("the older unchanged model was rewritten with lastModified %s", got)
+	}
+	if skipped := recorder.Counter(metrics.ModelsSkipped); skipped != 1 {
+		t.Errorf("skipped %v unchanged models, want 1", skipped)
+	}
+
+	svc.RunWatchCycle(ctx)
+	if skipped := recorder.Counter(metrics.ModelsSkipped); skipped != 1 {
+		t.Errorf("the next cycle fetched the unchanged models again (%v skipped in total)", skipped)
+	}
+	if upserted := recorder.Counter(metrics.ModelsUpserted); upserted != 1 {
+		t.Errorf("upserted %v models over both cycles, want 1", upserted)
+	}
+}
+
+func TestDeletedModelsBackOnTheHubAreRewritten(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.DedupeWindowMinutes = 60
+	env.seed(t, model("a/newest", 0), model("a/back", -1))
+	ctx := context.Background()
+	if err := env.memory.MarkDeleted(ctx, "a/back", at(-1)); err != nil {
+		t.Fatal(err)
+	}
+	// a/back reappears with the same content, within the dedupe window.
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/newest", 5), model("a/back", 4)})
+	svc := env.newService()
+
+	svc.RunWatchCycle(ctx)
+	if back := env.stored(t, "a/back"); back.DeletedAt != nil {
+		t.Errorf("a/back is still marked deleted at %s after coming back", back.DeletedAt)
+	}
+}
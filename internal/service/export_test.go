@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"hf-scraper/internal/scraper"
+)
+
+// Entry points into unexported steps of the service for the external tests.
+
+func (s *Service) ReconcileBatch(ctx context.Context, afterID string) string {
+	return s.reconcileBatch(ctx, afterID)
+}
+
+func (s *Service) RunWatchCycle(ctx context.Context) {
+	s.runWatchCycle(ctx)
+}
+
+func (s *Service) RunBackfill(ctx context.Context, initialCursor string) error {
+	return s.runBackfill(ctx, initialCursor)
+}
+
+func (s *Service) PurgeDeleted(ctx context.Context, cutoff time.Time) {
+	s.purgeDeleted(ctx, cutoff)
+}
+
+func (s *Service) FetchModels(ctx context.Context, url string) (*scraper.ScrapeResult, error) {
+	return s.fetchModels(ctx, url)
+}
+
+func (s *Service) RefreshTopDownloads(ctx context.Context) {
+	s.refreshTopDownloads(ctx)
+}
+
+func (s *Service) RefreshBadgeThresholds(ctx context.Context) {
+	s.refreshBadgeThresholds(ctx)
+}
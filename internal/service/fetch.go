@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"hf-scraper/internal/scraper"
+)
+
+// fetchModels fetches one page of models through the scraper. Concurrent
+// fetches of the same URL, e.g. by the backfill and the watcher, share a
+// single request; fetches of different URLs proceed independently. Each
+// caller gets its own copy of the models, as ingest modifies them in place.
+//
+// The shared request runs without the callers' cancellation, so one caller
+// giving up doesn't fail the others; a cancelled caller returns right away.
+func (s *Service) fetchModels(ctx context.Context, url string) (*scraper.ScrapeResult, error) {
+	results := s.fetches.DoChan(url, func() (any, error) {
+		return s.scraper.FetchModels(context.WithoutCancel(ctx), url)
+	})
+	select {
+	case res := <-results:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		result := *res.Val.(*scraper.ScrapeResult)
+		if res.Shared {
+			result.Models = slices.Clone(result.Models)
+		}
+		return &result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
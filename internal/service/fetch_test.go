@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/scraper"
+)
+
+func TestConcurrentFetchesOfOneURLShareARequest(t *testing.T) {
+	var requests atomic.Int32
+	arrived := make(chan struct{}, 10)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		arrived <- struct{}{}
+		<-release
+		w.Write([]byte(`[{"id":"a/one"},{"id":"a/two"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	env := newTestEnv(t)
+	env.scraper.BaseURL = server.URL
+	svc := env.newService()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]*scraper.ScrapeResult, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := svc.FetchModels(context.Background(), server.URL+"/api/models?page=1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = result
+		}()
+	}
+	<-arrived
+	// Give the other callers time to join the request in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("%d concurrent fetches of one URL made %d requests, want 1", callers, got)
+	}
+	for i, result := range results {
+		if result == nil || len(result.Models) != 2 {
+			t.Fatalf("caller %d got %+v, want both models", i, result)
+		}
+	}
+	results[0].Models[0].ID = "changed"
+	for i, result := range results[1:] {
+		if result.Models[0].ID != "a/one" {
+			t.Errorf("caller %d sees another caller's change to its models", i+1)
+		}
+	}
+
+	// Different URLs are fetched independently.
+	for _, url := range []string{"/api/models?page=2", "/api/models?page=3"} {
+		if _, err := svc.FetchModels(context.Background(), server.URL+url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("after fetching two other URLs, made %d requests, want 3", got)
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"hf-scraper/internal/domain"
+)
+
+// PageHash computes a stable hash of a page of search results. Because search
+// ordering is deterministic, clients can compare hashes to detect changes.
+func PageHash(models []domain.HuggingFaceModel) string {
+	hasher := sha256.New()
+	encoder := json.NewEncoder(hasher)
+	for _, model := range models {
+		// Encoding a struct is deterministic: fields are written in declaration order.
+		encoder.Encode(model)
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
@@ -0,0 +1,38 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestSamePageHashesTheSameOverUnchangedData(t *testing.T) {
+	env := newTestEnv(t)
+	var models []domain.HuggingFaceModel
+	for _, id := range []string{"a/d", "a/b", "a/e", "a/a", "a/c"} {
+		models = append(models, domain.HuggingFaceModel{ID: id, Likes: 7})
+	}
+	env.seed(t, models...)
+	svc := env.newService()
+	opts := service.SearchOptions{SortBy: "likes", SortOrder: -1, Limit: 3, Page: 1}
+
+	page := func() []domain.HuggingFaceModel {
+		t.Helper()
+		models, _, err := svc.SearchModels(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return models
+	}
+	first, second := page(), page()
+	if service.PageHash(first) != service.PageHash(second) {
+		t.Errorf("the same query hashed differently: %v then %v", first, second)
+	}
+
+	opts.Page = 2
+	if service.PageHash(page()) == service.PageHash(first) {
+		t.Error("different pages hashed the same")
+	}
+}
@@ -0,0 +1,243 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/events"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/scraper"
+	"hf-scraper/internal/service"
+	"hf-scraper/internal/storage"
+)
+
+// fakeHub serves the parts of the Hugging Face API the service uses from
+// in-memory data: a listing split into pages, linked with "page" query
+// parameters, and the detail record of each model.
+type fakeHub struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	pages    [][]domain.HuggingFaceModel
+	models   map[string]domain.HuggingFaceModel
+	fail     func(r *http.Request) int
+	requests []string
+	// bareLinks makes next links carry only the page parameter, dropping the
+	// rest of the request's query.
+	bareLinks bool
+}
+
+func newFakeHub(t *testing.T) *fakeHub {
+	t.Helper()
+	hub := &fakeHub{models: make(map[string]domain.HuggingFaceModel)}
+	hub.server = httptest.NewServer(hub)
+	t.Cleanup(hub.server.Close)
+	return hub
+}
+
+// setPages replaces the listing. The first page is served without a page
+// parameter; each links to the next.
+func (h *fakeHub) setPages(pages ...[]domain.HuggingFaceModel) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pages = pages
+}
+
+// setModels adds detail records.
+func (h *fakeHub) setModels(models ...domain.HuggingFaceModel) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, model := range models {
+		h.models[model.ID] = model
+	}
+}
+
+// failWith makes the hub answer requests with the status fail returns for
+// them, unless it returns 0.
+func (h *fakeHub) failWith(fail func(r *http.Request) int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fail = fail
+}
+
+// requestCount returns how many requests had a URI starting with prefix.
+func (h *fakeHub) requestCount(prefix string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := 0
+	for _, uri := range h.requests {
+		if strings.HasPrefix(uri, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func (h *fakeHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r.URL.RequestURI())
+	if h.fail != nil {
+		if status := h.fail(r); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	if id, ok := strings.CutPrefix(r.URL.Path, "/api/models/"); ok {
+		model, found := h.models[id]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(model)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page >= len(h.pages) {
+		w.Write([]byte("[]"))
+		return
+	}
+	if page+1 < len(h.pages) {
+		next := *r.URL
+		query := next.Query()
+		if h.bareLinks {
+			query = url.Values{}
+		}
+		query.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="next"`, h.server.URL, next.RequestURI()))
+	}
+	json.NewEncoder(w).Encode(h.pages[page])
+}
+
+// testEnv wires a service to a fake hub and the memory storage. Tests adjust
+// the configuration, or wrap store and status, before calling newService.
+type testEnv struct {
+	hub     *fakeHub
+	memory  *storage.MemoryModelStorage
+	store   service.ModelStorage
+	status  service.StatusStorage
+	broker  *events.Broker
+	metrics metrics.Metrics
+	// client is the scraper of the last service created. Copies of a
+	// Scraper share its limiter, so it reports the service's limits.
+	client *scraper.Scraper
+
+	watcher config.WatcherConfig
+	scraper config.ScraperConfig
+	cache   config.CacheConfig
+	ingest  config.IngestConfig
+	db      config.DatabaseConfig
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	hub := newFakeHub(t)
+	memory := storage.NewMemoryModelStorage()
+	broker := events.NewBroker(metrics.Noop{})
+	t.Cleanup(broker.Close)
+	return &testEnv{
+		hub:     hub,
+		memory:  memory,
+		store:   memory,
+		status:  storage.NewMemoryStatusStorage(),
+		broker:  broker,
+		metrics: metrics.Noop{},
+		watcher: config.WatcherConfig{
+			IntervalMinutes:  60,
+			BackfillWriters:  1,
+			CycleHistorySize: 10,
+			MaxPagesPerCycle: 10,
+		},
+		scraper: config.ScraperConfig{
+			BaseURL:           hub.server.URL,
+			RequestsPerSecond: 1000,
+			BurstLimit:        1000,
+		},
+		ingest: config.IngestConfig{ImportBatchSize: 100, ImportWriters: 1},
+	}
+}
+
+// newService creates the service from the current configuration.
+func (e *testEnv) newService(opts ...service.Option) *service.Service {
+	e.client = scraper.NewScraper(e.scraper, e.metrics)
+	return service.NewService(e.watcher, e.scraper, *e.client, e.store, e.status, e.broker, e.cache, e.ingest, e.db, e.metrics, opts...)
+}
+
+// seed stores models directly, bypassing the service.
+func (e *testEnv) seed(t *testing.T, models ...domain.HuggingFaceModel) {
+	t.Helper()
+	if err := e.memory.BulkUpsert(context.Background(), models); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stored returns the stored copy of a model, failing the test if it is missing.
+func (e *testEnv) stored(t *testing.T, id string) domain.HuggingFaceModel {
+	t.Helper()
+	model, err := e.memory.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model == nil {
+		t.Fatalf("model %s is not stored", id)
+	}
+	return *model
+}
+
+// at returns a fixed time offset by minutes, for readable model timestamps.
+func at(minutes int) time.Time {
+	return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(minutes) * time.Minute)
+}
+
+// model returns a model with the given ID, last modified at(minutes).
+func model(id string, minutes int) domain.HuggingFaceModel {
+	author, _, _ := strings.Cut(id, "/")
+	return domain.HuggingFaceModel{ID: id, Author: author, Gated: domain.GatedStatusFalse, LastModified: at(minutes), CreatedAt: at(minutes)}
+}
+
+// listRequests returns the URIs of the listing requests the hub received.
+func (h *fakeHub) listRequests() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var uris []string
+	for _, uri := range h.requests {
+		if strings.HasPrefix(uri, "/api/models?") {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// countingStorage counts the FindByID calls reaching the wrapped storage.
+type countingStorage struct {
+	service.ModelStorage
+	mu        sync.Mutex
+	findByIDs int
+}
+
+func (s *countingStorage) FindByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	s.mu.Lock()
+	s.findByIDs++
+	s.mu.Unlock()
+	return s.ModelStorage.FindByID(ctx, id)
+}
+
+func (s *countingStorage) reads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.findByIDs
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// snapshotTolerance is how far from a requested time the snapshot used for a
+// diff may have been scraped before the diff carries a note about it.
+const snapshotTolerance = time.Hour
+
+// recordHistory saves a snapshot of the counters of freshly stored models
+// when WATCHER.RECORD_HISTORY is set. Failures are logged rather than
+// returned, as the models themselves are already stored.
+func (s *Service) recordHistory(ctx context.Context, models []domain.HuggingFaceModel) {
+	if !s.cfg.RecordHistory || len(models) == 0 {
+		return
+	}
+	scrapedAt := s.now()
+	snapshots := make([]domain.ModelSnapshot, len(models))
+	for i, model := range models {
+		snapshots[i] = domain.ModelSnapshot{
+			ModelID:   model.ID,
+			ScrapedAt: scrapedAt,
+			Likes:     int(model.Likes),
+			Downloads: int(model.Downloads),
+		}
+	}
+	if err := s.modelStorage.RecordSnapshots(ctx, snapshots); err != nil {
+		log.Printf("Warning: failed to record %d model snapshots: %v", len(snapshots), err)
+	}
+}
+
+// GetModelDiff compares the snapshots of a model scraped closest to from and
+// to. A zero to means now. When the closest snapshot is more than
+// snapshotTolerance away from the requested time it is used anyway, with a
+// note. It returns nil if the model has no history.
+func (s *Service) GetModelDiff(ctx context.Context, id string, from, to time.Time) (*domain.ModelDiff, error) {
+	if to.IsZero() {
+		to = s.now()
+	}
+	fromSnapshot, err := s.modelStorage.NearestSnapshot(ctx, id, from)
+	if err != nil || fromSnapshot == nil {
+		return nil, err
+	}
+	toSnapshot, err := s.modelStorage.NearestSnapshot(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &domain.ModelDiff{
+		ID:             id,
+		From:           *fromSnapshot,
+		To:             *toSnapshot,
+		LikesDelta:     toSnapshot.Likes - fromSnapshot.Likes,
+		DownloadsDelta: toSnapshot.Downloads - fromSnapshot.Downloads,
+	}
+	for _, end := range []struct {
+		name     string
+		at       time.Time
+		snapshot *domain.ModelSnapshot
+	}{{"from", from, fromSnapshot}, {"to", to, toSnapshot}} {
+		if end.snapshot.ScrapedAt.Sub(end.at).Abs() > snapshotTolerance {
+			diff.Notes = append(diff.Notes, fmt.Sprintf("no snapshot near %s=%s, using the closest one, scraped at %s",
+				end.name, end.at.Format(time.RFC3339), end.snapshot.ScrapedAt.Format(time.RFC3339)))
+		}
+	}
+	return diff, nil
+}
+
+// TrendFields are the snapshot counters GetTrend can sum.
+var TrendFields = []string{"downloads", "likes"}
+
+// TrendIntervals are the bucket sizes GetTrend can group snapshots by.
+var TrendIntervals = []string{"hour", "day", "week", "month"}
+
+// ErrInvalidTrend is returned by GetTrend and GetDiscovery for a field or
+// interval outside TrendFields or TrendIntervals.
+var ErrInvalidTrend = errors.New("invalid trend parameters")
+
+// GetTrend returns the sum of a counter over all models with recorded history
+// per time bucket, oldest first. Within a bucket, the last snapshot of each
+// model counts, so models scraped several times are not counted twice.
+func (s *Service) GetTrend(ctx context.Context, field, interval string) ([]domain.TrendPoint, error) {
+	if !slices.Contains(TrendFields, field) {
+		return nil, fmt.Errorf("%w: field must be one of %s, got %q", ErrInvalidTrend, strings.Join(TrendFields, ", "), field)
+	}
+	if err := validateInterval(interval); err != nil {
+		return nil, err
+	}
+	return s.modelStorage.SnapshotTrend(ctx, field, interval)
+}
+
+// GetDiscovery returns the number of models first stored per time bucket,
+// oldest first, to chart how fast new models are discovered. Models stored
+// before first-seen times were recorded are not counted.
+func (s *Service) GetDiscovery(ctx context.Context, interval string) ([]domain.TimeCount, error) {
+	if err := validateInterval(interval); err != nil {
+		return nil, err
+	}
+	return s.modelStorage.CountByFirstSeen(ctx, interval)
+}
+
+// validateInterval rejects bucket sizes outside TrendIntervals.
+func validateInterval(interval string) error {
+	if !slices.Contains(TrendIntervals, interval) {
+		return fmt.Errorf("%w: interval must be one of %s, got %q", ErrInvalidTrend, strings.Join(TrendIntervals, ", "), interval)
+	}
+	return nil
+}
+
+// TruncateToInterval returns the start of the trend bucket holding t, in UTC.
+// Weeks start on Monday.
+func TruncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	year, month, day := t.Date()
+	switch interval {
+	case "hour":
+		return t.Truncate(time.Hour)
+	case "week":
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
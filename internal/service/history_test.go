@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestModelDiffUsesNearestSnapshots(t *testing.T) {
+	env := newTestEnv(t)
+	snapshot := func(minutes, likes, downloads int) domain.ModelSnapshot {
+		return domain.ModelSnapshot{ModelID: "a/m", ScrapedAt: at(minutes), Likes: likes, Downloads: downloads}
+	}
+	if err := env.memory.RecordSnapshots(context.Background(), []domain.ModelSnapshot{
+		snapshot(0, 10, 100),
+		snapshot(60, 15, 180),
+		snapshot(120, 17, 250),
+		snapshot(600, 40, 900),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svc := env.newService()
+
+	for _, tc := range []struct {
+		name                     string
+		from, to                 time.Time
+		wantLikes, wantDownloads int
+		wantFrom, wantTo         time.Time
+		wantNotes                []string
+	}{
+		{name: "exact", from: at(0), to: at(120), wantLikes: 7, wantDownloads: 150, wantFrom: at(0), wantTo: at(120)},
+		{name: "nearest", from: at(50), to: at(100), wantLikes: 2, wantDownloads: 70, wantFrom: at(60), wantTo: at(120)},
+		{name: "far from a snapshot", from: at(10), to: at(400), wantLikes: 30, wantDownloads: 800, wantFrom: at(0), wantTo: at(600),
+			wantNotes: []string{"to="}},
+	} {
+		diff, err := svc.GetModelDiff(context.Background(), "a/m", tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if diff.LikesDelta != tc.wantLikes || diff.DownloadsDelta != tc.wantDownloads {
+			t.Errorf("%s: deltas = %d likes, %d downloads, want %d, %d", tc.name, diff.LikesDelta, diff.DownloadsDelta, tc.wantLikes, tc.wantDownloads)
+		}
+		if !diff.From.ScrapedAt.Equal(tc.wantFrom) || !diff.To.ScrapedAt.Equal(tc.wantTo) {
+			t.Errorf("%s: compared %s to %s, want %s to %s", tc.name, diff.From.ScrapedAt, diff.To.ScrapedAt, tc.wantFrom, tc.wantTo)
+		}
+		if len(diff.Notes) != len(tc.wantNotes) {
+			t.Errorf("%s: notes = %q, want %d", tc.name, diff.Notes, len(tc.wantNotes))
+			continue
+		}
+		for i, note := range tc.wantNotes {
+			if !strings.Contains(diff.Notes[i], note) {
+				t.Errorf("%s: note %q does not mention %s", tc.name, diff.Notes[i], note)
+			}
+		}
+	}
+
+	diff, err := svc.GetModelDiff(context.Background(), "a/unknown", at(0), at(60))
+	if err != nil || diff != nil {
+		t.Errorf("a model without history: diff = %v, err = %v, want nil, nil", diff, err)
+	}
+}
+
+func TestDiscoveryRejectsUnknownIntervals(t *testing.T) {
+	svc := newTestEnv(t).newService()
+	if _, err := svc.GetDiscovery(context.Background(), "year"); !errors.Is(err, service.ErrInvalidTrend) {
+		t.Errorf("interval year: err = %v, want ErrInvalidTrend", err)
+	}
+	if _, err := svc.GetDiscovery(context.Background(), "week"); err != nil {
+		t.Errorf("interval week: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"hf-scraper/internal/domain"
+)
+
+// ErrMalformedImport is returned by ImportModels for a record that is not a
+// valid JSON model.
+var ErrMalformedImport = errors.New("malformed import record")
+
+// importFailure is a batch an import could not write.
+type importFailure struct {
+	batch      int
+	lastRecord int
+	err        error
+}
+
+// ImportModels restores models from a JSON Lines stream, one model per line,
+// upserting them in batches of INGEST.IMPORT_BATCH_SIZE. The final batch is
+// flushed even when it is partial. Up to INGEST.IMPORT_WRITERS batches are
+// written at the same time; upserts are idempotent, so their order does not
+// matter. Once a batch fails or a record is malformed, no further batches are
+// started, and the ones in flight are waited for. It returns the number of
+// models written, which on error counts every batch that was stored, and the
+// failures of each batch joined in batch order. A failed write takes
+// precedence over a malformed record: the error only wraps ErrMalformedImport
+// when every batch was stored.
+func (s *Service) ImportModels(ctx context.Context, r io.Reader) (int, error) {
+	batchSize := max(s.ingestCfg.ImportBatchSize, 1)
+	decoder := json.NewDecoder(r)
+	batch := make([]domain.HuggingFaceModel, 0, batchSize)
+
+	var (
+		mu       sync.Mutex
+		imported int
+		failures []importFailure
+		writers  sync.WaitGroup
+	)
+	// slots holds one token per batch being written.
+	slots := make(chan struct{}, max(s.ingestCfg.ImportWriters, 1))
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(failures) > 0
+	}
+	fail := func(f importFailure) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, f)
+	}
+
+	batches := 0
+	flush := func(lastRecord int) {
+		models := s.dropMissingIDs(batch, "Import")
+		// The writer keeps the flushed batch, so the next one gets a new array.
+		batch = make([]domain.HuggingFaceModel, 0, batchSize)
+		if len(models) == 0 {
+			return
+		}
+		batches++
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			fail(importFailure{batch: batches, lastRecord: lastRecord, err: ctx.Err()})
+			return
+		}
+		if failed() {
+			<-slots
+			return
+		}
+		writers.Add(1)
+		go func(n int) {
+			defer writers.Done()
+			defer func() { <-slots }()
+			if err := s.bulkUpsert(ctx, s.prepareModels(models)); err != nil {
+				var partial *PartialWriteError
+				if errors.As(err, &partial) {
+					mu.Lock()
+					imported += len(models) - len(partial.Failed)
+					mu.Unlock()
+				}
+				fail(importFailure{batch: n, lastRecord: lastRecord, err: err})
+				return
+			}
+			mu.Lock()
+			imported += len(models)
+			mu.Unlock()
+		}(batches)
+	}
+
+	var decodeErr error
+	record := 1
+	malformed := 0
+	for ; !failed(); record++ {
+		var model domain.HuggingFaceModel
+		if err := decoder.Decode(&model); err == io.EOF {
+			flush(record - 1)
+			break
+		} else if err != nil {
+			decodeErr, malformed = err, record
+			break
+		}
+		batch = append(batch, model)
+		if len(batch) == batchSize {
+			flush(record)
+		}
+	}
+	writers.Wait()
+
+	slices.SortFunc(failures, func(a, b importFailure) int { return cmp.Compare(a.batch, b.batch) })
+	errs := make([]error, 0, len(failures)+1)
+	for _, f := range failures {
+		errs = append(errs, fmt.Errorf("import batch %d (up to record %d): %w", f.batch, f.lastRecord, f.err))
+	}
+	switch {
+	case decodeErr == nil:
+	case len(failures) > 0:
+		errs = append(errs, fmt.Errorf("record %d is also malformed: %v", malformed, decodeErr))
+	default:
+		errs = append(errs, fmt.Errorf("%w %d: %v", ErrMalformedImport, malformed, decodeErr))
+	}
+	return imported, errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// batchStorage records the size of every bulk write.
+type batchStorage struct {
+	service.ModelStorage
+	mu    sync.Mutex
+	sizes []int
+}
+
+func (s *batchStorage) BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
+	s.mu.Lock()
+	s.sizes = append(s.sizes, len(models))
+	s.mu.Unlock()
+	return s.ModelStorage.BulkUpsert(ctx, models)
+}
+
+// jsonLines returns an import stream of n models named a/model-0, a/model-1, ...
+func jsonLines(n int) string {
+	var lines strings.Builder
+	for i := range n {
+		fmt.Fprintf(&lines, "{\"id\":\"a/model-%d\"}\n", i)
+	}
+	return lines.String()
+}
+
+func TestImportFlushesPartialFinalBatch(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.ImportBatchSize = 3
+	batches := &batchStorage{ModelStorage: env.store}
+	env.store = batches
+	svc := env.newService()
+
+	imported, err := svc.ImportModels(context.Background(), strings.NewReader(jsonLines(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 7 {
+		t.Errorf("imported %d models, want 7", imported)
+	}
+	if want := []int{3, 3, 1}; !slices.Equal(batches.sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", batches.sizes, want)
+	}
+	for i := range 7 {
+		env.stored(t, fmt.Sprintf("a/model-%d", i))
+	}
+}
+
+func TestImportWritesBatchesInParallel(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.ImportBatchSize = 3
+	env.ingest.ImportWriters = 4
+	batches := &batchStorage{ModelStorage: env.store}
+	env.store = batches
+	svc := env.newService()
+
+	imported, err := svc.ImportModels(context.Background(), strings.NewReader(jsonLines(50)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 50 {
+		t.Errorf("imported %d models, want 50", imported)
+	}
+	written := 0
+	for _, size := range batches.sizes {
+		written += size
+	}
+	if len(batches.sizes) != 17 || written != 50 {
+		t.Errorf("wrote %d models in %d batches, want 50 in 17", written, len(batches.sizes))
+	}
+	for i := range 50 {
+		env.stored(t, fmt.Sprintf("a/model-%d", i))
+	}
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingStorage fails every bulk write.
+type failingStorage struct {
+	service.ModelStorage
+}
+
+func (failingStorage) BulkUpsert(context.Context, []domain.HuggingFaceModel) error {
+	return errWriteFailed
+}
+
+func TestImportReportsMalformedRecords(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.ImportBatchSize = 2
+	svc := env.newService()
+
+	imported, err := svc.ImportModels(context.Background(), strings.NewReader(jsonLines(2)+"{not json\n"))
+	if !errors.Is(err, service.ErrMalformedImport) {
+		t.Fatalf("err = %v, want ErrMalformedImport", err)
+	}
+	if !strings.Contains(err.Error(), "record 3") {
+		t.Errorf("err = %v, want it to name record 3", err)
+	}
+	if imported != 2 {
+		t.Errorf("imported %d models, want 2", imported)
+	}
+}
+
+func TestImportReportsWriteFailuresAheadOfMalformedRecords(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.ImportBatchSize = 2
+	env.store = failingStorage{ModelStorage: env.store}
+	svc := env.newService()
+
+	imported, err := svc.ImportModels(context.Background(), strings.NewReader(jsonLines(2)+"{not json\n"))
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("err = %v, want the write failure", err)
+	}
+	if errors.Is(err, service.ErrMalformedImport) {
+		t.Errorf("err = %v, a failed write must not be reported as a malformed import", err)
+	}
+	if imported != 0 {
+		t.Errorf("imported %d models, want 0", imported)
+	}
+}
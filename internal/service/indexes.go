@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// writeGate lets backfill writes be held back while a long-running operation,
+// such as an index build, has the collection busy.
+type writeGate struct {
+	mu     sync.Mutex
+	paused chan struct{} // non-nil while paused, closed on resume
+}
+
+// pause holds back writes until resume is called.
+func (g *writeGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused == nil {
+		g.paused = make(chan struct{})
+	}
+}
+
+// resume releases every writer waiting on the gate.
+func (g *writeGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused != nil {
+		close(g.paused)
+		g.paused = nil
+	}
+}
+
+// isPaused reports whether writes are currently held back.
+func (g *writeGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused != nil
+}
+
+// wait blocks while the gate is paused. It returns early with the context's
+// error if ctx is cancelled first.
+func (g *writeGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	paused := g.paused
+	g.mu.Unlock()
+	if paused == nil {
+		return nil
+	}
+
+	select {
+	case <-paused:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EnsureIndexes creates the model indexes the queries rely on. Backfill upserts
+// are paused for the duration of the build, since a build on a large
+// collection competing with bulk writes slows both down. On startup it runs
+// before Start, so the first watch cycle already has its benchmark index.
+func (s *Service) EnsureIndexes(ctx context.Context) {
+	s.indexBuild.pause()
+	defer s.indexBuild.resume()
+
+	log.Println("Ensuring model indexes; backfill writes are paused until done.")
+	start := time.Now()
+	if err := s.modelStorage.EnsureIndexes(ctx); err != nil {
+		log.Printf("Failed to ensure model indexes: %v", err)
+		return
+	}
+	log.Printf("Model indexes ready after %s.", time.Since(start).Round(time.Second))
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// blockingIndexes holds EnsureIndexes until release is closed.
+type blockingIndexes struct {
+	service.ModelStorage
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingIndexes) EnsureIndexes(ctx context.Context) error {
+	close(s.started)
+	<-s.release
+	return s.ModelStorage.EnsureIndexes(ctx)
+}
+
+func TestBackfillWritesWaitForIndexBuild(t *testing.T) {
+	env := newTestEnv(t)
+	blocking := &blockingIndexes{ModelStorage: env.store, started: make(chan struct{}), release: make(chan struct{})}
+	env.store = blocking
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/one", 1), model("a/two", 2)})
+	svc := env.newService()
+	ctx := context.Background()
+
+	built := make(chan struct{})
+	go func() {
+		defer close(built)
+		svc.EnsureIndexes(ctx)
+	}()
+	<-blocking.started
+	if status, err := svc.GetStatus(ctx); err != nil || !status.IndexBuilding {
+		t.Fatalf("GetStatus() = %+v, %v during the build, want IndexBuilding", status, err)
+	}
+
+	backfilled := make(chan error, 1)
+	go func() { backfilled <- svc.RunBackfill(ctx, "") }()
+	select {
+	case err := <-backfilled:
+		t.Fatalf("the backfill finished during the index build: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if model, _ := env.memory.FindByID(ctx, "a/one"); model != nil {
+		t.Fatal("a backfill page was stored during the index build")
+	}
+
+	close(blocking.release)
+	<-built
+	select {
+	case err := <-backfilled:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backfill did not finish after the index build")
+	}
+	env.stored(t, "a/one")
+	env.stored(t, "a/two")
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+)
+
+// Prefixes of the tags that encode structured model metadata, e.g.
+// "license:apache-2.0", "library:transformers" or "language:en".
+const (
+	licenseTagPrefix  = "license:"
+	libraryTagPrefix  = "library:"
+	languageTagPrefix = "language:"
+)
+
+// dropMissingIDs removes the malformed models the API occasionally returns
+// with an empty or blank ID. Stored, they would all collide on _id "" and
+// could become the watcher's benchmark model. source names the caller in the
+// log line.
+func (s *Service) dropMissingIDs(models []domain.HuggingFaceModel, source string) []domain.HuggingFaceModel {
+	kept := models[:0]
+	for _, model := range models {
+		if strings.TrimSpace(model.ID) != "" {
+			kept = append(kept, model)
+		}
+	}
+	if skipped := len(models) - len(kept); skipped > 0 {
+		log.Printf("%s: Skipped %d models without an ID.", source, skipped)
+		s.metrics.AddCounter(metrics.ModelsSkippedNoID, float64(skipped))
+	}
+	return kept
+}
+
+// prepareModels applies the configured ingest transformations to a page of
+// models right before it is written to storage.
+func (s *Service) prepareModels(models []domain.HuggingFaceModel) []domain.HuggingFaceModel {
+	for i := range models {
+		if s.ingestCfg.NormalizeTags {
+			models[i].Tags = normalizeTags(models[i].Tags)
+		}
+		deriveTagFields(&models[i])
+		capTags(&models[i], s.ingestCfg.MaxTags)
+		if s.ingestCfg.CompactDocuments {
+			compactModel(&models[i])
+		}
+	}
+	return models
+}
+
+// normalizeTags lowercases and trims tags and drops empty and duplicate ones,
+// keeping the order in which tags first occur.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// deriveTagFields fills the structured fields that the Hub only encodes in a
+// model's tags. The tags themselves are left untouched.
+func deriveTagFields(model *domain.HuggingFaceModel) {
+	model.License = firstTagValue(model.Tags, licenseTagPrefix)
+	model.Library = firstTagValue(model.Tags, libraryTagPrefix)
+	model.Languages = extractLanguages(model.Tags)
+}
+
+// firstTagValue returns the lowercased value of the first tag with the given
+// prefix, or an empty string if there is none. Prefixes match case-insensitively.
+func firstTagValue(tags []string, prefix string) string {
+	for _, tag := range tags {
+		if value, ok := tagValue(tag, prefix); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// tagValue strips prefix from tag and reports whether a non-empty value remained.
+func tagValue(tag, prefix string) (string, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) <= len(prefix) || !strings.EqualFold(tag[:len(prefix)], prefix) {
+		return "", false
+	}
+	value := strings.ToLower(strings.TrimSpace(tag[len(prefix):]))
+	return value, value != ""
+}
+
+// extractLanguages collects the languages a model is tagged with. The Hub tags
+// languages either as a bare ISO 639-1 code ("en") or as "language:en".
+// Duplicates are dropped and the tag order is kept.
+func extractLanguages(tags []string) []string {
+	var languages []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		language, ok := tagValue(tag, languageTagPrefix)
+		if !ok {
+			language = strings.TrimSpace(tag)
+			if !isLanguageCode(language) {
+				continue
+			}
+		}
+		if !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// isLanguageCode reports whether tag looks like a bare two-letter language code.
+// Upper-case tags are not treated as codes, since the Hub emits them lowercase.
+func isLanguageCode(tag string) bool {
+	return len(tag) == 2 && tag[0] >= 'a' && tag[0] <= 'z' && tag[1] >= 'a' && tag[1] <= 'z'
+}
+
+// capTags keeps the first maxTags tags of a model and flags it as truncated
+// if any were dropped. The tag-derived fields are filled from the full list
+// beforehand, and the full list can be recovered from the Hub on demand.
+// A maxTags of zero keeps every tag.
+func capTags(model *domain.HuggingFaceModel, maxTags int) {
+	if maxTags <= 0 || len(model.Tags) <= maxTags {
+		return
+	}
+	model.Tags = slices.Clip(model.Tags[:maxTags])
+	model.TagsTruncated = true
+}
+
+// compactModel drops the rarely-used, space-hungry fields of a model. The full
+// record can always be recovered from the Hub on demand.
+func compactModel(model *domain.HuggingFaceModel) {
+	model.SHA = ""
+	model.Siblings = nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+)
+
+func TestDeriveTagFieldsExtractsLicense(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		want string
+	}{
+		{tags: []string{"transformers", "license:apache-2.0"}, want: "apache-2.0"},
+		{tags: []string{"License:MIT"}, want: "mit"},
+		{tags: []string{" license: bsd-3-clause "}, want: "bsd-3-clause"},
+		{tags: []string{"license:", "license:mit", "license:other"}, want: "mit"},
+		{tags: []string{"licensed", "pytorch"}, want: ""},
+		{tags: nil, want: ""},
+	} {
+		model := domain.HuggingFaceModel{Tags: tc.tags}
+		deriveTagFields(&model)
+		if model.License != tc.want {
+			t.Errorf("tags %q: License = %q, want %q", tc.tags, model.License, tc.want)
+		}
+	}
+}
+
+func TestDeriveTagFieldsExtractsLibraryAndLanguages(t *testing.T) {
+	tags := []string{"library:Transformers", "pytorch", "en", "language:fr", "EN", "de", "fr", "eng", "library:diffusers"}
+	model := domain.HuggingFaceModel{Tags: tags}
+	deriveTagFields(&model)
+
+	if model.Library != "transformers" {
+		t.Errorf("Library = %q, want transformers", model.Library)
+	}
+	if want := []string{"en", "fr", "de"}; !slices.Equal(model.Languages, want) {
+		t.Errorf("Languages = %q, want %q", model.Languages, want)
+	}
+	if !slices.Equal(model.Tags, tags) {
+		t.Errorf("Tags = %q, want them untouched", model.Tags)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		want []string
+	}{
+		{tags: []string{"PyTorch", "pytorch", "Transformers", "PYTORCH"}, want: []string{"pytorch", "transformers"}},
+		{tags: []string{"  en ", "en", "\tlicense:mit\n", " ", ""}, want: []string{"en", "license:mit"}},
+		{tags: []string{}, want: []string{}},
+		{tags: nil, want: nil},
+	} {
+		got := normalizeTags(tc.tags)
+		if !slices.Equal(got, tc.want) || (got == nil) != (tc.want == nil) {
+			t.Errorf("normalizeTags(%q) = %q, want %q", tc.tags, got, tc.want)
+		}
+	}
+}
+
+func TestPrepareModelsNormalizesTagsOnlyWhenEnabled(t *testing.T) {
+	raw := []string{"PyTorch", " pytorch "}
+	for _, enabled := range []bool{false, true} {
+		s := &Service{ingestCfg: config.IngestConfig{NormalizeTags: enabled}}
+		models := s.prepareModels([]domain.HuggingFaceModel{{Tags: slices.Clone(raw)}})
+		want := raw
+		if enabled {
+			want = []string{"pytorch"}
+		}
+		if !slices.Equal(models[0].Tags, want) {
+			t.Errorf("NormalizeTags=%v: tags = %q, want %q", enabled, models[0].Tags, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+	"hf-scraper/internal/service"
+)
+
+func TestWatchCycleReportsMetrics(t *testing.T) {
+	env := newTestEnv(t)
+	recorder := metricstest.NewRecorder()
+	env.metrics = recorder
+	env.seed(t, model("a/old", 0))
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/new", 2), model("a/newer", 1), model("a/old", 0)})
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+
+	for name, want := range map[string]float64{
+		metrics.WatchCycles:     1,
+		metrics.ModelsUpserted:  2,
+		metrics.ScraperRequests: 1,
+	} {
+		if got := recorder.Counter(name); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if got := recorder.Counter(metrics.ModelUpsertErrors); got != 0 {
+		t.Errorf("%s = %v, want 0", metrics.ModelUpsertErrors, got)
+	}
+	if observed := recorder.Observations(metrics.WatchCycleDuration); len(observed) != 1 {
+		t.Errorf("%s observed %d times, want once", metrics.WatchCycleDuration, len(observed))
+	}
+	if observed := recorder.Observations(metrics.ScraperRequestDuration); len(observed) != 1 {
+		t.Errorf("%s observed %d times, want once", metrics.ScraperRequestDuration, len(observed))
+	}
+}
+
+func TestWatchIntervalAveragesRecentCycleStarts(t *testing.T) {
+	env := newTestEnv(t)
+	recorder := metricstest.NewRecorder()
+	env.metrics = recorder
+	env.watcher.CycleHistorySize = 3
+	env.seed(t, model("a/old", 0))
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/old", 0)})
+	var now time.Time
+	svc := env.newService(service.WithClock(func() time.Time { return now }))
+
+	// The oldest start falls out of the three remembered ones.
+	for _, minutes := range []int{0, 60, 130, 180} {
+		now = at(minutes)
+		svc.RunWatchCycle(context.Background())
+	}
+
+	for name, want := range map[string]float64{
+		metrics.WatchIntervalLast:    (50 * time.Minute).Seconds(),
+		metrics.WatchIntervalAverage: (60 * time.Minute).Seconds(),
+	} {
+		if got, _ := recorder.Gauge(name); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	status, err := svc.GetStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status.WatchInterval; got.LastSeconds != 3000 || got.AverageSeconds != 3600 || got.ConfiguredSeconds != 3600 {
+		t.Errorf("status watch interval = %+v, want last 3000s, average 3600s, configured 3600s", got)
+	}
+}
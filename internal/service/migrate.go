@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"log"
+)
+
+// NormalizeMissingFields backfills sortable fields missing from models stored
+// by older versions, so sorting by them is deterministic. It is idempotent
+// and meant to run once in the background on startup.
+func (s *Service) NormalizeMissingFields(ctx context.Context) {
+	log.Println("Normalizing models with missing sortable fields...")
+	updated, err := s.modelStorage.NormalizeMissingFields(ctx)
+	if err != nil {
+		log.Printf("Failed to normalize missing fields after %d updates: %v", updated, err)
+		return
+	}
+	if updated > 0 {
+		s.cache.clear()
+	}
+	log.Printf("Normalized %d missing model fields.", updated)
+}
@@ -0,0 +1,42 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+)
+
+func TestModelsWithoutIDAreSkipped(t *testing.T) {
+	for name, run := range map[string]func(*testEnv) error{
+		"backfill": func(env *testEnv) error {
+			return env.newService().RunBackfill(context.Background(), "")
+		},
+		"watch": func(env *testEnv) error {
+			env.newService().RunWatchCycle(context.Background())
+			return nil
+		},
+	} {
+		env := newTestEnv(t)
+		recorder := metricstest.NewRecorder()
+		env.metrics = recorder
+		blank, spaces := model("", 9), model("   ", 8)
+		env.hub.setPages([]domain.HuggingFaceModel{blank, model("a/newer", 5), spaces, model("a/older", 4)})
+
+		if err := run(env); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		env.stored(t, "a/newer")
+		env.stored(t, "a/older")
+		for _, id := range []string{"", "   "} {
+			if stored, _ := env.memory.FindByID(context.Background(), id); stored != nil {
+				t.Errorf("%s: the model with ID %q was stored", name, id)
+			}
+		}
+		if got := recorder.Counter(metrics.ModelsSkippedNoID); got != 2 {
+			t.Errorf("%s: %s = %v, want 2", name, metrics.ModelsSkippedNoID, got)
+		}
+	}
+}
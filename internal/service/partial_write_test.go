@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/metrics/metricstest"
+	"hf-scraper/internal/service"
+)
+
+// rejectingStorage rejects the bulk writes of some model IDs the way an
+// unordered bulk write does: the other models are written, and the rejected
+// ones are reported in a *service.PartialWriteError. It records the model IDs
+// of the snapshots written through it.
+type rejectingStorage struct {
+	service.ModelStorage
+	reject map[string]bool
+
+	mu        sync.Mutex
+	snapshots []string
+}
+
+func (s *rejectingStorage) BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
+	failed := make(map[string]string)
+	var accepted []domain.HuggingFaceModel
+	for _, model := range models {
+		if s.reject[model.ID] {
+			failed[model.ID] = "document failed validation"
+			continue
+		}
+		accepted = append(accepted, model)
+	}
+	if err := s.ModelStorage.BulkUpsert(ctx, accepted); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return &service.PartialWriteError{Failed: failed}
+	}
+	return nil
+}
+
+func (s *rejectingStorage) RecordSnapshots(ctx context.Context, snapshots []domain.ModelSnapshot) error {
+	s.mu.Lock()
+	for _, snapshot := range snapshots {
+		s.snapshots = append(s.snapshots, snapshot.ModelID)
+	}
+	s.mu.Unlock()
+	return s.ModelStorage.RecordSnapshots(ctx, snapshots)
+}
+
+func TestBackfillSkipsModelsTheDatabaseRejects(t *testing.T) {
+	env := newTestEnv(t)
+	recorder := metricstest.NewRecorder()
+	env.metrics = recorder
+	env.watcher.RecordHistory = true
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/good", 2), model("a/bad", 1)},
+		[]domain.HuggingFaceModel{model("a/next", 0)},
+	)
+	store := &rejectingStorage{ModelStorage: env.store, reject: map[string]bool{"a/bad": true}}
+	env.store = store
+	svc := env.newService()
+
+	if err := svc.RunBackfill(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	env.stored(t, "a/good")
+	// The page with the rejected model completed, so the backfill went on.
+	env.stored(t, "a/next")
+	if model, _ := env.memory.FindByID(context.Background(), "a/bad"); model != nil {
+		t.Error("the rejected a/bad was stored")
+	}
+	if pages := recorder.Counter(metrics.BackfillPages); pages != 2 {
+		t.Errorf("%s = %v, want 2", metrics.BackfillPages, pages)
+	}
+	if errs := recorder.Counter(metrics.ModelUpsertErrors); errs != 1 {
+		t.Errorf("%s = %v, want the one rejected model", metrics.ModelUpsertErrors, errs)
+	}
+	if upserted := recorder.Counter(metrics.ModelsUpserted); upserted != 2 {
+		t.Errorf("%s = %v, want the two stored models", metrics.ModelsUpserted, upserted)
+	}
+	slices.Sort(store.snapshots)
+	if want := []string{"a/good", "a/next"}; !slices.Equal(store.snapshots, want) {
+		t.Errorf("history was recorded for %v, want only the stored %v", store.snapshots, want)
+	}
+}
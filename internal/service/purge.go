@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runPurger periodically hard-deletes models whose soft-delete is older than
+// the retention period, until ctx is cancelled.
+func (s *Service) runPurger(ctx context.Context) {
+	if s.cfg.DeletedRetentionDays <= 0 || s.cfg.PurgeIntervalMinutes <= 0 {
+		return
+	}
+	retention := time.Duration(s.cfg.DeletedRetentionDays) * 24 * time.Hour
+	log.Printf("Purger: removing models deleted more than %d days ago every %d minutes.", s.cfg.DeletedRetentionDays, s.cfg.PurgeIntervalMinutes)
+	ticker := time.NewTicker(time.Duration(s.cfg.PurgeIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.purgeDeleted(ctx, time.Now().Add(-retention))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// purgeDeleted removes the models soft-deleted before cutoff.
+func (s *Service) purgeDeleted(ctx context.Context, cutoff time.Time) {
+	purged, err := s.modelStorage.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		log.Printf("Purger Error: could not remove deleted models: %v", err)
+		return
+	}
+	if purged > 0 {
+		s.cache.clear()
+	}
+	log.Printf("Purger: removed %d models deleted before %s.", purged, cutoff.Format(time.RFC3339))
+}
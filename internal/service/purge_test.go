@@ -0,0 +1,30 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPurgeRemovesOnlyModelsDeletedBeforeTheCutoff(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/expired", 0), model("a/recent", 0), model("a/live", 0))
+	ctx := context.Background()
+	for id, deletedAt := range map[string]int{"a/expired": 10, "a/recent": 30} {
+		if err := env.memory.MarkDeleted(ctx, id, at(deletedAt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc := env.newService()
+
+	svc.PurgeDeleted(ctx, at(20))
+
+	if model, _ := env.memory.FindByID(ctx, "a/expired"); model != nil {
+		t.Error("a/expired was deleted before the cutoff but is still stored")
+	}
+	if recent := env.stored(t, "a/recent"); recent.DeletedAt == nil {
+		t.Error("a/recent lost its soft-delete mark")
+	}
+	if live := env.stored(t, "a/live"); live.DeletedAt != nil {
+		t.Errorf("a/live was marked deleted at %v", live.DeletedAt)
+	}
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// limit is a rate limit as reported by Scraper.Limit.
+type limit struct {
+	rate  float64
+	burst int
+}
+
+func TestModeChangeSwitchesRateLimits(t *testing.T) {
+	env := newTestEnv(t)
+	env.scraper.RequestsPerSecond, env.scraper.BurstLimit = 500, 2
+	env.scraper.BackfillRequestsPerSecond, env.scraper.BackfillBurstLimit = 800, 50
+	env.hub.setPages([]domain.HuggingFaceModel{model("a/one", 1)})
+	svc := env.newService()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The hub records the limit in effect for the first backfill and the
+	// first watch request, then stops the service.
+	var mu sync.Mutex
+	seen := make(map[string]limit)
+	env.hub.failWith(func(r *http.Request) int {
+		mode := "watch"
+		if strings.Contains(r.URL.RawQuery, "sort=createdAt") {
+			mode = "backfill"
+		}
+		rate, burst := env.client.Limit()
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := seen[mode]; !ok {
+			seen[mode] = limit{rate, burst}
+		}
+		if mode == "watch" {
+			cancel()
+		}
+		return 0
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- svc.Start(ctx) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the service did not stop")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for mode, want := range map[string]limit{"backfill": {800, 50}, "watch": {500, 2}} {
+		if got, ok := seen[mode]; !ok || got != want {
+			t.Errorf("%s limit = %+v (seen %t), want %+v", mode, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/service"
+)
+
+func TestRecentlyModifiedWindowFollowsClock(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/old", 30), model("a/boundary", 60), model("a/recent", 90), model("a/latest", 119))
+	svc := env.newService(service.WithClock(func() time.Time { return at(120) }))
+
+	models, total, err := svc.GetRecentlyModified(context.Background(), time.Hour, service.SearchOptions{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+	if want := []string{"a/latest", "a/recent", "a/boundary"}; !slices.Equal(ids, want) || total != 3 {
+		t.Errorf("got %v of %d, want %v of 3", ids, total, want)
+	}
+
+	models, total, err = svc.GetRecentlyModified(context.Background(), time.Minute, service.SearchOptions{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(models) != 1 || models[0].ID != "a/latest" {
+		t.Errorf("1m window: got %v of %d, want only a/latest", models, total)
+	}
+}
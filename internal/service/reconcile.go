@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/metrics"
+	"hf-scraper/internal/scraper"
+)
+
+// runReconciler periodically re-checks stored models against the Hub, one
+// batch per tick, until ctx is cancelled. It walks the collection in ID order
+// and starts over once it reaches the end.
+func (s *Service) runReconciler(ctx context.Context) {
+	if s.cfg.ReconcileIntervalMinutes <= 0 || s.cfg.ReconcileBatchSize <= 0 {
+		return
+	}
+	log.Printf("Reconciler: checking %d models every %d minutes.", s.cfg.ReconcileBatchSize, s.cfg.ReconcileIntervalMinutes)
+	ticker := time.NewTicker(time.Duration(s.cfg.ReconcileIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	afterID := ""
+	for {
+		select {
+		case <-ticker.C:
+			afterID = s.reconcileBatch(ctx, afterID)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reconcileBatch checks the next batch of models after afterID. Models the
+// Hub no longer knows are marked as deleted, the others are refreshed with
+// the fetched record. It returns the ID to continue from on the next run: the
+// last model checked, so a model that failed is checked again.
+func (s *Service) reconcileBatch(ctx context.Context, afterID string) string {
+	ids, err := s.modelStorage.ListIDs(ctx, afterID, s.cfg.ReconcileBatchSize)
+	if err != nil {
+		log.Printf("Reconciler Error: could not list model IDs: %v", err)
+		return afterID
+	}
+	if len(ids) == 0 {
+		// Reached the end of the collection, wrap around on the next run.
+		return ""
+	}
+
+	checked, deleted, refreshed := 0, 0, 0
+batch:
+	for _, id := range ids {
+		// Each fetch waits on the scraper's rate limiter, which it shares with watch mode.
+		model, err := s.scraper.FetchModelByID(ctx, id)
+		switch {
+		case errors.Is(err, scraper.ErrModelNotFound):
+			if err := s.modelStorage.MarkDeleted(ctx, id, s.now()); err != nil {
+				log.Printf("Reconciler Error: could not mark %s as deleted: %v", id, err)
+				break batch
+			}
+			deleted++
+		case err != nil:
+			// Leave the rest of the batch for the next run rather than hammering a failing API.
+			log.Printf("Reconciler Error: could not fetch %s: %v", id, err)
+			break batch
+		default:
+			if _, err := s.upsertWithResult(ctx, s.prepareModels([]domain.HuggingFaceModel{*model})[0]); err != nil {
+				log.Printf("Reconciler Error: could not refresh %s: %v", id, err)
+				break batch
+			}
+			refreshed++
+		}
+		s.metrics.IncCounter(metrics.ReconcileChecked)
+		checked++
+		afterID = id
+	}
+
+	if deleted > 0 {
+		s.metrics.AddCounter(metrics.ReconcileDeleted, float64(deleted))
+		s.cache.clear()
+	}
+	log.Printf("Reconciler: checked %d models, %d deleted, %d refreshed.", checked, deleted, refreshed)
+	return afterID
+}
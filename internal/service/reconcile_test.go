@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/service"
+)
+
+func TestReconcileDeletesGoneModelsAndRetriesFailures(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.ReconcileBatchSize = 10
+	env.seed(t, model("a/gone", 0), model("a/live", 0), model("a/flaky", 0))
+	live, flaky := model("a/live", 1), model("a/flaky", 1)
+	live.Likes, flaky.Likes = 5, 7
+	env.hub.setModels(live, flaky)
+	var failing atomic.Bool
+	failing.Store(true)
+	env.hub.failWith(func(r *http.Request) int {
+		if r.URL.Path == "/api/models/a/flaky" && failing.Load() {
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+	svc := env.newService()
+	ctx := context.Background()
+
+	// IDs are checked in order: a/flaky fails first, so nothing is checked.
+	if next := svc.ReconcileBatch(ctx, ""); next != "" {
+		t.Errorf("after a failed first model, the next run continues after %q, want the start", next)
+	}
+	if got := env.stored(t, "a/flaky").Likes; got != 0 {
+		t.Errorf("a/flaky likes = %d after a failed fetch, want it untouched", got)
+	}
+
+	failing.Store(false)
+	if next := svc.ReconcileBatch(ctx, ""); next != "a/live" {
+		t.Errorf("the next run continues after %q, want a/live", next)
+	}
+	if got := env.stored(t, "a/flaky").Likes; got != 7 {
+		t.Errorf("a/flaky likes = %d after the retry, want 7", got)
+	}
+	if got := env.stored(t, "a/live").Likes; got != 5 {
+		t.Errorf("a/live likes = %d, want it refreshed to 5", got)
+	}
+	if gone := env.stored(t, "a/gone"); gone.DeletedAt == nil {
+		t.Error("a/gone, which the Hub answers with 404, was not marked deleted")
+	}
+}
+
+func TestReconcileKeepsTheFirstDeletionTime(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.ReconcileBatchSize = 10
+	env.seed(t, model("a/gone", 0))
+	now := at(10)
+	svc := env.newService(service.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	svc.ReconcileBatch(ctx, "")
+	now = at(20)
+	svc.ReconcileBatch(ctx, "")
+	if deletedAt := env.stored(t, "a/gone").DeletedAt; deletedAt == nil || !deletedAt.Equal(at(10)) {
+		t.Fatalf("a/gone deletedAt = %v after a second pass, want the first pass's %s", deletedAt, at(10))
+	}
+
+	// The retention period counts from the first pass, so the model is purged.
+	svc.PurgeDeleted(ctx, at(15))
+	if model, _ := env.memory.FindByID(ctx, "a/gone"); model != nil {
+		t.Error("a/gone was not purged past its retention")
+	}
+}
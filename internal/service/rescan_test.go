@@ -0,0 +1,40 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"hf-scraper/internal/domain"
+)
+
+func TestRescanOverridesTheBenchmarkForOneCycle(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/newest", 100), model("a/middle", 90), model("a/old", 80))
+	changed := func(id string, minutes int, likes domain.FlexibleInt) domain.HuggingFaceModel {
+		m := model(id, minutes)
+		m.Likes = likes
+		return m
+	}
+	env.hub.setPages([]domain.HuggingFaceModel{changed("a/newest", 100, 7), changed("a/middle", 90, 7), changed("a/old", 80, 7)})
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+	if got := env.stored(t, "a/newest").Likes; got != 0 {
+		t.Fatalf("without a rescan the cycle stored a model at the benchmark (likes %d)", got)
+	}
+
+	svc.RescanWatch(at(85))
+	svc.RunWatchCycle(context.Background())
+	for id, want := range map[string]domain.FlexibleInt{"a/newest": 7, "a/middle": 7, "a/old": 0} {
+		if got := env.stored(t, id).Likes; got != want {
+			t.Errorf("after the rescan, %s has %d likes, want %d", id, got, want)
+		}
+	}
+
+	// The override is spent: the next cycle compares with the stored benchmark again.
+	env.hub.setPages([]domain.HuggingFaceModel{changed("a/newest", 100, 9), changed("a/middle", 90, 9), changed("a/old", 80, 9)})
+	svc.RunWatchCycle(context.Background())
+	if got := env.stored(t, "a/middle").Likes; got != 7 {
+		t.Errorf("the cycle after the rescan stored a/middle again (likes %d, want 7)", got)
+	}
+}
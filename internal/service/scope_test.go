@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"hf-scraper/internal/domain"
+)
+
+func TestScopeParamsSurvivePagination(t *testing.T) {
+	env := newTestEnv(t)
+	env.scraper.Filter = "diffusers"
+	env.scraper.Search = "sdxl"
+	env.hub.bareLinks = true
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/one", 1)},
+		[]domain.HuggingFaceModel{model("a/two", 2)},
+		[]domain.HuggingFaceModel{model("a/three", 3)},
+	)
+	svc := env.newService()
+	ctx := context.Background()
+
+	if err := svc.RunBackfill(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/six", 6)},
+		[]domain.HuggingFaceModel{model("a/five", 5)},
+		[]domain.HuggingFaceModel{model("a/four", 4)},
+	)
+	svc.RunWatchCycle(ctx)
+
+	requests := env.hub.listRequests()
+	if len(requests) < 6 {
+		t.Fatalf("listing requests = %q, want three pages for the backfill and three for the watch cycle", requests)
+	}
+	for _, uri := range requests {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := parsed.Query()
+		if query.Get("filter") != "diffusers" || query.Get("search") != "sdxl" {
+			t.Errorf("request %s lost the scope parameters", uri)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hf-scraper/internal/service"
+)
+
+func TestInvalidPatternsAreRejectedBeforeStorage(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/one", 0))
+	svc := env.newService()
+	ctx := context.Background()
+	opts := service.SearchOptions{Query: "[unclosed"}
+
+	for name, query := range map[string]func(service.SearchOptions) error{
+		"SearchModels": func(opts service.SearchOptions) error {
+			_, _, err := svc.SearchModels(ctx, opts)
+			return err
+		},
+		"GetModelsByTask": func(opts service.SearchOptions) error {
+			_, _, err := svc.GetModelsByTask(ctx, "text-generation", opts)
+			return err
+		},
+		"GetAuthorCounts": func(opts service.SearchOptions) error {
+			_, _, err := svc.GetAuthorCounts(ctx, opts)
+			return err
+		},
+	} {
+		if err := query(opts); !errors.Is(err, service.ErrInvalidSearchPattern) {
+			t.Errorf("%s: err = %v, want ErrInvalidSearchPattern", name, err)
+		}
+		literal := opts
+		literal.Literal = true
+		if err := query(literal); err != nil {
+			t.Errorf("%s: literal query failed: %v", name, err)
+		}
+	}
+}
@@ -3,19 +3,35 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"hf-scraper/internal/config"
 	"hf-scraper/internal/domain"
 	"hf-scraper/internal/events"
+	"hf-scraper/internal/metrics"
 	"hf-scraper/internal/scraper"
+	"hf-scraper/internal/tracing"
+
+	"golang.org/x/sync/singleflight"
 )
 
+var tracer = tracing.Tracer("hf-scraper/service")
+
 const (
 	// Event topics
 	EventModeChange = "status:mode_change"
+	// EventModelNew and EventModelUpdated carry the ID of a model the watcher
+	// or the reconciler stored for the first time or changed.
+	EventModelNew     = "model:new"
+	EventModelUpdated = "model:updated"
 )
 
 // Service is the central orchestrator of the daemon's logic.
@@ -26,6 +42,46 @@ type Service struct {
 	modelStorage  ModelStorage
 	statusStorage StatusStorage
 	broker        *events.Broker
+	cache         *modelCache
+	cacheCfg      config.CacheConfig
+	ingestCfg     config.IngestConfig
+	dbCfg         config.DatabaseConfig
+	summary       summaryCache
+	metrics       metrics.Metrics
+	// skippedUnchanged counts watch-cycle writes avoided by dropUnchanged.
+	skippedUnchanged atomic.Int64
+	// indexBuild holds back backfill writes while EnsureIndexes runs.
+	indexBuild writeGate
+	// authors backs the new-author fast path of the watch cycle.
+	authors *knownAuthors
+	// writes bounds concurrent model upserts to DatabaseConfig.MaxConcurrentWrites.
+	writes writeSemaphore
+	// fetches deduplicates concurrent fetches of the same page URL.
+	fetches singleflight.Group
+	// cycles remembers when recent watch cycles started.
+	cycles *cycleTimes
+	// watchSince, when set by RescanWatch, replaces the stored benchmark
+	// timestamp for the next watch cycle that fetches successfully.
+	watchSince atomic.Pointer[time.Time]
+	// gap, when a watch cycle stopped at WATCHER.MAX_PAGES_PER_CYCLE, is where
+	// the following cycles resume the listing.
+	gap atomic.Pointer[watchGap]
+	// badgesCfg and badges back the popularity badges set with WithBadges.
+	badgesCfg config.BadgesConfig
+	badges    badgeThresholds
+	// now is the clock used for time limits, replaceable with WithClock.
+	now func() time.Time
+}
+
+// Option customizes a Service created by NewService.
+type Option func(*Service)
+
+// WithClock makes the Service read the current time from now instead of
+// time.Now, e.g. a fake clock that advances on demand.
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
 }
 
 // NewService creates a new core application service.
@@ -36,15 +92,33 @@ func NewService(
 	modelStorage ModelStorage,
 	statusStorage StatusStorage,
 	broker *events.Broker,
+	cacheCfg config.CacheConfig,
+	ingestCfg config.IngestConfig,
+	dbCfg config.DatabaseConfig,
+	m metrics.Metrics,
+	opts ...Option,
 ) *Service {
-	return &Service{
+	s := &Service{
 		cfg:           cfg,
 		scraperCfg:    scraperCfg, // Added
 		scraper:       scraper,
 		modelStorage:  modelStorage,
 		statusStorage: statusStorage,
 		broker:        broker,
+		cache:         newModelCache(cacheCfg.MaxEntries, time.Duration(cacheCfg.TTLSeconds)*time.Second),
+		cacheCfg:      cacheCfg,
+		ingestCfg:     ingestCfg,
+		dbCfg:         dbCfg,
+		metrics:       m,
+		authors:       &knownAuthors{},
+		writes:        newWriteSemaphore(dbCfg.MaxConcurrentWrites),
+		cycles:        newCycleTimes(cfg.CycleHistorySize),
+		now:           time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Start begins the main operational loop of the service.
@@ -57,10 +131,14 @@ func (s *Service) Start(ctx context.Context) error {
 	}
 
 	log.Printf("Initial status is: %s", statusDoc.Status)
+	s.metrics.SetGauge(metrics.ServiceWatching, 0)
 
 	if statusDoc.Status == domain.StatusNeedsBackfill {
+		if s.cfg.ConcurrentBackfill {
+			return s.runConcurrently(ctx, statusDoc.Cursor(s.backfillShard()))
+		}
 		// Pass the cursor to the backfill process.
-		err := s.runBackfill(ctx, statusDoc.BackfillCursor)
+		err := s.runBackfill(ctx, statusDoc.Cursor(s.backfillShard()))
 		if err != nil {
 			// If context was cancelled, it's a graceful shutdown, not an error.
 			if ctx.Err() == context.Canceled {
@@ -78,12 +156,23 @@ func (s *Service) Start(ctx context.Context) error {
 // runBackfill executes the one-time, historical data scrape.
 func (s *Service) runBackfill(ctx context.Context, initialCursor string) error {
 	log.Println("Starting Backfill Mode...")
-	backfillStartURL := fmt.Sprintf("%s/api/models?sort=createdAt&direction=1&full=true", s.scraperCfg.BaseURL)
+	s.applyRateLimits(domain.StatusNeedsBackfill)
+	backfillStartURL := s.withScopeParams(fmt.Sprintf("%s/api/models?sort=createdAt&direction=1&full=true", s.scraperCfg.BaseURL))
+	if s.cfg.BackfillStartURL != "" {
+		// A configured start URL is also where a stale cursor starts over, so
+		// the reset never scrapes pages the operator chose to skip.
+		backfillStartURL = s.withScopeParams(s.cfg.BackfillStartURL)
+	}
 
+	shard := s.backfillShard()
 	currentURL := backfillStartURL
-	if initialCursor != "" {
+	if s.cfg.BackfillStartURL != "" && initialCursor == "" {
+		// The start URL only seeds a fresh backfill. Once a cursor is saved,
+		// restarts resume from it rather than scraping the first pages again.
+		log.Printf("Starting backfill from configured start URL: %s", s.cfg.BackfillStartURL)
+	} else if initialCursor != "" {
 		log.Printf("Resuming backfill from saved cursor: %s", initialCursor)
-		currentURL = initialCursor
+		currentURL = s.withScopeParams(initialCursor)
 	} else {
 		log.Println("Starting a fresh backfill. Saving initial state.")
 		if err := s.statusStorage.UpdateStatus(ctx, domain.StatusNeedsBackfill); err != nil {
@@ -91,41 +180,85 @@ func (s *Service) runBackfill(ctx context.Context, initialCursor string) error {
 		}
 	}
 
-	for currentURL != "" {
+	cursor := newCursorCoordinator(func(cursorURL string) error {
+		return s.statusStorage.UpdateBackfillCursor(ctx, shard, cursorURL)
+	})
+	// writers holds one token per page being written. Pages are fetched in
+	// order, since each links to the next, but stored in parallel.
+	writers := make(chan struct{}, max(s.cfg.BackfillWriters, 1))
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	started := s.now()
+	maxDuration := time.Duration(s.cfg.MaxBackfillMinutes) * time.Minute
+	completed := true
+	for seq := 0; currentURL != ""; {
+		if maxDuration > 0 && s.now().Sub(started) >= maxDuration {
+			log.Printf("Backfill: Reached the maximum duration of %s, stopping with the pages collected so far.", maxDuration)
+			completed = false
+			break
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 			log.Printf("Backfill: Fetching %s", currentURL)
-			result, err := s.scraper.FetchModels(ctx, currentURL)
-			if err != nil {
-				log.Printf("Error fetching page, will retry after 10s: %v", err)
-				time.Sleep(10 * time.Second)
+			result, err := s.fetchModels(ctx, currentURL)
+			if isGoneCursor(err) && currentURL != backfillStartURL {
+				// A stale pagination token never recovers, so retrying it would loop
+				// forever. Upserts are idempotent, so starting over is safe.
+				log.Printf("Backfill: cursor %s is no longer valid (%v), restarting from %s", currentURL, err, backfillStartURL)
+				if err := cursor.restart(seq, backfillStartURL); err != nil {
+					log.Printf("Warning: failed to reset backfill cursor: %v", err)
+				}
+				currentURL = backfillStartURL
 				continue
 			}
-
-			if len(result.Models) > 0 {
-				log.Printf("Backfill: Storing %d models...", len(result.Models))
-				if err := s.modelStorage.BulkUpsert(ctx, result.Models); err != nil {
-					log.Printf("CRITICAL: FAILED TO BULK UPSERT MODELS. Error: %v", err)
-					// We add a small sleep to avoid a rapid failure loop on DB issues.
-					time.Sleep(10 * time.Second)
-					continue // Retry the same page after a delay
+			if err != nil {
+				delay := fetchRetryDelay(err)
+				log.Printf("Error fetching page, will retry after %s: %v", delay, err)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return ctx.Err()
 				}
+				continue
 			}
 
-			// *** RESILIENCY FIX ***
-			// Update the cursor bookmark ONLY AFTER the page is processed successfully.
-			if err := s.statusStorage.UpdateBackfillCursor(ctx, result.NextURL); err != nil {
-				log.Printf("CRITICAL: FAILED TO SAVE BACKFILL CURSOR. Error: %v", err)
-				time.Sleep(10 * time.Second)
+			models := s.prepareModels(s.dropMissingIDs(result.Models, "Backfill"))
+			nextURL := s.withScopeParams(result.NextURL)
+			select {
+			case writers <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
 			}
+			inFlight.Add(1)
+			go func(seq int) {
+				defer inFlight.Done()
+				defer func() { <-writers }()
+				// *** RESILIENCY FIX ***
+				// The cursor only moves past this page once it and every page
+				// before it are stored.
+				if s.writeBackfillPage(ctx, models) {
+					cursor.complete(seq, nextURL)
+				}
+			}(seq)
 
-			currentURL = result.NextURL
+			seq++
+			currentURL = nextURL
 		}
 	}
+	inFlight.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	log.Println("Backfill Mode completed.")
+	if completed {
+		log.Println("Backfill Mode completed.")
+		if err := s.statusStorage.ClearBackfillCursor(ctx, shard); err != nil {
+			log.Printf("Warning: failed to clear the finished backfill cursor: %v", err)
+		}
+	}
 	log.Println("Updating service status to WATCHING.")
 	if err := s.statusStorage.UpdateStatus(ctx, domain.StatusWatching); err != nil {
 		return fmt.Errorf("failed to update status to WATCHING after backfill: %w", err)
@@ -137,17 +270,68 @@ func (s *Service) runBackfill(ctx context.Context, initialCursor string) error {
 
 // startWatcher begins the permanent, periodic watch for updates.
 func (s *Service) startWatcher(ctx context.Context) {
+	s.enterWatchMode(ctx)
+	s.runWatchLoop(ctx)
+}
+
+// enterWatchMode switches the service to watch mode and starts the background
+// tasks that run alongside the watch loop, until ctx is cancelled.
+func (s *Service) enterWatchMode(ctx context.Context) {
+	s.metrics.SetGauge(metrics.ServiceWatching, 1)
+	s.applyRateLimits(domain.StatusWatching)
+	go s.runReconciler(ctx)
+	go s.runPurger(ctx)
+}
+
+// runConcurrently runs the backfill and the watch loop side by side, so new
+// models are captured while history fills in behind. Both only upsert, so
+// their writes don't conflict. The backfill alone owns the status document and
+// the rate limits; the watcher only reads the database, and switches nothing
+// until the backfill has finished.
+func (s *Service) runConcurrently(ctx context.Context, initialCursor string) error {
+	log.Println("Running backfill and watch mode concurrently.")
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		s.runWatchLoop(watchCtx)
+	}()
+
+	if err := s.runBackfill(ctx, initialCursor); err != nil {
+		// The watch loop only ends on cancellation, so it is stopped before
+		// waiting for it rather than after its next interval.
+		stopWatching()
+		<-watchDone
+		if ctx.Err() == context.Canceled {
+			log.Println("Backfill process cancelled gracefully.")
+			return nil
+		}
+		return fmt.Errorf("backfill process failed: %w", err)
+	}
+
+	// The watcher is already running; settle into the regular watch mode setup.
+	s.enterWatchMode(ctx)
+	<-watchDone
+	return nil
+}
+
+// runWatchLoop runs a watch cycle immediately and then on every interval
+// until ctx is cancelled.
+func (s *Service) runWatchLoop(ctx context.Context) {
 	log.Printf("Starting Watch Mode. Checking for updates every %d minutes.", s.cfg.IntervalMinutes)
 	ticker := time.NewTicker(time.Duration(s.cfg.IntervalMinutes) * time.Minute)
 	defer ticker.Stop()
 
 	// Run the first cycle immediately on startup.
 	s.runWatchCycle(ctx)
+	s.refreshTopDownloads(ctx)
 
 	for {
 		select {
 		case <-ticker.C:
 			s.runWatchCycle(ctx)
+			s.refreshTopDownloads(ctx)
 		case <-ctx.Done():
 			log.Println("Watch Mode stopped.")
 			return
@@ -157,58 +341,536 @@ func (s *Service) startWatcher(ctx context.Context) {
 
 // runWatchCycle performs a single check for new or updated models.
 func (s *Service) runWatchCycle(ctx context.Context) {
-	log.Println("Watch Cycle: Starting check for latest models.")
-	watchStartURL := fmt.Sprintf("%s/api/models?sort=lastModified&direction=-1&full=true", s.scraperCfg.BaseURL)
+	ctx, span := tracer.Start(ctx, "service.WatchCycle")
+	defer span.End()
 
-	latestModel, err := s.modelStorage.FindMostRecentlyModified(ctx)
-	if err != nil {
-		log.Printf("Watch Cycle Error: could not get latest model from DB: %v", err)
-		return
+	log.Println("Watch Cycle: Starting check for latest models.")
+	s.metrics.IncCounter(metrics.WatchCycles)
+	s.cycles.record(s.now())
+	if last, average := s.cycles.intervals(); last > 0 {
+		s.metrics.SetGauge(metrics.WatchIntervalLast, last.Seconds())
+		s.metrics.SetGauge(metrics.WatchIntervalAverage, average.Seconds())
 	}
+	defer func(start time.Time) {
+		s.metrics.ObserveHistogram(metrics.WatchCycleDuration, time.Since(start).Seconds())
+	}(time.Now())
+	benchmark := s.benchmarkField()
+	watchStartURL := s.withScopeParams(fmt.Sprintf("%s/api/models?sort=%s&direction=-1&full=true", s.scraperCfg.BaseURL, benchmark))
 
 	latestKnownUpdate := time.Time{}
-	if latestModel != nil {
-		latestKnownUpdate = latestModel.LastModified
-		log.Printf("Watch Cycle: Latest known update timestamp is %s (from model %s)", latestKnownUpdate.Format(time.RFC3339), latestModel.ID)
+	override := s.watchSince.Load()
+	if override != nil {
+		latestKnownUpdate = *override
+		log.Printf("Watch Cycle: Rescan requested, re-examining models with %s after %s", benchmark, latestKnownUpdate.Format(time.RFC3339))
 	} else {
-		log.Println("Watch Cycle: No existing models found. Will fetch all new models.")
+		latestModel, err := s.modelStorage.FindExtremeBy(ctx, benchmark, -1)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			log.Println("Watch Cycle: No existing models found. Will fetch all new models.")
+		case err != nil:
+			// Treating a failed read as an empty database would re-store every
+			// page the cycle may follow, so the cycle is skipped instead.
+			log.Printf("Watch Cycle Error: could not read the latest %s from DB, skipping this cycle: %v", benchmark, err)
+			return
+		default:
+			latestKnownUpdate = latestModel.TimestampOf(benchmark)
+			log.Printf("Watch Cycle: Latest known %s timestamp is %s (from model %s)", benchmark, latestKnownUpdate.Format(time.RFC3339), latestModel.ID)
+		}
 	}
 
-	result, err := s.scraper.FetchModels(ctx, watchStartURL)
+	modelsToUpdate, resumeURL, err := s.collectWatchPages(ctx, watchStartURL, latestKnownUpdate, benchmark)
 	if err != nil {
-		log.Printf("Watch Cycle Error: failed to fetch from API: %v", err)
+		if ctx.Err() == nil {
+			log.Printf("Watch Cycle Error: %v. Dropping the %d new models of earlier pages, retrying next cycle.", err, len(modelsToUpdate))
+		}
 		return
 	}
+	if override != nil {
+		// The rescan is done once its pages are fetched, unless a newer one
+		// was requested meanwhile.
+		s.watchSince.CompareAndSwap(override, nil)
+	}
 
-	modelsToUpdate := make([]domain.HuggingFaceModel, 0)
-	for _, model := range result.Models {
-		if model.LastModified.After(latestKnownUpdate) {
-			modelsToUpdate = append(modelsToUpdate, model)
-		} else {
-			log.Println("Watch Cycle: Reached a model that is not new. Stopping check.")
+	// Storing the newest models advances the benchmark past the pages left
+	// behind at the limit, so the gap is remembered and scanned on its own.
+	// A new gap starts above any pending one and reaches down to its end,
+	// taking it over.
+	switch gap := s.gap.Load(); {
+	case resumeURL != "" && override != nil:
+		since, ok := s.rescanGapSince(ctx, benchmark, *override, modelsToUpdate)
+		if !ok {
+			log.Printf("Watch Cycle: Rescan stopped after %d pages (WATCHER.MAX_PAGES_PER_CYCLE).", max(s.cfg.MaxPagesPerCycle, 1))
 			break
 		}
+		if gap != nil && gap.since.Before(since) {
+			since = gap.since
+		}
+		s.gap.Store(&watchGap{url: resumeURL, since: since})
+		log.Printf("Watch Cycle Warning: still finding models newer than the stored ones after %d pages of the rescan (WATCHER.MAX_PAGES_PER_CYCLE); the next cycles resume from there.", max(s.cfg.MaxPagesPerCycle, 1))
+	case resumeURL != "":
+		since := latestKnownUpdate
+		if gap != nil && gap.since.Before(since) {
+			since = gap.since
+		}
+		s.gap.Store(&watchGap{url: resumeURL, since: since})
+		log.Printf("Watch Cycle Warning: still finding new models after %d pages (WATCHER.MAX_PAGES_PER_CYCLE); the next cycles resume from there.", max(s.cfg.MaxPagesPerCycle, 1))
+	case gap != nil:
+		older, gapResumeURL, err := s.collectWatchPages(ctx, gap.url, gap.since, benchmark)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Watch Cycle Error: could not resume the pages left at the last limit, retrying next cycle: %v", err)
+			break
+		}
+		modelsToUpdate = append(modelsToUpdate, older...)
+		if gapResumeURL != "" {
+			s.gap.Store(&watchGap{url: gapResumeURL, since: gap.since})
+			log.Printf("Watch Cycle: Resumed %d models left at the last limit; more remain for the next cycle.", len(older))
+		} else {
+			s.gap.CompareAndSwap(gap, nil)
+			log.Printf("Watch Cycle: Resumed %d models left at the last limit; caught up.", len(older))
+		}
 	}
 
 	if len(modelsToUpdate) > 0 {
 		log.Printf("Watch Cycle: Found %d new/updated models. Storing...", len(modelsToUpdate))
-		if err := s.modelStorage.BulkUpsert(ctx, modelsToUpdate); err != nil {
-			log.Printf("Watch Cycle Error: failed to bulk upsert models: %v", err)
+		modelsToUpdate = s.enrichNewAuthors(ctx, modelsToUpdate)
+		modelsToUpdate = s.dropUnchanged(ctx, s.prepareModels(modelsToUpdate))
+		stored, err := s.storeAndPublish(ctx, modelsToUpdate)
+		s.metrics.AddCounter(metrics.ModelsUpserted, float64(stored))
+		if err != nil {
+			log.Printf("Watch Cycle Error: failed to upsert %d of %d models: %v", len(modelsToUpdate)-stored, len(modelsToUpdate), err)
+			s.metrics.AddCounter(metrics.ModelUpsertErrors, float64(len(modelsToUpdate)-stored))
 		} else {
-			log.Printf("Watch Cycle: Finished. Stored %d models.", len(modelsToUpdate))
+			log.Printf("Watch Cycle: Finished. Stored %d models.", stored)
 		}
 	} else {
 		log.Printf("Watch Cycle: Finished. No new updates found.")
 	}
 }
 
+// rescanGapSince returns where the gap left by a rescan that stopped at
+// WATCHER.MAX_PAGES_PER_CYCLE ends, and false if it left none. A rescan only
+// re-examines up to the page limit: past it, only models newer than both
+// since and the stored benchmark are resumed, rather than walking the whole
+// listing. collected are the models the rescan found, newest first.
+func (s *Service) rescanGapSince(ctx context.Context, benchmark string, since time.Time, collected []domain.HuggingFaceModel) (time.Time, bool) {
+	latestModel, err := s.modelStorage.FindExtremeBy(ctx, benchmark, -1)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		log.Printf("Watch Cycle Error: could not read the latest %s from DB, not resuming the rescan: %v", benchmark, err)
+		return time.Time{}, false
+	default:
+		if latest := latestModel.TimestampOf(benchmark); latest.After(since) {
+			since = latest
+		}
+	}
+	if len(collected) > 0 && !collected[len(collected)-1].TimestampOf(benchmark).After(since) {
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// watchGap is the part of the listing a watch cycle left behind when it
+// stopped at WATCHER.MAX_PAGES_PER_CYCLE.
+type watchGap struct {
+	// url is the first page not fetched.
+	url string
+	// since is the benchmark timestamp the gap ends at.
+	since time.Time
+}
+
+// collectWatchPages follows the watch listing from startURL, collecting the
+// models whose benchmark timestamp is after since, until it reaches one that
+// is not or runs out of pages. Pages are collected before anything is
+// stored: storing the newest models first would advance the benchmark past
+// pages not fetched yet. When it stops at WATCHER.MAX_PAGES_PER_CYCLE while
+// still finding new models, it returns the URL of the next page. On error it
+// returns the models of the pages fetched before.
+func (s *Service) collectWatchPages(ctx context.Context, startURL string, since time.Time, benchmark string) ([]domain.HuggingFaceModel, string, error) {
+	models := make([]domain.HuggingFaceModel, 0)
+	maxPages := max(s.cfg.MaxPagesPerCycle, 1)
+	pageURL := startURL
+	for page := 1; ; page++ {
+		result, err := s.fetchWatchPage(ctx, pageURL)
+		if err != nil {
+			return models, "", fmt.Errorf("failed to fetch page %d from API: %w", page, err)
+		}
+
+		for _, model := range s.dropMissingIDs(result.Models, "Watch Cycle") {
+			if !model.TimestampOf(benchmark).After(since) {
+				log.Println("Watch Cycle: Reached a model that is not new. Stopping check.")
+				return models, "", nil
+			}
+			models = append(models, model)
+		}
+		if result.NextURL == "" {
+			return models, "", nil
+		}
+		if page == maxPages {
+			return models, s.withScopeParams(result.NextURL), nil
+		}
+		pageURL = s.withScopeParams(result.NextURL)
+	}
+}
+
+// fetchWatchPage fetches one page of the watch listing. When the API rate
+// limits it and lets us back in before the next cycle is due, the fetch is
+// retried once after the requested delay.
+func (s *Service) fetchWatchPage(ctx context.Context, url string) (*scraper.ScrapeResult, error) {
+	result, err := s.fetchModels(ctx, url)
+	var rateLimited *scraper.RateLimitError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter < time.Duration(s.cfg.IntervalMinutes)*time.Minute {
+		log.Printf("Watch Cycle: Rate limited, retrying after %s.", rateLimited.RetryAfter)
+		select {
+		case <-time.After(rateLimited.RetryAfter):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		result, err = s.fetchModels(ctx, url)
+	}
+	return result, err
+}
+
+// RescanWatch makes the next watch cycle treat every model of the Hub listing
+// whose benchmark timestamp is after since as new, instead of comparing
+// against the newest stored model, e.g. to re-store recent models after a
+// parsing fix. The cycle still follows at most WATCHER.MAX_PAGES_PER_CYCLE
+// pages, so a zero since re-examines that many; the following cycles only
+// resume past the limit for models newer than the stored ones, never the
+// whole listing. The override is kept in memory and applies to one
+// successful cycle.
+func (s *Service) RescanWatch(since time.Time) {
+	s.watchSince.Store(&since)
+}
+
+// benchmarkField returns the timestamp field the watch cycle compares.
+func (s *Service) benchmarkField() string {
+	if s.cfg.BenchmarkField == "" {
+		return config.BenchmarkFieldLastModified
+	}
+	return s.cfg.BenchmarkField
+}
+
+// backfillShard returns the name under which this instance saves its backfill cursor.
+func (s *Service) backfillShard() string {
+	if s.cfg.BackfillShard == "" {
+		return domain.DefaultCursorShard
+	}
+	return s.cfg.BackfillShard
+}
+
+// fetchRetryDelay returns how long to wait before retrying a failed page
+// fetch: the delay the API asked for when it rate-limited us, 10s otherwise.
+func fetchRetryDelay(err error) time.Duration {
+	var rateLimited *scraper.RateLimitError
+	if errors.As(err, &rateLimited) {
+		return rateLimited.RetryAfter
+	}
+	return 10 * time.Second
+}
+
+// isGoneCursor reports whether err means the requested page no longer exists,
+// as happens when the Hub invalidates a pagination cursor, or can never be
+// fetched because it points outside the scraper's host allowlist.
+func isGoneCursor(err error) bool {
+	if errors.Is(err, scraper.ErrHostNotAllowed) {
+		return true
+	}
+	var statusErr *scraper.StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone)
+}
+
+// applyRateLimits switches the scraper to the rate limits configured for the given mode.
+func (s *Service) applyRateLimits(mode domain.ServiceStatus) {
+	rps, burst := s.scraperCfg.RequestsPerSecond, s.scraperCfg.BurstLimit
+	if mode == domain.StatusNeedsBackfill {
+		if s.scraperCfg.BackfillRequestsPerSecond > 0 {
+			rps = s.scraperCfg.BackfillRequestsPerSecond
+		}
+		if s.scraperCfg.BackfillBurstLimit > 0 {
+			burst = s.scraperCfg.BackfillBurstLimit
+		}
+	}
+	log.Printf("Applying %s rate limits: %d requests/s, burst %d", mode, rps, burst)
+	s.scraper.SetLimit(float64(rps), burst)
+}
+
+// withScopeParams adds the configured Hugging Face "filter" and "search"
+// parameters to an API URL, so every page of a scrape stays within the
+// configured slice of the Hub. Parameters already on the URL are kept as-is.
+func (s *Service) withScopeParams(rawURL string) string {
+	if rawURL == "" || (s.scraperCfg.Filter == "" && s.scraperCfg.Search == "") {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	if s.scraperCfg.Filter != "" && !query.Has("filter") {
+		query.Set("filter", s.scraperCfg.Filter)
+	}
+	if s.scraperCfg.Search != "" && !query.Has("search") {
+		query.Set("search", s.scraperCfg.Search)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 // GetModelByID provides a simple data-retrieval method for the Delivery Layer.
+// Results are served from the read cache when possible. In compact mode, or
+// when the stored tags were truncated, the full record is fetched from the
+// Hub within SCRAPER.ON_DEMAND_REQUESTS_PER_SECOND, falling back to the
+// stored subset.
+// With ServeStale enabled, a database error returns the last cached copy of
+// the model, if any, together with a *StaleError.
 func (s *Service) GetModelByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
-	return s.modelStorage.FindByID(ctx, id)
+	if model, ok := s.cache.get(id); ok {
+		return s.withBadge(model), nil
+	}
+
+	model, err := s.modelStorage.FindByID(ctx, id)
+	if err != nil && s.cacheCfg.ServeStale {
+		if stale, age, ok := s.cache.getStale(id); ok {
+			log.Printf("Could not read model %s, serving a stale cached copy: %v", id, err)
+			return s.withBadge(stale), &StaleError{Age: age, Err: err}
+		}
+	}
+	if err != nil || model == nil {
+		return model, err
+	}
+
+	if s.ingestCfg.CompactDocuments || model.TagsTruncated {
+		// The fetch has its own rate limit, so reads can't slow the scrape.
+		full, err := s.scraper.FetchModelOnDemand(ctx, id)
+		if err != nil {
+			if !errors.Is(err, scraper.ErrOnDemandUnavailable) {
+				log.Printf("Could not fetch full record for %s, serving stored document: %v", id, err)
+			}
+			// The stored subset is not cached, so the next read tries again.
+			return s.withBadge(model), nil
+		}
+		deriveTagFields(full)
+		model = full
+	}
+
+	s.cache.set(*model)
+	return s.withBadge(model), nil
+}
+
+// withBadge sets the badges of a single model read for a response.
+func (s *Service) withBadge(model *domain.HuggingFaceModel) *domain.HuggingFaceModel {
+	badged := s.withBadges([]domain.HuggingFaceModel{*model})
+	return &badged[0]
+}
+
+// GetModelsByIDs returns the stored models with the given IDs, leaving out
+// the ones that are not stored. It reads the database directly, bypassing the
+// read cache and the compact-mode fetch from the Hub.
+func (s *Service) GetModelsByIDs(ctx context.Context, ids []string) ([]domain.HuggingFaceModel, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	models, err := s.modelStorage.FindByIDs(ctx, ids)
+	return s.withBadges(models), err
+}
+
+// WarmCache pre-loads the most-liked models into the read cache so the most
+// likely requests are hot right after startup. It is meant to run in the background.
+func (s *Service) WarmCache(ctx context.Context) {
+	if s.cacheCfg.WarmupCount <= 0 || s.cacheCfg.MaxEntries <= 0 {
+		return
+	}
+
+	models, _, err := s.modelStorage.SearchModels(ctx, SearchOptions{
+		SortBy:    "likes",
+		SortOrder: -1,
+		Limit:     int64(s.cacheCfg.WarmupCount),
+		Page:      1,
+	})
+	if err != nil {
+		log.Printf("Cache warmup failed: %v", err)
+		return
+	}
+
+	for _, model := range models {
+		s.cache.set(model)
+	}
+	log.Printf("Cache warmup: pre-loaded %d models.", len(models))
+}
+
+// DeleteModelsByAuthor purges every stored model of an author, e.g. a spam or defunct organization.
+func (s *Service) DeleteModelsByAuthor(ctx context.Context, author string) (int64, error) {
+	deleted, err := s.modelStorage.DeleteByAuthor(ctx, author)
+	if err != nil {
+		return 0, err
+	}
+	s.cache.clear()
+	log.Printf("Deleted %d models by author %s", deleted, author)
+	return deleted, nil
+}
+
+// GetRelatedModels returns up to limit models that share the most tags with
+// the given model. It returns nil if the model itself is unknown, and an empty
+// list if the model has no tags to compare on.
+func (s *Service) GetRelatedModels(ctx context.Context, id string, limit int) ([]domain.HuggingFaceModel, error) {
+	model, err := s.modelStorage.FindByID(ctx, id)
+	if err != nil || model == nil {
+		return nil, err
+	}
+	if len(model.Tags) == 0 {
+		return []domain.HuggingFaceModel{}, nil
+	}
+	related, err := s.modelStorage.FindRelated(ctx, *model, limit)
+	return s.withBadges(related), err
+}
+
+// GetRandomModels returns a random sample of up to n stored models.
+func (s *Service) GetRandomModels(ctx context.Context, n int) ([]domain.HuggingFaceModel, error) {
+	models, err := s.modelStorage.SampleModels(ctx, n)
+	return s.withBadges(models), err
+}
+
+// GetSummary returns aggregate collection statistics. The aggregation is
+// expensive, so its result is cached for summaryCacheTTL. With stats
+// snapshots enabled, the latest snapshot is served instead of computing it.
+func (s *Service) GetSummary(ctx context.Context) (*domain.StatsSummary, error) {
+	if summary, ok := s.summary.get(); ok {
+		return summary, nil
+	}
+	if s.cfg.StatsSnapshotMinutes > 0 {
+		snapshot, err := s.modelStorage.LatestStatsSnapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			s.summary.set(&snapshot.Summary)
+			return &snapshot.Summary, nil
+		}
+	}
+
+	summary, err := s.modelStorage.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.summary.set(summary)
+	return summary, nil
+}
+
+// GetStatus reports the persisted service mode together with live runtime state.
+func (s *Service) GetStatus(ctx context.Context) (*domain.StatusReport, error) {
+	statusDoc, err := s.statusStorage.GetStatusDocument(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastInterval, averageInterval := s.cycles.intervals()
+	return &domain.StatusReport{
+		Mode:             statusDoc.Status,
+		UpdatedAt:        statusDoc.UpdatedAt,
+		BackfillCursor:   statusDoc.Cursor(s.backfillShard()),
+		BackfillCursors:  statusDoc.BackfillCursors,
+		ScraperBreaker:   string(s.scraper.BreakerState()),
+		SkippedUnchanged: s.skippedUnchanged.Load(),
+		IndexBuilding:    s.indexBuild.isPaused(),
+		DroppedEvents:    s.broker.Dropped(),
+		WatchInterval: domain.WatchInterval{
+			ConfiguredSeconds: float64(s.cfg.IntervalMinutes * 60),
+			LastSeconds:       lastInterval.Seconds(),
+			AverageSeconds:    averageInterval.Seconds(),
+		},
+	}, nil
+}
+
+// GetBrokerTopics lists the event topics that currently have subscribers,
+// with their subscriber counts.
+func (s *Service) GetBrokerTopics() []domain.TopicSubscribers {
+	topics := s.broker.Topics()
+	counts := make([]domain.TopicSubscribers, 0, len(topics))
+	for _, topic := range topics {
+		counts = append(counts, domain.TopicSubscribers{Topic: topic, Subscribers: s.broker.SubscriberCount(topic)})
+	}
+	return counts
 }
 
 // SearchModels provides a search and sort capability for the Delivery Layer.
+// Results are ordered by the sort field with the model ID as a tiebreaker, so
+// the same query over an unchanged dataset always yields the same page.
 func (s *Service) SearchModels(ctx context.Context, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	if err := validateQuery(opts); err != nil {
+		return nil, 0, err
+	}
+	models, total, err := s.modelStorage.SearchModels(ctx, withSearchDefaults(opts))
+	return s.withBadges(models), total, err
+}
+
+// ErrInvalidSearchPattern is returned by SearchModels for a query that is not
+// a valid regular expression.
+var ErrInvalidSearchPattern = errors.New("invalid search pattern")
+
+// validateQuery rejects regex queries that don't compile, so they fail with
+// ErrInvalidSearchPattern instead of an opaque database error. Go's syntax is
+// close enough to MongoDB's to catch the common mistakes, like an unclosed "[".
+func validateQuery(opts SearchOptions) error {
+	if opts.Query == "" || opts.Literal {
+		return nil
+	}
+	if _, err := regexp.Compile(opts.Query); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSearchPattern, err)
+	}
+	return nil
+}
+
+// GetAuthorCounts returns a page of the authors of the models matching the
+// search filters of opts with their number of models, most first, and the
+// total number of authors. It reads no model documents.
+func (s *Service) GetAuthorCounts(ctx context.Context, opts SearchOptions) ([]domain.AuthorCount, int64, error) {
+	if err := validateQuery(opts); err != nil {
+		return nil, 0, err
+	}
+	return s.modelStorage.CountByAuthor(ctx, withSearchDefaults(opts))
+}
+
+// GetDuplicates returns a page of the SHAs shared by several of the models
+// matching the search filters of opts, for data-quality checks, and the
+// total number of such SHAs.
+func (s *Service) GetDuplicates(ctx context.Context, opts SearchOptions) ([]domain.DuplicateGroup, int64, error) {
+	if err := validateQuery(opts); err != nil {
+		return nil, 0, err
+	}
+	return s.modelStorage.FindDuplicateSHAs(ctx, withSearchDefaults(opts))
+}
+
+// GetModelsByTask returns a page of the models for one pipeline tag and the
+// total number of models for it, for browsing by task.
+func (s *Service) GetModelsByTask(ctx context.Context, tag string, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	if err := validateQuery(opts); err != nil {
+		return nil, 0, err
+	}
+	models, total, err := s.modelStorage.GetByPipelineTag(ctx, tag, withSearchDefaults(opts))
+	return s.withBadges(models), total, err
+}
+
+// GetGatedModels returns a page of the gated models, whether approval is
+// automatic or manual, or of the ungated ones, and their total number.
+func (s *Service) GetGatedModels(ctx context.Context, gated bool, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	models, total, err := s.modelStorage.FindByGated(ctx, gated, withSearchDefaults(opts))
+	return s.withBadges(models), total, err
+}
+
+// GetRecentlyModified returns a page of the models modified within the given
+// window before now, most recent first, and their total number.
+func (s *Service) GetRecentlyModified(ctx context.Context, window time.Duration, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	models, total, err := s.modelStorage.FindModifiedSince(ctx, s.now().Add(-window), withSearchDefaults(opts))
+	return s.withBadges(models), total, err
+}
+
+// withSearchDefaults fills in the sort and paging options left unset.
+func withSearchDefaults(opts SearchOptions) SearchOptions {
 	// Add default sorting if not provided
 	if opts.SortBy == "" {
 		opts.SortBy = "likes"
@@ -222,5 +884,5 @@ func (s *Service) SearchModels(ctx context.Context, opts SearchOptions) ([]domai
 	if opts.Page == 0 {
 		opts.Page = 1
 	}
-	return s.modelStorage.SearchModels(ctx, opts)
+	return opts
 }
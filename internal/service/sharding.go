@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"hf-scraper/internal/domain"
+)
+
+// hashedIDShardKey spreads the unordered backfill upserts evenly over the
+// shards while keeping single-model lookups targeted at one shard.
+const hashedIDShardKey = `{ "_id": "hashed" }`
+
+// queryIndexes are the indexes backing the sorts used by search and watch,
+// keyed by MongoDB's default index name.
+var queryIndexes = []struct{ name, keys string }{
+	{"likes_-1__id_-1", `{ "likes": -1, "_id": -1 }`},
+	{"downloads_-1__id_-1", `{ "downloads": -1, "_id": -1 }`},
+	{"lastModified_-1__id_-1", `{ "lastModified": -1, "_id": -1 }`},
+}
+
+// GetShardRecommendation analyzes the size of the model collection and
+// suggests whether and how to shard it.
+func (s *Service) GetShardRecommendation(ctx context.Context) (*domain.ShardRecommendation, error) {
+	stats, err := s.modelStorage.CollectionStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recommendation := RecommendSharding(*stats, int64(s.dbCfg.ShardingThresholdGB)<<30)
+	return &recommendation, nil
+}
+
+// RecommendSharding derives a sharding and indexing recommendation from
+// collection stats. Sharding is suggested once the data size reaches
+// thresholdBytes; the index advice applies to collections of any size.
+func RecommendSharding(stats domain.CollectionStats, thresholdBytes int64) domain.ShardRecommendation {
+	rec := domain.ShardRecommendation{
+		Stats:          stats,
+		MissingIndexes: []string{},
+		Notes:          []string{},
+	}
+
+	for _, index := range queryIndexes {
+		if _, ok := stats.IndexSizes[index.name]; !ok {
+			rec.MissingIndexes = append(rec.MissingIndexes, index.keys)
+		}
+	}
+	if len(rec.MissingIndexes) > 0 {
+		rec.Notes = append(rec.Notes, "Sorted searches and the watch cycle scan the whole collection without the missing indexes.")
+	}
+
+	switch {
+	case stats.Sharded:
+		rec.Notes = append(rec.Notes, "The collection is already sharded.")
+	case thresholdBytes > 0 && stats.SizeBytes >= thresholdBytes:
+		rec.ShouldShard = true
+		rec.ShardKey = hashedIDShardKey
+		rec.Notes = append(rec.Notes,
+			fmt.Sprintf("The collection holds %d bytes, at or above the %d byte threshold.", stats.SizeBytes, thresholdBytes),
+			"A hashed _id key spreads backfill writes evenly and keeps model lookups on a single shard.",
+			"ID regex searches and sorts by likes, downloads or lastModified will query every shard; the sort indexes keep each shard's part cheap.",
+		)
+	default:
+		rec.Notes = append(rec.Notes, "The collection is below the sharding threshold; a single replica set is sufficient.")
+	}
+	return rec
+}
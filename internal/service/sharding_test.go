@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"testing"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+func TestRecommendSharding(t *testing.T) {
+	const gb = int64(1) << 30
+	allIndexes := map[string]int64{"_id_": 1, "likes_-1__id_-1": 1, "downloads_-1__id_-1": 1, "lastModified_-1__id_-1": 1}
+
+	for _, tc := range []struct {
+		name        string
+		stats       domain.CollectionStats
+		shouldShard bool
+		missing     int
+	}{
+		{name: "small and indexed", stats: domain.CollectionStats{SizeBytes: gb, IndexSizes: allIndexes}},
+		{name: "at the threshold", stats: domain.CollectionStats{SizeBytes: 50 * gb, IndexSizes: allIndexes}, shouldShard: true},
+		{name: "already sharded", stats: domain.CollectionStats{SizeBytes: 80 * gb, IndexSizes: allIndexes, Sharded: true}},
+		{name: "missing sort indexes", stats: domain.CollectionStats{SizeBytes: gb, IndexSizes: map[string]int64{"_id_": 1, "likes_-1__id_-1": 1}}, missing: 2},
+	} {
+		rec := service.RecommendSharding(tc.stats, 50*gb)
+		if rec.ShouldShard != tc.shouldShard {
+			t.Errorf("%s: ShouldShard = %t, want %t", tc.name, rec.ShouldShard, tc.shouldShard)
+		}
+		if tc.shouldShard && rec.ShardKey == "" {
+			t.Errorf("%s: no shard key suggested", tc.name)
+		}
+		if !tc.shouldShard && rec.ShardKey != "" {
+			t.Errorf("%s: shard key %s suggested without sharding", tc.name, rec.ShardKey)
+		}
+		if len(rec.MissingIndexes) != tc.missing {
+			t.Errorf("%s: missing indexes = %v, want %d", tc.name, rec.MissingIndexes, tc.missing)
+		}
+	}
+
+	if rec := service.RecommendSharding(domain.CollectionStats{SizeBytes: 500 * gb}, 0); rec.ShouldShard {
+		t.Error("sharding was recommended with the threshold disabled")
+	}
+}
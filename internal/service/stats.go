@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// RunStatsSnapshots computes the stats summary right away and then every
+// WATCHER.STATS_SNAPSHOT_MINUTES, storing each result as a timestamped
+// snapshot, until ctx is cancelled. It is meant to run in the background.
+func (s *Service) RunStatsSnapshots(ctx context.Context) {
+	if s.cfg.StatsSnapshotMinutes <= 0 {
+		return
+	}
+	log.Printf("Stats: taking a stats snapshot every %d minutes.", s.cfg.StatsSnapshotMinutes)
+	ticker := time.NewTicker(time.Duration(s.cfg.StatsSnapshotMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		s.takeStatsSnapshot(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// takeStatsSnapshot computes the stats summary and stores it as a snapshot.
+func (s *Service) takeStatsSnapshot(ctx context.Context) {
+	summary, err := s.modelStorage.Summary(ctx)
+	if err != nil {
+		log.Printf("Stats Error: could not compute the stats summary: %v", err)
+		return
+	}
+	snapshot := domain.StatsSnapshot{TakenAt: s.now(), Summary: *summary}
+	if err := s.modelStorage.SaveStatsSnapshot(ctx, snapshot); err != nil {
+		log.Printf("Stats Error: could not store the stats snapshot: %v", err)
+		return
+	}
+	s.summary.set(summary)
+}
@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// snapshotStorage records the stats snapshots saved through it.
+type snapshotStorage struct {
+	service.ModelStorage
+	saved []domain.StatsSnapshot
+}
+
+func (s *snapshotStorage) SaveStatsSnapshot(_ context.Context, snapshot domain.StatsSnapshot) error {
+	s.saved = append(s.saved, snapshot)
+	return nil
+}
+
+func TestStatsSnapshotIsWrittenWithTheSummary(t *testing.T) {
+	env := newTestEnv(t)
+	gated := model("a/gated", 30)
+	gated.Gated, gated.Downloads, gated.PipelineTag = domain.GatedStatusAuto, 100, "text-generation"
+	open := model("b/open", 45)
+	open.Downloads, open.PipelineTag = 20, "text-generation"
+	env.seed(t, gated, open, model("b/untagged", 10))
+	store := &snapshotStorage{ModelStorage: env.store}
+	env.store = store
+	env.watcher.StatsSnapshotMinutes = 60
+	svc := env.newService(service.WithClock(func() time.Time { return at(120) }))
+
+	// A cancelled context stops the job after its first snapshot.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.RunStatsSnapshots(ctx)
+
+	if len(store.saved) != 1 {
+		t.Fatalf("saved %d snapshots, want 1", len(store.saved))
+	}
+	snapshot := store.saved[0]
+	if !snapshot.TakenAt.Equal(at(120)) {
+		t.Errorf("TakenAt = %s, want the clock's %s", snapshot.TakenAt, at(120))
+	}
+	summary := snapshot.Summary
+	if summary.TotalModels != 3 || summary.GatedModels != 1 || summary.TotalDownloads != 120 || !summary.NewestLastModified.Equal(at(45)) {
+		t.Errorf("summary = %+v, want 3 models, 1 gated, 120 downloads, newest at %s", summary, at(45))
+	}
+	if want := []domain.TagCount{{Tag: "text-generation", Count: 2}}; !slices.Equal(summary.TopPipelineTags, want) {
+		t.Errorf("top pipeline tags = %v, want %v", summary.TopPipelineTags, want)
+	}
+}
@@ -3,17 +3,56 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"hf-scraper/internal/domain"
 )
 
+// ErrNotFound is returned by storage lookups that match no document where a
+// nil result could be mistaken for a failed read, such as FindExtremeBy.
+var ErrNotFound = errors.New("no matching document")
+
 // SearchOptions holds parameters for searching and sorting models.
 type SearchOptions struct {
-	Query     string
-	SortBy    string // e.g., "likes", "downloads", "lastModified"
-	SortOrder int    // 1 for ascending, -1 for descending
-	Limit     int64
-	Page      int64
+	Query         string
+	CaseSensitive bool      // When true, Query is matched case-sensitively
+	Literal       bool      // When true, Query is matched as plain text rather than a regex
+	Relevance     bool      // When true, exact then prefix ID matches of Query rank first
+	License       string    // Only match models with this license, e.g. "mit"
+	Library       string    // Only match models built with this library, e.g. "transformers"
+	Language      string    // Only match models tagged with this language, e.g. "en"
+	PipelineTag   string    // Only match models for this task, e.g. "text-generation"
+	ModifiedSince time.Time // Only match models modified at or after this time, if set
+	Gated         *bool     // Only match gated (any approval kind) or ungated models, if set
+	SortBy        string    // e.g., "likes", "downloads", "lastModified"
+	SortOrder     int       // 1 for ascending, -1 for descending
+	Limit         int64
+	Page          int64
+}
+
+// UpsertResult tells what an upsert did to the stored document.
+type UpsertResult int
+
+const (
+	// UpsertInserted means no document with the model's ID existed.
+	UpsertInserted UpsertResult = iota
+	// UpsertUpdated means an existing document was changed.
+	UpsertUpdated
+	// UpsertUnchanged means the existing document already matched the model.
+	UpsertUnchanged
+)
+
+// PartialWriteError is returned by BulkUpsert when the database rejected some
+// models of a batch but wrote the others. Failed maps each rejected model ID
+// to the reason.
+type PartialWriteError struct {
+	Failed map[string]string
+}
+
+func (e *PartialWriteError) Error() string {
+	return fmt.Sprintf("%d models of the batch could not be written", len(e.Failed))
 }
 
 // ModelStorage defines the interface for persisting HuggingFaceModel data.
@@ -21,22 +60,142 @@ type ModelStorage interface {
 	// Upsert inserts a new model or updates an existing one, identified by its ID.
 	Upsert(ctx context.Context, model domain.HuggingFaceModel) error
 
-	// BulkUpsert efficiently inserts or updates multiple models in a single operation.
+	// UpsertWithResult is Upsert, also reporting whether the model was
+	// inserted, updated or already stored unchanged.
+	UpsertWithResult(ctx context.Context, model domain.HuggingFaceModel) (UpsertResult, error)
+
+	// BulkUpsert efficiently inserts or updates multiple models in a single
+	// operation. When only some models are rejected, the others are still
+	// written and a *PartialWriteError names the rejected ones.
 	BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error
 
 	// FindByID retrieves a single model by its unique ID.
 	FindByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error)
 
-	// FindMostRecentlyModified finds the model with the latest `lastModified` timestamp.
-	// This is crucial for the "Watch Mode" logic.
+	// FindByIDs retrieves the stored models with the given IDs. IDs that are not
+	// stored, or soft-deleted, are silently left out of the result.
+	FindByIDs(ctx context.Context, ids []string) ([]domain.HuggingFaceModel, error)
+
+	// FindMostRecentlyModified finds the model with the latest `lastModified` timestamp,
+	// ignoring soft-deleted models. This is crucial for the "Watch Mode" logic.
+	// It returns ErrNotFound when there are no live models.
 	FindMostRecentlyModified(ctx context.Context) (*domain.HuggingFaceModel, error)
 
+	// FindExtremeBy finds the model with the lowest (order 1) or highest
+	// (order -1) value of a benchmark field, "lastModified" or "createdAt",
+	// among the models that are not soft-deleted. Other fields are rejected
+	// with an error. It returns ErrNotFound when there are no live models, so
+	// any other error means the read itself failed.
+	FindExtremeBy(ctx context.Context, field string, order int) (*domain.HuggingFaceModel, error)
+
 	SearchModels(ctx context.Context, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+
+	// GetByPipelineTag returns a page of the models for one task along with
+	// the total number of models for it. An unknown tag yields no models.
+	GetByPipelineTag(ctx context.Context, tag string, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+
+	// RecordSnapshots appends snapshots to the model history.
+	RecordSnapshots(ctx context.Context, snapshots []domain.ModelSnapshot) error
+
+	// NearestSnapshot returns the snapshot of a model scraped closest to at,
+	// before or after it, or nil if the model has no history.
+	NearestSnapshot(ctx context.Context, id string, at time.Time) (*domain.ModelSnapshot, error)
+
+	// CountByAuthor returns one page of the number of models per author among
+	// those matching the filters of opts, most models first with ties ordered
+	// by author, along with the number of authors. Models without an author
+	// are left out; the sort options of opts are ignored.
+	CountByAuthor(ctx context.Context, opts SearchOptions) ([]domain.AuthorCount, int64, error)
+
+	// FindDuplicateSHAs returns one page of the SHAs shared by more than one
+	// of the models matching the filters of opts, with the sorted IDs of the
+	// models sharing each, largest groups first with ties ordered by SHA,
+	// along with the number of such SHAs. Models without a SHA are left out;
+	// the sort options of opts are ignored.
+	FindDuplicateSHAs(ctx context.Context, opts SearchOptions) ([]domain.DuplicateGroup, int64, error)
+
+	// SnapshotTrend sums field, "downloads" or "likes", over the last
+	// snapshot of each model per interval bucket ("hour", "day", "week" or
+	// "month", in UTC), returning the buckets oldest first.
+	SnapshotTrend(ctx context.Context, field, interval string) ([]domain.TrendPoint, error)
+
+	// SaveStatsSnapshot appends a stats snapshot.
+	SaveStatsSnapshot(ctx context.Context, snapshot domain.StatsSnapshot) error
+
+	// LatestStatsSnapshot returns the most recently taken stats snapshot, or
+	// nil if there is none.
+	LatestStatsSnapshot(ctx context.Context) (*domain.StatsSnapshot, error)
+
+	// CountByFirstSeen returns the number of models first stored in each
+	// interval bucket ("hour", "day", "week" or "month", in UTC), oldest
+	// first. Models without a first-seen time or soft-deleted are left out.
+	CountByFirstSeen(ctx context.Context, interval string) ([]domain.TimeCount, error)
+
+	// ValueAtPercentile returns the value of field, "downloads" or "likes",
+	// that the given percentile of the models that are not soft-deleted stay
+	// at or below: the value at rank PercentileRank from the top. It returns
+	// ErrNotFound when there are no live models.
+	ValueAtPercentile(ctx context.Context, field string, percentile float64) (int64, error)
+
+	// FindByGated returns a page of the gated models (auto, manual or true)
+	// or of the ungated ones, along with their total number.
+	FindByGated(ctx context.Context, gated bool, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+
+	// FindModifiedSince returns a page of the models modified at or after
+	// since, most recent first, along with their total number.
+	FindModifiedSince(ctx context.Context, since time.Time, opts SearchOptions) ([]domain.HuggingFaceModel, int64, error)
+
+	// SampleModels returns up to n randomly chosen models that are not deleted.
+	SampleModels(ctx context.Context, n int) ([]domain.HuggingFaceModel, error)
+
+	// ListIDs returns up to limit stored model IDs greater than afterID in
+	// ascending order, so callers can walk the whole collection in batches.
+	ListIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+
+	// MarkDeleted soft-deletes a model by setting its DeletedAt timestamp. A
+	// model that is already deleted keeps its timestamp, so the retention
+	// period of PurgeDeleted counts from the first time it went missing.
+	MarkDeleted(ctx context.Context, id string, at time.Time) error
+
+	// PurgeDeleted hard-deletes the models soft-deleted before the given time
+	// and returns how many were removed.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// DeleteByAuthor removes every model published by the given author and
+	// returns the number of deleted documents.
+	DeleteByAuthor(ctx context.Context, author string) (int64, error)
+
+	// DistinctAuthors returns every author with at least one stored model.
+	DistinctAuthors(ctx context.Context) ([]string, error)
+
+	// FindRelated returns up to limit other models ranked by how many tags they
+	// share with model, with a matching pipeline tag counting as one more.
+	// Soft-deleted models are left out.
+	FindRelated(ctx context.Context, model domain.HuggingFaceModel, limit int) ([]domain.HuggingFaceModel, error)
+
+	// EnsureIndexes creates the indexes the queries rely on, if missing. On a
+	// large collection this can take a long time.
+	EnsureIndexes(ctx context.Context) error
+
+	// NormalizeMissingFields gives stored models lacking a sortable field, or
+	// holding null in it, the zero value that new upserts write, so sorts
+	// order them consistently. It returns the number of updated fields.
+	NormalizeMissingFields(ctx context.Context) (int64, error)
+
+	// CollectionStats reports the size of the model collection and its indexes.
+	CollectionStats(ctx context.Context) (*domain.CollectionStats, error)
+
+	// Summary computes aggregate counts over the models that are not
+	// soft-deleted in a single round trip.
+	Summary(ctx context.Context) (*domain.StatsSummary, error)
 }
 
 // StatusStorage defines the interface for persisting the service's operational state.
 type StatusStorage interface {
 	GetStatusDocument(ctx context.Context) (*domain.StatusDocument, error)
 	UpdateStatus(ctx context.Context, status domain.ServiceStatus) error
-	UpdateBackfillCursor(ctx context.Context, cursorURL string) error
+	// UpdateBackfillCursor saves the cursor of one backfill shard.
+	UpdateBackfillCursor(ctx context.Context, shard, cursorURL string) error
+	// ClearBackfillCursor removes the cursor of a shard that finished its backfill.
+	ClearBackfillCursor(ctx context.Context, shard string) error
 }
@@ -0,0 +1,33 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"hf-scraper/internal/domain"
+)
+
+func TestStoredTagsAreCappedAndFlagged(t *testing.T) {
+	env := newTestEnv(t)
+	env.ingest.MaxTags = 2
+	many := model("a/many", 2)
+	many.Tags = []string{"pytorch", "text-generation", "en", "license:mit"}
+	few := model("a/few", 1)
+	few.Tags = []string{"pytorch", "en"}
+	env.hub.setPages([]domain.HuggingFaceModel{many, few})
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+
+	stored := env.stored(t, "a/many")
+	if want := []string{"pytorch", "text-generation"}; !slices.Equal(stored.Tags, want) || !stored.TagsTruncated {
+		t.Errorf("a/many: tags %q, truncated %v, want %q flagged as truncated", stored.Tags, stored.TagsTruncated, want)
+	}
+	if stored.License != "mit" || !slices.Equal(stored.Languages, []string{"en"}) {
+		t.Errorf("a/many: license %q, languages %q, want them derived from the full tag list", stored.License, stored.Languages)
+	}
+	if stored := env.stored(t, "a/few"); len(stored.Tags) != 2 || stored.TagsTruncated {
+		t.Errorf("a/few: tags %q, truncated %v, want both tags and no flag", stored.Tags, stored.TagsTruncated)
+	}
+}
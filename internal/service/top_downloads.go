@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"hf-scraper/internal/domain"
+)
+
+// refreshTopDownloads re-fetches the most-downloaded models on the Hub and
+// stores their current records, when WATCHER.REFRESH_TOP_DOWNLOADS is on.
+// The regular watch cycle only sees models whose benchmark timestamp moved,
+// so download counts of popular but unmodified models would otherwise go
+// stale. A failure ends the pass; the next cycle starts over.
+func (s *Service) refreshTopDownloads(ctx context.Context) {
+	if !s.cfg.RefreshTopDownloads {
+		return
+	}
+	listURL := s.withScopeParams(fmt.Sprintf("%s/api/models?sort=downloads&direction=-1&limit=%d", s.scraperCfg.BaseURL, s.cfg.TopDownloadsCount))
+	result, err := s.fetchModels(ctx, listURL)
+	if err != nil {
+		log.Printf("Top Downloads Error: failed to list the most-downloaded models: %v", err)
+		return
+	}
+
+	listed := s.dropMissingIDs(result.Models, "Top Downloads")
+	listed = listed[:min(len(listed), s.cfg.TopDownloadsCount)]
+	updated := 0
+	for _, entry := range listed {
+		// Each fetch waits on the scraper's rate limiter, which it shares with the watch cycle.
+		model, err := s.scraper.FetchModelByID(ctx, entry.ID)
+		if err != nil {
+			log.Printf("Top Downloads Error: could not fetch %s: %v", entry.ID, err)
+			return
+		}
+		result, err := s.upsertWithResult(ctx, s.prepareModels([]domain.HuggingFaceModel{*model})[0])
+		if err != nil {
+			log.Printf("Top Downloads Error: could not refresh %s: %v", entry.ID, err)
+			return
+		}
+		if result != UpsertUnchanged {
+			updated++
+		}
+	}
+	log.Printf("Top Downloads: refreshed %d models, %d changed.", len(listed), updated)
+}
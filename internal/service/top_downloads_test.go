@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"hf-scraper/internal/domain"
+)
+
+func TestTopDownloadsAreRefreshedWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		env := newTestEnv(t)
+		env.watcher.RefreshTopDownloads = enabled
+		env.watcher.TopDownloadsCount = 2
+		env.seed(t, model("a/first", 0), model("b/second", 0), model("c/third", 0))
+		env.hub.setPages([]domain.HuggingFaceModel{model("a/first", 0), model("b/second", 0), model("c/third", 0)})
+		var surging []domain.HuggingFaceModel
+		for i, id := range []string{"a/first", "b/second", "c/third"} {
+			m := model(id, 0)
+			m.Downloads = domain.FlexibleInt(1000 * (3 - i))
+			surging = append(surging, m)
+		}
+		env.hub.setModels(surging...)
+		svc := env.newService()
+
+		svc.RefreshTopDownloads(context.Background())
+
+		want := map[string]domain.FlexibleInt{"a/first": 3000, "b/second": 2000, "c/third": 0}
+		if !enabled {
+			want = map[string]domain.FlexibleInt{"a/first": 0, "b/second": 0, "c/third": 0}
+		}
+		for id, downloads := range want {
+			if got := env.stored(t, id).Downloads; got != downloads {
+				t.Errorf("enabled=%v: %s has %d downloads, want %d", enabled, id, got, downloads)
+			}
+		}
+		listed := env.hub.listRequests()
+		switch {
+		case enabled && (len(listed) != 1 || !strings.Contains(listed[0], "sort=downloads") || !strings.Contains(listed[0], "limit=2")):
+			t.Errorf("enabled: listed %v, want one listing sorted by downloads, limited to 2", listed)
+		case !enabled && len(listed) != 0:
+			t.Errorf("disabled: listed %v", listed)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+)
+
+// pagesRequested counts the listing requests for the page with the given
+// parameter, "" being the first page.
+func pagesRequested(env *testEnv, page string) int {
+	count := 0
+	for _, uri := range env.hub.listRequests() {
+		if u, err := url.Parse(uri); err == nil && u.Query().Get("page") == page {
+			count++
+		}
+	}
+	return count
+}
+
+func notStored(t *testing.T, env *testEnv, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if model, _ := env.memory.FindByID(context.Background(), id); model != nil {
+			t.Errorf("%s was stored", id)
+		}
+	}
+}
+
+func TestWatchCycleFollowsPagesUntilAKnownModel(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/known", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/m-10", 10), model("a/m-9", 9)},
+		[]domain.HuggingFaceModel{model("a/m-8", 8), model("a/m-7", 7)},
+		[]domain.HuggingFaceModel{model("a/m-6", 6), model("a/known", 0)},
+		[]domain.HuggingFaceModel{model("a/m-old", -1)},
+	)
+	svc := env.newService()
+
+	svc.RunWatchCycle(context.Background())
+	for _, id := range []string{"a/m-10", "a/m-9", "a/m-8", "a/m-7", "a/m-6"} {
+		env.stored(t, id)
+	}
+	notStored(t, env, "a/m-old")
+	if got := len(env.hub.listRequests()); got != 3 {
+		t.Errorf("the cycle fetched %d pages, want 3: %v", got, env.hub.listRequests())
+	}
+}
+
+func TestWatchCycleResumesPagesLeftAtTheLimit(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.MaxPagesPerCycle = 2
+	env.seed(t, model("a/known", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/m-10", 10), model("a/m-9", 9)},
+		[]domain.HuggingFaceModel{model("a/m-8", 8), model("a/m-7", 7)},
+		[]domain.HuggingFaceModel{model("a/m-6", 6), model("a/m-5", 5)},
+		[]domain.HuggingFaceModel{model("a/m-4", 4), model("a/known", 0)},
+	)
+	svc := env.newService()
+	ctx := context.Background()
+
+	svc.RunWatchCycle(ctx)
+	for _, id := range []string{"a/m-10", "a/m-9", "a/m-8", "a/m-7"} {
+		env.stored(t, id)
+	}
+	notStored(t, env, "a/m-6", "a/m-5", "a/m-4")
+
+	// The benchmark is now a/m-10, but the next cycle still reaches the
+	// models below the limit.
+	svc.RunWatchCycle(ctx)
+	for _, id := range []string{"a/m-6", "a/m-5", "a/m-4"} {
+		env.stored(t, id)
+	}
+	if got := pagesRequested(env, "2"); got != 1 {
+		t.Errorf("page 3 was fetched %d times, want once", got)
+	}
+
+	// Once caught up, a cycle only reads the first page.
+	before := len(env.hub.listRequests())
+	svc.RunWatchCycle(ctx)
+	if got := env.hub.listRequests()[before:]; !slices.Equal(got, env.hub.listRequests()[:1]) {
+		t.Errorf("the caught-up cycle requested %v, want only the first page", got)
+	}
+}
+
+func TestWatchCycleKeepsResumingAcrossCycles(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.MaxPagesPerCycle = 1
+	env.seed(t, model("a/known", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/m-4", 4)},
+		[]domain.HuggingFaceModel{model("a/m-3", 3)},
+		[]domain.HuggingFaceModel{model("a/m-2", 2)},
+		[]domain.HuggingFaceModel{model("a/m-1", 1), model("a/known", 0)},
+	)
+	svc := env.newService()
+	ctx := context.Background()
+
+	for cycle, id := range []string{"a/m-4", "a/m-3", "a/m-2", "a/m-1"} {
+		svc.RunWatchCycle(ctx)
+		if model, _ := env.memory.FindByID(ctx, id); model == nil {
+			t.Fatalf("%s was not stored after cycle %d", id, cycle+1)
+		}
+	}
+}
+
+func TestWatchCycleDropsFetchedPagesWhenALaterPageFails(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/known", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/m-2", 2)},
+		[]domain.HuggingFaceModel{model("a/m-1", 1), model("a/known", 0)},
+	)
+	env.hub.failWith(func(r *http.Request) int {
+		if r.URL.Query().Get("page") == "1" {
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+	svc := env.newService()
+	ctx := context.Background()
+
+	// Storing a/m-2 alone would move the benchmark past a/m-1.
+	svc.RunWatchCycle(ctx)
+	notStored(t, env, "a/m-2", "a/m-1")
+
+	env.hub.failWith(nil)
+	svc.RunWatchCycle(ctx)
+	env.stored(t, "a/m-2")
+	env.stored(t, "a/m-1")
+}
+
+func TestWatchCycleStopsPagingWhenCancelled(t *testing.T) {
+	env := newTestEnv(t)
+	env.seed(t, model("a/known", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/m-3", 3)},
+		[]domain.HuggingFaceModel{model("a/m-2", 2)},
+		[]domain.HuggingFaceModel{model("a/m-1", 1), model("a/known", 0)},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	env.hub.failWith(func(r *http.Request) int {
+		if r.URL.Query().Get("page") == "1" {
+			cancel()
+		}
+		return 0
+	})
+	svc := env.newService()
+
+	svc.RunWatchCycle(ctx)
+	if got := pagesRequested(env, "2"); got != 0 {
+		t.Errorf("page 3 was fetched %d times after the cancellation, want none", got)
+	}
+	notStored(t, env, "a/m-3", "a/m-2", "a/m-1")
+}
+
+// liked returns model(id, minutes) with the given likes.
+func liked(id string, minutes, likes int) domain.HuggingFaceModel {
+	m := model(id, minutes)
+	m.Likes = domain.FlexibleInt(likes)
+	return m
+}
+
+func TestRescanWithoutSinceStopsAtThePageLimit(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.MaxPagesPerCycle = 1
+	env.seed(t, model("a/p-0", 3), model("a/p-1", 2), model("a/p-2", 1))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{liked("a/p-0", 3, 9)},
+		[]domain.HuggingFaceModel{liked("a/p-1", 2, 9)},
+		[]domain.HuggingFaceModel{liked("a/p-2", 1, 9)},
+	)
+	svc := env.newService()
+	ctx := context.Background()
+
+	svc.RescanWatch(time.Time{})
+	for range 3 {
+		svc.RunWatchCycle(ctx)
+	}
+	if got := env.stored(t, "a/p-0").Likes; got != 9 {
+		t.Errorf("a/p-0 likes = %d, want the rescan to re-store it with 9", got)
+	}
+	for _, id := range []string{"a/p-1", "a/p-2"} {
+		if got := env.stored(t, id).Likes; got != 0 {
+			t.Errorf("%s likes = %d, want it left alone past the page limit", id, got)
+		}
+	}
+	if got := pagesRequested(env, ""); got != 3 || len(env.hub.listRequests()) != 3 {
+		t.Errorf("the cycles requested %v, want only the first page each time", env.hub.listRequests())
+	}
+}
+
+func TestRescanPastThePageLimitResumesOnlyNewModels(t *testing.T) {
+	env := newTestEnv(t)
+	env.watcher.MaxPagesPerCycle = 1
+	env.seed(t, model("a/old", 0))
+	env.hub.setPages(
+		[]domain.HuggingFaceModel{model("a/new-2", 2)},
+		[]domain.HuggingFaceModel{model("a/new-1", 1)},
+		[]domain.HuggingFaceModel{liked("a/old", 0, 9)},
+	)
+	svc := env.newService()
+	ctx := context.Background()
+
+	svc.RescanWatch(time.Time{})
+	for range 3 {
+		svc.RunWatchCycle(ctx)
+	}
+	env.stored(t, "a/new-2")
+	env.stored(t, "a/new-1")
+	if got := env.stored(t, "a/old").Likes; got != 0 {
+		t.Errorf("a/old likes = %d, want the models known before the rescan left alone past the limit", got)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"hf-scraper/internal/domain"
+)
+
+// writeSemaphore bounds the number of concurrent model writes across the
+// backfill, the watcher and the reconciler. A nil semaphore never blocks.
+type writeSemaphore chan struct{}
+
+// newWriteSemaphore returns a semaphore admitting n writers, or nil for no limit.
+func newWriteSemaphore(n int) writeSemaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(writeSemaphore, n)
+}
+
+// acquire waits for a free slot or for ctx to be cancelled.
+func (w writeSemaphore) acquire(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	select {
+	case w <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire.
+func (w writeSemaphore) release() {
+	if w != nil {
+		<-w
+	}
+}
+
+// upsertWithResult writes a single model once a write slot is free, drops its
+// cached copy, records its snapshot and publishes a model event if it was
+// inserted or changed.
+func (s *Service) upsertWithResult(ctx context.Context, model domain.HuggingFaceModel) (UpsertResult, error) {
+	if err := s.writes.acquire(ctx); err != nil {
+		return 0, err
+	}
+	result, err := s.modelStorage.UpsertWithResult(ctx, model)
+	s.writes.release()
+	s.cache.invalidate(model.ID)
+	if err != nil {
+		return 0, err
+	}
+	s.recordHistory(ctx, []domain.HuggingFaceModel{model})
+	switch result {
+	case UpsertInserted:
+		s.broker.Publish(EventModelNew, model.ID)
+	case UpsertUpdated:
+		s.broker.Publish(EventModelUpdated, model.ID)
+	}
+	return result, nil
+}
+
+// storeAndPublish writes models one by one through upsertWithResult, so each
+// gets an accurate model event. It is meant for the handful of models a watch
+// cycle finds; the backfill uses bulkUpsert and publishes nothing. A model
+// that fails to store does not stop the others; it returns the number stored
+// and the failures joined.
+func (s *Service) storeAndPublish(ctx context.Context, models []domain.HuggingFaceModel) (int, error) {
+	stored := 0
+	var errs []error
+	for _, model := range models {
+		if _, err := s.upsertWithResult(ctx, model); err != nil {
+			errs = append(errs, fmt.Errorf("upserting %s: %w", model.ID, err))
+			continue
+		}
+		stored++
+	}
+	return stored, errors.Join(errs...)
+}
+
+// bulkUpsert writes a batch of models once a write slot is free and drops
+// their cached copies. A failed write may still have stored some of them, so
+// they are dropped either way.
+func (s *Service) bulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
+	if err := s.writes.acquire(ctx); err != nil {
+		return err
+	}
+	err := s.modelStorage.BulkUpsert(ctx, models)
+	s.writes.release()
+	ids := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = model.ID
+	}
+	s.cache.invalidate(ids...)
+	return err
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// overlapStorage records the most bulk writes in flight at once. Each write
+// waits briefly for another to start, so unbounded writers always overlap.
+type overlapStorage struct {
+	service.ModelStorage
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	started  chan struct{}
+}
+
+func (s *overlapStorage) BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.started <- struct{}{}:
+	case <-s.started:
+	case <-time.After(200 * time.Millisecond):
+	}
+	return s.ModelStorage.BulkUpsert(ctx, models)
+}
+
+func TestWriteSemaphoreSerializesConcurrentWrites(t *testing.T) {
+	for _, tc := range []struct {
+		maxWrites int
+		wantPeak  int
+	}{
+		{maxWrites: 1, wantPeak: 1},
+		{maxWrites: 0, wantPeak: 2},
+	} {
+		env := newTestEnv(t)
+		env.db.MaxConcurrentWrites = tc.maxWrites
+		overlap := &overlapStorage{ModelStorage: env.store, started: make(chan struct{})}
+		env.store = overlap
+		svc := env.newService()
+
+		var wg sync.WaitGroup
+		for _, id := range []string{"a/one", "a/two"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := svc.ImportModels(context.Background(), strings.NewReader(`{"id":"`+id+`"}`)); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if overlap.peak != tc.wantPeak {
+			t.Errorf("MaxConcurrentWrites=%d: %d writes overlapped, want %d", tc.maxWrites, overlap.peak, tc.wantPeak)
+		}
+		env.stored(t, "a/one")
+		env.stored(t, "a/two")
+	}
+}
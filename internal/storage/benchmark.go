@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+
+	"hf-scraper/internal/config"
+)
+
+// checkCountField rejects fields ValueAtPercentile does not support, for the
+// same reason.
+func checkCountField(field string) error {
+	switch field {
+	case "downloads", "likes":
+		return nil
+	default:
+		return fmt.Errorf("unsupported count field %q", field)
+	}
+}
+
+// checkBenchmarkField rejects fields FindExtremeBy does not support, so
+// arbitrary input never reaches a sort specification.
+func checkBenchmarkField(field string) error {
+	switch field {
+	case config.BenchmarkFieldLastModified, config.BenchmarkFieldCreatedAt:
+		return nil
+	default:
+		return fmt.Errorf("unsupported benchmark field %q", field)
+	}
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/service"
+)
+
+// Backend is the pair of stores the service runs on, together with functions
+// that supervise and release the underlying connection.
+type Backend struct {
+	Models service.ModelStorage
+	Status service.StatusStorage
+	// Supervise health-checks the connection until ctx is cancelled,
+	// reconnecting when it is lost. It returns at once for the memory driver.
+	Supervise func(ctx context.Context)
+	Close     func(ctx context.Context) error
+}
+
+// Open creates the storage backend selected by cfg.Driver. The Mongo driver
+// connects lazily, so Open does not fail when the server is unreachable; the
+// first query does.
+func Open(ctx context.Context, cfg config.DatabaseConfig) (*Backend, error) {
+	switch cfg.Driver {
+	case config.DatabaseDriverMemory:
+		return &Backend{
+			Models:    NewMemoryModelStorage(),
+			Status:    NewMemoryStatusStorage(),
+			Supervise: func(context.Context) {},
+			Close:     func(context.Context) error { return nil },
+		}, nil
+	case config.DatabaseDriverMongo, "":
+		client, err := connectMongo(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		db := client.Database(cfg.Name)
+		supervisor := &mongoSupervisor{
+			cfg:    cfg,
+			client: client,
+			models: NewMongoModelStorage(db, cfg),
+			status: NewMongoStatusStorage(db, cfg.StatusCollection),
+		}
+		return &Backend{
+			Models:    supervisor.models,
+			Status:    supervisor.status,
+			Supervise: supervisor.run,
+			Close:     supervisor.close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"hf-scraper/internal/config"
+)
+
+func TestOpenSelectsBackendByDriver(t *testing.T) {
+	ctx := context.Background()
+
+	memory, err := Open(ctx, config.DatabaseConfig{Driver: config.DatabaseDriverMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := memory.Models.(*MemoryModelStorage); !ok {
+		t.Errorf("memory driver models = %T, want *MemoryModelStorage", memory.Models)
+	}
+	if _, ok := memory.Status.(*MemoryStatusStorage); !ok {
+		t.Errorf("memory driver status = %T, want *MemoryStatusStorage", memory.Status)
+	}
+
+	// The Mongo client connects lazily, so nothing needs to listen here.
+	for _, driver := range []string{config.DatabaseDriverMongo, ""} {
+		mongo, err := Open(ctx, config.DatabaseConfig{Driver: driver, URI: "mongodb://127.0.0.1:1", Name: "test"})
+		if err != nil {
+			t.Fatalf("driver %q: %v", driver, err)
+		}
+		if _, ok := mongo.Models.(*MongoModelStorage); !ok {
+			t.Errorf("driver %q models = %T, want *MongoModelStorage", driver, mongo.Models)
+		}
+		if _, ok := mongo.Status.(*MongoStatusStorage); !ok {
+			t.Errorf("driver %q status = %T, want *MongoStatusStorage", driver, mongo.Status)
+		}
+		mongo.Close(ctx)
+	}
+
+	if _, err := Open(ctx, config.DatabaseConfig{Driver: "sqlite"}); err == nil {
+		t.Error("an unknown driver opened without an error")
+	}
+}
@@ -0,0 +1,694 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"math/rand/v2"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// MemoryModelStorage is an in-memory implementation of the ModelStorage
+// interface for development and tests. Data is lost when the process exits,
+// and every query is a scan, so it is not meant for the full Hub.
+type MemoryModelStorage struct {
+	mu      sync.RWMutex
+	models  map[string]domain.HuggingFaceModel
+	history map[string][]domain.ModelSnapshot
+	stats   []domain.StatsSnapshot
+}
+
+// NewMemoryModelStorage creates an empty in-memory model store.
+func NewMemoryModelStorage() *MemoryModelStorage {
+	return &MemoryModelStorage{
+		models:  make(map[string]domain.HuggingFaceModel),
+		history: make(map[string][]domain.ModelSnapshot),
+	}
+}
+
+// Upsert implements the ModelStorage interface.
+func (s *MemoryModelStorage) Upsert(ctx context.Context, model domain.HuggingFaceModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(model)
+	return nil
+}
+
+// put stores model, keeping the FirstSeen of the stored copy or, for a new
+// model without one, setting it to now. It returns the previous copy.
+// s.mu must be held.
+func (s *MemoryModelStorage) put(model domain.HuggingFaceModel) (existing domain.HuggingFaceModel, found bool) {
+	existing, found = s.models[model.ID]
+	switch {
+	case found && existing.FirstSeen != nil:
+		model.FirstSeen = existing.FirstSeen
+	case model.FirstSeen == nil:
+		now := time.Now()
+		model.FirstSeen = &now
+	}
+	s.models[model.ID] = model
+	return existing, found
+}
+
+// UpsertWithResult implements the ModelStorage interface.
+func (s *MemoryModelStorage) UpsertWithResult(ctx context.Context, model domain.HuggingFaceModel) (service.UpsertResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, found := s.put(model)
+	switch {
+	case !found:
+		return service.UpsertInserted, nil
+	case reflect.DeepEqual(existing, s.models[model.ID]):
+		return service.UpsertUnchanged, nil
+	default:
+		return service.UpsertUpdated, nil
+	}
+}
+
+// BulkUpsert implements the ModelStorage interface.
+func (s *MemoryModelStorage) BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, model := range models {
+		s.put(model)
+	}
+	return nil
+}
+
+// FindByID implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	model, ok := s.models[id]
+	if !ok {
+		return nil, nil
+	}
+	return &model, nil
+}
+
+// FindByIDs implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindByIDs(ctx context.Context, ids []string) ([]domain.HuggingFaceModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var models []domain.HuggingFaceModel
+	for _, id := range ids {
+		if model, ok := s.models[id]; ok && model.DeletedAt == nil {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// FindMostRecentlyModified implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindMostRecentlyModified(ctx context.Context) (*domain.HuggingFaceModel, error) {
+	return s.FindExtremeBy(ctx, config.BenchmarkFieldLastModified, -1)
+}
+
+// FindExtremeBy implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindExtremeBy(ctx context.Context, field string, order int) (*domain.HuggingFaceModel, error) {
+	if err := checkBenchmarkField(field); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var extreme *domain.HuggingFaceModel
+	for _, model := range s.models {
+		if model.DeletedAt != nil {
+			continue
+		}
+		if extreme == nil {
+			extreme = &model
+			continue
+		}
+		c := model.TimestampOf(field).Compare(extreme.TimestampOf(field))
+		if (order < 0 && c > 0) || (order >= 0 && c < 0) {
+			extreme = &model
+		}
+	}
+	if extreme == nil {
+		return nil, service.ErrNotFound
+	}
+	return extreme, nil
+}
+
+// SearchModels implements the ModelStorage interface. The query is matched
+// with Go's regexp package, which is close to, but not exactly, MongoDB's syntax.
+func (s *MemoryModelStorage) SearchModels(ctx context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	matches, err := s.matching(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	slices.SortFunc(matches, func(a, b domain.HuggingFaceModel) int {
+		if opts.Relevance && opts.Query != "" {
+			if c := cmp.Compare(matchScore(b.ID, opts), matchScore(a.ID, opts)); c != 0 {
+				return c
+			}
+		}
+		c := compareByField(a, b, opts.SortBy)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if opts.SortOrder < 0 {
+			return -c
+		}
+		return c
+	})
+
+	total := int64(len(matches))
+	start, end := pageBounds(total, opts)
+	return matches[start:end], total, nil
+}
+
+// CountByAuthor implements the ModelStorage interface.
+func (s *MemoryModelStorage) CountByAuthor(ctx context.Context, opts service.SearchOptions) ([]domain.AuthorCount, int64, error) {
+	matches, err := s.matching(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	perAuthor := make(map[string]int64)
+	for _, model := range matches {
+		if model.Author != "" {
+			perAuthor[model.Author]++
+		}
+	}
+	counts := make([]domain.AuthorCount, 0, len(perAuthor))
+	for author, count := range perAuthor {
+		counts = append(counts, domain.AuthorCount{Author: author, Count: count})
+	}
+	slices.SortFunc(counts, func(a, b domain.AuthorCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Author, b.Author))
+	})
+
+	total := int64(len(counts))
+	start, end := pageBounds(total, opts)
+	return counts[start:end], total, nil
+}
+
+// FindDuplicateSHAs implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindDuplicateSHAs(ctx context.Context, opts service.SearchOptions) ([]domain.DuplicateGroup, int64, error) {
+	matches, err := s.matching(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	perSHA := make(map[string][]string)
+	for _, model := range matches {
+		if model.SHA != "" {
+			perSHA[model.SHA] = append(perSHA[model.SHA], model.ID)
+		}
+	}
+	groups := make([]domain.DuplicateGroup, 0)
+	for sha, ids := range perSHA {
+		if len(ids) > 1 {
+			slices.Sort(ids)
+			groups = append(groups, domain.DuplicateGroup{SHA: sha, IDs: ids, Count: int64(len(ids))})
+		}
+	}
+	slices.SortFunc(groups, func(a, b domain.DuplicateGroup) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.SHA, b.SHA))
+	})
+
+	total := int64(len(groups))
+	start, end := pageBounds(total, opts)
+	return groups[start:end], total, nil
+}
+
+// pageBounds returns the slice bounds of the page of opts among total items.
+// A zero limit selects everything from the page start.
+func pageBounds(total int64, opts service.SearchOptions) (start, end int64) {
+	start = min(max(opts.Page-1, 0)*opts.Limit, total)
+	end = total
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, total)
+	}
+	return start, end
+}
+
+// matching returns the models selected by the filters of opts, unordered.
+func (s *MemoryModelStorage) matching(opts service.SearchOptions) ([]domain.HuggingFaceModel, error) {
+	var pattern *regexp.Regexp
+	if opts.Query != "" {
+		expr := opts.Query
+		if opts.Literal {
+			expr = regexp.QuoteMeta(expr)
+		}
+		if !opts.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		var err error
+		if pattern, err = regexp.Compile(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	var matches []domain.HuggingFaceModel
+	for _, model := range s.models {
+		if model.DeletedAt != nil ||
+			(pattern != nil && !pattern.MatchString(model.ID)) ||
+			(opts.License != "" && model.License != strings.ToLower(opts.License)) ||
+			(opts.Library != "" && model.Library != strings.ToLower(opts.Library)) ||
+			(opts.Language != "" && !slices.Contains(model.Languages, strings.ToLower(opts.Language))) ||
+			(opts.PipelineTag != "" && model.PipelineTag != opts.PipelineTag) ||
+			(!opts.ModifiedSince.IsZero() && model.LastModified.Before(opts.ModifiedSince)) ||
+			(opts.Gated != nil && model.Gated.IsGated() != *opts.Gated) {
+			continue
+		}
+		matches = append(matches, model)
+	}
+	s.mu.RUnlock()
+	return matches, nil
+}
+
+// GetByPipelineTag implements the ModelStorage interface.
+func (s *MemoryModelStorage) GetByPipelineTag(ctx context.Context, tag string, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.PipelineTag = tag
+	return s.SearchModels(ctx, opts)
+}
+
+// RecordSnapshots implements the ModelStorage interface.
+func (s *MemoryModelStorage) RecordSnapshots(ctx context.Context, snapshots []domain.ModelSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, snapshot := range snapshots {
+		s.history[snapshot.ModelID] = append(s.history[snapshot.ModelID], snapshot)
+	}
+	return nil
+}
+
+// NearestSnapshot implements the ModelStorage interface.
+func (s *MemoryModelStorage) NearestSnapshot(ctx context.Context, id string, at time.Time) (*domain.ModelSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var nearest *domain.ModelSnapshot
+	for _, snapshot := range s.history[id] {
+		if nearest == nil || snapshot.ScrapedAt.Sub(at).Abs() < nearest.ScrapedAt.Sub(at).Abs() {
+			nearest = &snapshot
+		}
+	}
+	return nearest, nil
+}
+
+// SnapshotTrend implements the ModelStorage interface.
+func (s *MemoryModelStorage) SnapshotTrend(ctx context.Context, field, interval string) ([]domain.TrendPoint, error) {
+	type modelBucket struct {
+		model  string
+		bucket time.Time
+	}
+	s.mu.RLock()
+	last := make(map[modelBucket]domain.ModelSnapshot)
+	for id, snapshots := range s.history {
+		for _, snapshot := range snapshots {
+			key := modelBucket{id, service.TruncateToInterval(snapshot.ScrapedAt, interval)}
+			if current, ok := last[key]; !ok || !snapshot.ScrapedAt.Before(current.ScrapedAt) {
+				last[key] = snapshot
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	byBucket := make(map[time.Time]*domain.TrendPoint)
+	for key, snapshot := range last {
+		point, ok := byBucket[key.bucket]
+		if !ok {
+			point = &domain.TrendPoint{Bucket: key.bucket}
+			byBucket[key.bucket] = point
+		}
+		value := snapshot.Downloads
+		if field == "likes" {
+			value = snapshot.Likes
+		}
+		point.Total += int64(value)
+		point.Models++
+	}
+	points := make([]domain.TrendPoint, 0, len(byBucket))
+	for _, point := range byBucket {
+		points = append(points, *point)
+	}
+	slices.SortFunc(points, func(a, b domain.TrendPoint) int { return a.Bucket.Compare(b.Bucket) })
+	return points, nil
+}
+
+// SaveStatsSnapshot implements the ModelStorage interface.
+func (s *MemoryModelStorage) SaveStatsSnapshot(ctx context.Context, snapshot domain.StatsSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = append(s.stats, snapshot)
+	return nil
+}
+
+// LatestStatsSnapshot implements the ModelStorage interface.
+func (s *MemoryModelStorage) LatestStatsSnapshot(ctx context.Context) (*domain.StatsSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var latest *domain.StatsSnapshot
+	for i := range s.stats {
+		if latest == nil || s.stats[i].TakenAt.After(latest.TakenAt) {
+			snapshot := s.stats[i]
+			latest = &snapshot
+		}
+	}
+	return latest, nil
+}
+
+// CountByFirstSeen implements the ModelStorage interface.
+func (s *MemoryModelStorage) CountByFirstSeen(ctx context.Context, interval string) ([]domain.TimeCount, error) {
+	s.mu.RLock()
+	perBucket := make(map[time.Time]int64)
+	for _, model := range s.models {
+		if model.FirstSeen != nil && model.DeletedAt == nil {
+			perBucket[service.TruncateToInterval(*model.FirstSeen, interval)]++
+		}
+	}
+	s.mu.RUnlock()
+
+	counts := make([]domain.TimeCount, 0, len(perBucket))
+	for bucket, count := range perBucket {
+		counts = append(counts, domain.TimeCount{Bucket: bucket, Count: count})
+	}
+	slices.SortFunc(counts, func(a, b domain.TimeCount) int { return a.Bucket.Compare(b.Bucket) })
+	return counts, nil
+}
+
+// ValueAtPercentile implements the ModelStorage interface.
+func (s *MemoryModelStorage) ValueAtPercentile(ctx context.Context, field string, percentile float64) (int64, error) {
+	if err := checkCountField(field); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	values := make([]int64, 0, len(s.models))
+	for _, model := range s.models {
+		if model.DeletedAt == nil {
+			values = append(values, model.CountOf(field))
+		}
+	}
+	s.mu.RUnlock()
+	if len(values) == 0 {
+		return 0, service.ErrNotFound
+	}
+
+	slices.SortFunc(values, func(a, b int64) int { return cmp.Compare(b, a) })
+	return values[service.PercentileRank(int64(len(values)), percentile)-1], nil
+}
+
+// FindByGated implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindByGated(ctx context.Context, gated bool, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.Gated = &gated
+	return s.SearchModels(ctx, opts)
+}
+
+// FindModifiedSince implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindModifiedSince(ctx context.Context, since time.Time, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.ModifiedSince = since
+	opts.SortBy, opts.SortOrder = "lastModified", -1
+	return s.SearchModels(ctx, opts)
+}
+
+// matchScore ranks how well id matches the literal search query: 2 for an
+// exact match, 1 for a prefix match and 0 otherwise.
+func matchScore(id string, opts service.SearchOptions) int {
+	query := opts.Query
+	if !opts.CaseSensitive {
+		id, query = strings.ToLower(id), strings.ToLower(query)
+	}
+	switch {
+	case id == query:
+		return 2
+	case strings.HasPrefix(id, query):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareByField compares two models on one of the sortable fields, falling
+// back to the ID for unknown fields.
+func compareByField(a, b domain.HuggingFaceModel, field string) int {
+	switch field {
+	case "likes":
+		return cmp.Compare(a.Likes, b.Likes)
+	case "downloads":
+		return cmp.Compare(a.Downloads, b.Downloads)
+	case "lastModified":
+		return a.LastModified.Compare(b.LastModified)
+	case "createdAt":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	default:
+		return strings.Compare(a.ID, b.ID)
+	}
+}
+
+// FindRelated implements the ModelStorage interface.
+func (s *MemoryModelStorage) FindRelated(ctx context.Context, model domain.HuggingFaceModel, limit int) ([]domain.HuggingFaceModel, error) {
+	related := []domain.HuggingFaceModel{}
+	if len(model.Tags) == 0 || limit <= 0 {
+		return related, nil
+	}
+
+	type scored struct {
+		model domain.HuggingFaceModel
+		score int
+	}
+	var candidates []scored
+	s.mu.RLock()
+	for _, candidate := range s.models {
+		if candidate.ID == model.ID || candidate.DeletedAt != nil {
+			continue
+		}
+		shared := 0
+		for _, tag := range slices.Compact(slices.Sorted(slices.Values(candidate.Tags))) {
+			if slices.Contains(model.Tags, tag) {
+				shared++
+			}
+		}
+		if shared == 0 {
+			continue
+		}
+		if model.PipelineTag != "" && candidate.PipelineTag == model.PipelineTag {
+			shared++
+		}
+		candidates = append(candidates, scored{model: candidate, score: shared})
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(candidates, func(a, b scored) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(b.model.Likes, a.model.Likes),
+			strings.Compare(a.model.ID, b.model.ID),
+		)
+	})
+	for _, candidate := range candidates[:min(limit, len(candidates))] {
+		related = append(related, candidate.model)
+	}
+	return related, nil
+}
+
+// SampleModels implements the ModelStorage interface.
+func (s *MemoryModelStorage) SampleModels(ctx context.Context, n int) ([]domain.HuggingFaceModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []domain.HuggingFaceModel
+	for _, model := range s.models {
+		if model.DeletedAt == nil {
+			candidates = append(candidates, model)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	sample := []domain.HuggingFaceModel{}
+	return append(sample, candidates[:max(0, min(n, len(candidates)))]...), nil
+}
+
+// ListIDs implements the ModelStorage interface.
+func (s *MemoryModelStorage) ListIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	s.mu.RLock()
+	var ids []string
+	for id := range s.models {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.Sort(ids)
+	return ids[:min(limit, len(ids))], nil
+}
+
+// MarkDeleted implements the ModelStorage interface.
+func (s *MemoryModelStorage) MarkDeleted(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model, ok := s.models[id]; ok && model.DeletedAt == nil {
+		model.DeletedAt = &at
+		s.models[id] = model
+	}
+	return nil
+}
+
+// PurgeDeleted implements the ModelStorage interface.
+func (s *MemoryModelStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for id, model := range s.models {
+		if model.DeletedAt != nil && model.DeletedAt.Before(before) {
+			delete(s.models, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// DeleteByAuthor implements the ModelStorage interface.
+func (s *MemoryModelStorage) DeleteByAuthor(ctx context.Context, author string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	for id, model := range s.models {
+		if model.Author == author {
+			delete(s.models, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DistinctAuthors implements the ModelStorage interface.
+func (s *MemoryModelStorage) DistinctAuthors(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool)
+	authors := make([]string, 0)
+	for _, model := range s.models {
+		if model.Author != "" && !seen[model.Author] {
+			seen[model.Author] = true
+			authors = append(authors, model.Author)
+		}
+	}
+	return authors, nil
+}
+
+// EnsureIndexes implements the ModelStorage interface. The memory store has
+// no indexes, so there is nothing to do.
+func (s *MemoryModelStorage) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+// NormalizeMissingFields implements the ModelStorage interface. Models held
+// as structs always have every field, so there is nothing to do.
+func (s *MemoryModelStorage) NormalizeMissingFields(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// CollectionStats implements the ModelStorage interface. The memory store
+// has no meaningful byte sizes or indexes, so only the count is reported.
+func (s *MemoryModelStorage) CollectionStats(ctx context.Context) (*domain.CollectionStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &domain.CollectionStats{
+		Count:      int64(len(s.models)),
+		IndexSizes: map[string]int64{},
+	}, nil
+}
+
+// Summary implements the ModelStorage interface.
+func (s *MemoryModelStorage) Summary(ctx context.Context) (*domain.StatsSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary := &domain.StatsSummary{TopPipelineTags: []domain.TagCount{}}
+	pipelineCounts := make(map[string]int64)
+	for _, model := range s.models {
+		if model.DeletedAt != nil {
+			continue
+		}
+		summary.TotalModels++
+		switch model.Gated {
+		case domain.GatedStatusTrue, domain.GatedStatusAuto, domain.GatedStatusManual:
+			summary.GatedModels++
+		}
+		if model.Private {
+			summary.PrivateModels++
+		}
+		summary.TotalDownloads += int64(model.Downloads)
+		if model.LastModified.After(summary.NewestLastModified) {
+			summary.NewestLastModified = model.LastModified
+		}
+		if model.PipelineTag != "" {
+			pipelineCounts[model.PipelineTag]++
+		}
+	}
+
+	for tag, count := range pipelineCounts {
+		summary.TopPipelineTags = append(summary.TopPipelineTags, domain.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(summary.TopPipelineTags, func(a, b domain.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Tag, b.Tag))
+	})
+	summary.TopPipelineTags = summary.TopPipelineTags[:min(summaryTopPipelineTags, len(summary.TopPipelineTags))]
+	return summary, nil
+}
+
+// MemoryStatusStorage is an in-memory implementation of the StatusStorage interface.
+type MemoryStatusStorage struct {
+	mu  sync.Mutex
+	doc domain.StatusDocument
+}
+
+// NewMemoryStatusStorage creates a status store in the "first run" state.
+func NewMemoryStatusStorage() *MemoryStatusStorage {
+	return &MemoryStatusStorage{doc: domain.StatusDocument{
+		ID:     statusDocumentID,
+		Status: domain.StatusNeedsBackfill,
+	}}
+}
+
+// GetStatusDocument implements the StatusStorage interface.
+func (s *MemoryStatusStorage) GetStatusDocument(ctx context.Context) (*domain.StatusDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.doc
+	doc.BackfillCursors = maps.Clone(s.doc.BackfillCursors)
+	return &doc, nil
+}
+
+// UpdateStatus implements the StatusStorage interface.
+func (s *MemoryStatusStorage) UpdateStatus(ctx context.Context, status domain.ServiceStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc.Status = status
+	s.doc.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// UpdateBackfillCursor implements the StatusStorage interface.
+func (s *MemoryStatusStorage) UpdateBackfillCursor(ctx context.Context, shard, cursorURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc.SetCursor(shard, cursorURL)
+	s.doc.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// ClearBackfillCursor implements the StatusStorage interface.
+func (s *MemoryStatusStorage) ClearBackfillCursor(ctx context.Context, shard string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc.ClearCursor(shard)
+	s.doc.UpdatedAt = time.Now().UTC()
+	return nil
+}
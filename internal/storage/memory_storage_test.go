@@ -0,0 +1,557 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+)
+
+// newMemoryStore returns a memory store holding models.
+func newMemoryStore(t *testing.T, models ...domain.HuggingFaceModel) *MemoryModelStorage {
+	t.Helper()
+	store := NewMemoryModelStorage()
+	if err := store.BulkUpsert(context.Background(), models); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// ids returns the IDs of models in order.
+func ids(models []domain.HuggingFaceModel) []string {
+	out := make([]string, len(models))
+	for i, model := range models {
+		out[i] = model.ID
+	}
+	return out
+}
+
+func TestMemorySearchCaseSensitivity(t *testing.T) {
+	store := newMemoryStore(t, domain.HuggingFaceModel{ID: "meta/Llama"}, domain.HuggingFaceModel{ID: "meta/llama-2"})
+
+	for _, tc := range []struct {
+		caseSensitive bool
+		want          []string
+	}{
+		{caseSensitive: false, want: []string{"meta/Llama", "meta/llama-2"}},
+		{caseSensitive: true, want: []string{"meta/Llama"}},
+	} {
+		models, _, err := store.SearchModels(context.Background(), service.SearchOptions{
+			Query: "Llama", CaseSensitive: tc.caseSensitive, SortBy: "id", SortOrder: 1, Page: 1, Limit: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(models); !slices.Equal(got, tc.want) {
+			t.Errorf("CaseSensitive=%v: got %v, want %v", tc.caseSensitive, got, tc.want)
+		}
+	}
+}
+
+func TestMemoryDeleteByAuthorRemovesOnlyThatAuthor(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "spam/a", Author: "spam"},
+		domain.HuggingFaceModel{ID: "spam/b", Author: "spam"},
+		domain.HuggingFaceModel{ID: "meta/llama", Author: "meta"},
+		domain.HuggingFaceModel{ID: "spammer/c", Author: "spammer"},
+	)
+
+	deleted, err := store.DeleteByAuthor(context.Background(), "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+	remaining, err := store.ListIDs(context.Background(), "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"meta/llama", "spammer/c"}; !slices.Equal(remaining, want) {
+		t.Errorf("remaining = %v, want %v", remaining, want)
+	}
+}
+
+func TestMemorySearchFiltersByLicense(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/mit", License: "mit"},
+		domain.HuggingFaceModel{ID: "a/apache", License: "apache-2.0"},
+		domain.HuggingFaceModel{ID: "a/none"},
+	)
+
+	models, total, err := store.SearchModels(context.Background(), service.SearchOptions{License: "MIT", Limit: 10, Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(models); total != 1 || !slices.Equal(got, []string{"a/mit"}) {
+		t.Errorf("Search(license=MIT) = %v (total %d), want [a/mit]", got, total)
+	}
+}
+
+func TestMemorySearchFiltersByLibraryAndLanguage(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/en", Library: "transformers", Languages: []string{"en"}},
+		domain.HuggingFaceModel{ID: "a/multi", Library: "transformers", Languages: []string{"fr", "en"}},
+		domain.HuggingFaceModel{ID: "a/fr", Library: "transformers", Languages: []string{"fr"}},
+		domain.HuggingFaceModel{ID: "a/diffusers", Library: "diffusers", Languages: []string{"en"}},
+	)
+
+	models, _, err := store.SearchModels(context.Background(), service.SearchOptions{Library: "Transformers", Language: "en", SortBy: "_id", SortOrder: 1, Limit: 10, Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(models), []string{"a/en", "a/multi"}; !slices.Equal(got, want) {
+		t.Errorf("Search(library=Transformers, language=en) = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryFindRelatedRanksByOverlap(t *testing.T) {
+	target := domain.HuggingFaceModel{ID: "a/target", Tags: []string{"pytorch", "bert", "en"}, PipelineTag: "fill-mask"}
+	store := newMemoryStore(t,
+		target,
+		domain.HuggingFaceModel{ID: "a/barely", Tags: []string{"pytorch"}, Likes: 1000},
+		domain.HuggingFaceModel{ID: "a/heavily", Tags: []string{"pytorch", "bert", "en"}, PipelineTag: "fill-mask"},
+		domain.HuggingFaceModel{ID: "a/unrelated", Tags: []string{"jax"}, PipelineTag: "fill-mask"},
+	)
+	ctx := context.Background()
+
+	related, err := store.FindRelated(ctx, target, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(related), []string{"a/heavily", "a/barely"}; !slices.Equal(got, want) {
+		t.Errorf("FindRelated = %v, want %v", got, want)
+	}
+
+	related, err = store.FindRelated(ctx, domain.HuggingFaceModel{ID: "a/untagged"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if related == nil || len(related) != 0 {
+		t.Errorf("FindRelated for an untagged model = %#v, want an empty list", related)
+	}
+}
+
+func TestMemoryRelevanceRanksExactThenPrefixMatches(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "fans/meta/llama-remix", Downloads: 300},
+		domain.HuggingFaceModel{ID: "meta/llama-2", Downloads: 200},
+		domain.HuggingFaceModel{ID: "Meta/Llama", Downloads: 100},
+	)
+
+	for _, tc := range []struct {
+		relevance bool
+		want      []string
+	}{
+		{relevance: false, want: []string{"fans/meta/llama-remix", "meta/llama-2", "Meta/Llama"}},
+		{relevance: true, want: []string{"Meta/Llama", "meta/llama-2", "fans/meta/llama-remix"}},
+	} {
+		models, _, err := store.SearchModels(context.Background(), service.SearchOptions{
+			Query: "meta/llama", Relevance: tc.relevance, SortBy: "downloads", SortOrder: -1, Page: 1, Limit: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(models); !slices.Equal(got, tc.want) {
+			t.Errorf("Relevance=%v: got %v, want %v", tc.relevance, got, tc.want)
+		}
+	}
+}
+
+func TestMemoryGetByPipelineTagPagesAndCounts(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/gen-1", PipelineTag: "text-generation", Likes: 30},
+		domain.HuggingFaceModel{ID: "a/gen-2", PipelineTag: "text-generation", Likes: 20},
+		domain.HuggingFaceModel{ID: "a/gen-3", PipelineTag: "text-generation", Likes: 10},
+		domain.HuggingFaceModel{ID: "a/mask", PipelineTag: "fill-mask", Likes: 40},
+		domain.HuggingFaceModel{ID: "a/untagged", Likes: 50},
+	)
+
+	for _, tc := range []struct {
+		tag       string
+		page      int64
+		want      []string
+		wantTotal int64
+	}{
+		{tag: "text-generation", page: 1, want: []string{"a/gen-1", "a/gen-2"}, wantTotal: 3},
+		{tag: "text-generation", page: 2, want: []string{"a/gen-3"}, wantTotal: 3},
+		{tag: "fill-mask", page: 1, want: []string{"a/mask"}, wantTotal: 1},
+		{tag: "no-such-task", page: 1, want: []string{}, wantTotal: 0},
+	} {
+		models, total, err := store.GetByPipelineTag(context.Background(), tc.tag, service.SearchOptions{
+			SortBy: "likes", SortOrder: -1, Page: tc.page, Limit: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(models); !slices.Equal(got, tc.want) || total != tc.wantTotal {
+			t.Errorf("%s page %d: got %v of %d, want %v of %d", tc.tag, tc.page, got, total, tc.want, tc.wantTotal)
+		}
+	}
+}
+
+func TestMemoryFindExtremeByEachBenchmarkField(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/oldest-created", CreatedAt: day(1), LastModified: day(20)},
+		domain.HuggingFaceModel{ID: "a/newest-created", CreatedAt: day(10), LastModified: day(11)},
+		domain.HuggingFaceModel{ID: "a/newest-modified", CreatedAt: day(5), LastModified: day(30)},
+		domain.HuggingFaceModel{ID: "a/oldest-modified", CreatedAt: day(6), LastModified: day(7)},
+	)
+
+	for _, tc := range []struct {
+		field string
+		order int
+		want  string
+	}{
+		{field: config.BenchmarkFieldLastModified, order: -1, want: "a/newest-modified"},
+		{field: config.BenchmarkFieldLastModified, order: 1, want: "a/oldest-modified"},
+		{field: config.BenchmarkFieldCreatedAt, order: -1, want: "a/newest-created"},
+		{field: config.BenchmarkFieldCreatedAt, order: 1, want: "a/oldest-created"},
+	} {
+		model, err := store.FindExtremeBy(context.Background(), tc.field, tc.order)
+		if err != nil {
+			t.Fatalf("%s %d: %v", tc.field, tc.order, err)
+		}
+		if model.ID != tc.want {
+			t.Errorf("%s %d: got %s, want %s", tc.field, tc.order, model.ID, tc.want)
+		}
+	}
+
+	if _, err := store.FindExtremeBy(context.Background(), "likes", -1); err == nil {
+		t.Error("FindExtremeBy accepted an unsupported field")
+	}
+	if _, err := NewMemoryModelStorage().FindExtremeBy(context.Background(), config.BenchmarkFieldCreatedAt, -1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("empty store: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemorySortPlacesModelsMissingTheFieldDeterministically(t *testing.T) {
+	var models []domain.HuggingFaceModel
+	if err := json.Unmarshal([]byte(`[
+		{"id": "a/popular", "downloads": 50},
+		{"id": "c/no-count"},
+		{"id": "b/no-count"},
+		{"id": "a/zero", "downloads": 0}
+	]`), &models); err != nil {
+		t.Fatal(err)
+	}
+	store := newMemoryStore(t, models...)
+
+	for _, tc := range []struct {
+		order int
+		want  []string
+	}{
+		{order: -1, want: []string{"a/popular", "c/no-count", "b/no-count", "a/zero"}},
+		{order: 1, want: []string{"a/zero", "b/no-count", "c/no-count", "a/popular"}},
+	} {
+		for range 3 {
+			page, _, err := store.SearchModels(context.Background(), service.SearchOptions{SortBy: "downloads", SortOrder: tc.order, Page: 1, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(page); !slices.Equal(got, tc.want) {
+				t.Fatalf("order %d: got %v, want %v", tc.order, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestMemoryLiteralSearchEscapesPattern(t *testing.T) {
+	store := newMemoryStore(t, domain.HuggingFaceModel{ID: "a/model[v1"}, domain.HuggingFaceModel{ID: "a/model-v1"})
+
+	if _, _, err := store.SearchModels(context.Background(), service.SearchOptions{Query: "[v1", SortBy: "id", Page: 1, Limit: 10}); err == nil {
+		t.Error("an invalid pattern searched as a regex succeeded")
+	}
+	models, _, err := store.SearchModels(context.Background(), service.SearchOptions{Query: "[v1", Literal: true, SortBy: "id", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(models); !slices.Equal(got, []string{"a/model[v1"}) {
+		t.Errorf("literal search: got %v, want only a/model[v1", got)
+	}
+}
+
+func TestMemoryUpsertWithResult(t *testing.T) {
+	store := NewMemoryModelStorage()
+	ctx := context.Background()
+	original := domain.HuggingFaceModel{ID: "a/model", Likes: 1}
+	changed := domain.HuggingFaceModel{ID: "a/model", Likes: 2}
+
+	for _, step := range []struct {
+		name  string
+		model domain.HuggingFaceModel
+		want  service.UpsertResult
+	}{
+		{name: "insert", model: original, want: service.UpsertInserted},
+		{name: "same again", model: original, want: service.UpsertUnchanged},
+		{name: "update", model: changed, want: service.UpsertUpdated},
+		{name: "no-op", model: changed, want: service.UpsertUnchanged},
+	} {
+		got, err := store.UpsertWithResult(ctx, step.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("%s: got %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestMemoryMostRecentlyModifiedSkipsDeletedModels(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/live", LastModified: day(10)},
+		domain.HuggingFaceModel{ID: "a/deleted", LastModified: day(20)},
+	)
+	ctx := context.Background()
+	if err := store.MarkDeleted(ctx, "a/deleted", day(21)); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := store.FindMostRecentlyModified(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.ID != "a/live" {
+		t.Errorf("most recently modified = %s, want the live a/live", model.ID)
+	}
+
+	if err := store.MarkDeleted(ctx, "a/live", day(22)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FindMostRecentlyModified(ctx); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("only deleted models: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryFindByGatedGroupsStatuses(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/auto", Gated: domain.GatedStatusAuto},
+		domain.HuggingFaceModel{ID: "a/manual", Gated: domain.GatedStatusManual},
+		domain.HuggingFaceModel{ID: "a/open", Gated: domain.GatedStatusFalse},
+		domain.HuggingFaceModel{ID: "a/true", Gated: domain.GatedStatusTrue},
+		domain.HuggingFaceModel{ID: "a/unset"},
+	)
+
+	for _, tc := range []struct {
+		gated bool
+		want  []string
+	}{
+		{gated: true, want: []string{"a/auto", "a/manual", "a/true"}},
+		{gated: false, want: []string{"a/open", "a/unset"}},
+	} {
+		models, total, err := store.FindByGated(context.Background(), tc.gated, service.SearchOptions{
+			SortBy: "id", SortOrder: 1, Page: 1, Limit: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(models); !slices.Equal(got, tc.want) || total != int64(len(tc.want)) {
+			t.Errorf("gated=%v: got %v (total %d), want %v", tc.gated, got, total, tc.want)
+		}
+	}
+}
+
+func TestMemoryCountByAuthorOrdersByCountThenAuthor(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "b/1", Author: "b"},
+		domain.HuggingFaceModel{ID: "b/2", Author: "b"},
+		domain.HuggingFaceModel{ID: "c/1", Author: "c"},
+		domain.HuggingFaceModel{ID: "c/2", Author: "c"},
+		domain.HuggingFaceModel{ID: "a/1", Author: "a"},
+		domain.HuggingFaceModel{ID: "z/1", Author: "z"},
+		domain.HuggingFaceModel{ID: "z/2", Author: "z"},
+		domain.HuggingFaceModel{ID: "z/3", Author: "z"},
+		domain.HuggingFaceModel{ID: "nobody"},
+	)
+
+	counts, total, err := store.CountByAuthor(context.Background(), service.SearchOptions{Page: 1, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.AuthorCount{{Author: "z", Count: 3}, {Author: "b", Count: 2}, {Author: "c", Count: 2}}
+	if !slices.Equal(counts, want) || total != 4 {
+		t.Errorf("got %v of %d authors, want %v of 4", counts, total, want)
+	}
+}
+
+func TestMemorySnapshotTrendSumsLastSnapshotPerModelAndBucket(t *testing.T) {
+	store := NewMemoryModelStorage()
+	hour := func(h int) time.Time { return time.Date(2025, 6, 1, h, 30, 0, 0, time.UTC) }
+	if err := store.RecordSnapshots(context.Background(), []domain.ModelSnapshot{
+		{ModelID: "a/one", ScrapedAt: hour(10), Likes: 1, Downloads: 10},
+		{ModelID: "a/one", ScrapedAt: hour(12), Likes: 3, Downloads: 30},
+		{ModelID: "a/two", ScrapedAt: hour(11), Likes: 5, Downloads: 50},
+		{ModelID: "a/one", ScrapedAt: hour(10).AddDate(0, 0, 1), Likes: 4, Downloads: 40},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := store.SnapshotTrend(context.Background(), "likes", "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.TrendPoint{
+		{Bucket: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Total: 8, Models: 2},
+		{Bucket: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), Total: 4, Models: 1},
+	}
+	if !slices.Equal(points, want) {
+		t.Errorf("likes per day = %+v, want %+v", points, want)
+	}
+
+	points, err = store.SnapshotTrend(context.Background(), "downloads", "hour")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 4 || points[0].Total != 10 || points[1].Total != 50 || points[2].Total != 30 {
+		t.Errorf("downloads per hour = %+v, want one point per snapshot, oldest first", points)
+	}
+}
+
+func TestMemoryCountByFirstSeenBucketsNewModels(t *testing.T) {
+	seen := func(month time.Month, day, hour int) *time.Time {
+		t := time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/1", FirstSeen: seen(6, 2, 9)},  // Monday
+		domain.HuggingFaceModel{ID: "a/2", FirstSeen: seen(6, 2, 23)}, // Monday
+		domain.HuggingFaceModel{ID: "a/3", FirstSeen: seen(6, 8, 12)}, // Sunday
+		domain.HuggingFaceModel{ID: "a/4", FirstSeen: seen(6, 9, 0)},  // Monday
+		domain.HuggingFaceModel{ID: "a/5", FirstSeen: seen(7, 1, 0)},
+	)
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, tc := range []struct {
+		interval string
+		want     []domain.TimeCount
+	}{
+		{interval: "day", want: []domain.TimeCount{
+			{Bucket: day(6, 2), Count: 2}, {Bucket: day(6, 8), Count: 1}, {Bucket: day(6, 9), Count: 1}, {Bucket: day(7, 1), Count: 1},
+		}},
+		{interval: "week", want: []domain.TimeCount{
+			{Bucket: day(6, 2), Count: 3}, {Bucket: day(6, 9), Count: 1}, {Bucket: day(6, 30), Count: 1},
+		}},
+		{interval: "month", want: []domain.TimeCount{{Bucket: day(6, 1), Count: 4}, {Bucket: day(7, 1), Count: 1}}},
+	} {
+		counts, err := store.CountByFirstSeen(context.Background(), tc.interval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(counts, tc.want) {
+			t.Errorf("%s: counts = %v, want %v", tc.interval, counts, tc.want)
+		}
+	}
+}
+
+func TestMemoryFindDuplicateSHAs(t *testing.T) {
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "b/copy", SHA: "aaa"},
+		domain.HuggingFaceModel{ID: "a/original", SHA: "aaa"},
+		domain.HuggingFaceModel{ID: "c/copy", SHA: "aaa"},
+		domain.HuggingFaceModel{ID: "d/one", SHA: "bbb"},
+		domain.HuggingFaceModel{ID: "d/two", SHA: "bbb"},
+		domain.HuggingFaceModel{ID: "e/unique", SHA: "ccc"},
+		domain.HuggingFaceModel{ID: "f/no-sha"},
+		domain.HuggingFaceModel{ID: "g/no-sha"},
+	)
+
+	groups, total, err := store.FindDuplicateSHAs(context.Background(), service.SearchOptions{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(groups) != 2 {
+		t.Fatalf("got %+v of %d, want the aaa and bbb groups", groups, total)
+	}
+	if g := groups[0]; g.SHA != "aaa" || g.Count != 3 || !slices.Equal(g.IDs, []string{"a/original", "b/copy", "c/copy"}) {
+		t.Errorf("first group = %+v, want aaa shared by a/original, b/copy and c/copy", g)
+	}
+	if g := groups[1]; g.SHA != "bbb" || g.Count != 2 || !slices.Equal(g.IDs, []string{"d/one", "d/two"}) {
+		t.Errorf("second group = %+v, want bbb shared by d/one and d/two", g)
+	}
+}
+
+func TestMemoryReadsLeaveOutDeletedModels(t *testing.T) {
+	firstSeen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/live", Tags: []string{"nlp"}, PipelineTag: "fill-mask", FirstSeen: &firstSeen},
+		domain.HuggingFaceModel{ID: "a/deleted", Tags: []string{"nlp"}, PipelineTag: "fill-mask", FirstSeen: &firstSeen},
+	)
+	ctx := context.Background()
+	if err := store.MarkDeleted(ctx, "a/deleted", firstSeen); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := store.FindByIDs(ctx, []string{"a/live", "a/deleted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(found); !slices.Equal(got, []string{"a/live"}) {
+		t.Errorf("FindByIDs = %v, want [a/live]", got)
+	}
+
+	related, err := store.FindRelated(ctx, domain.HuggingFaceModel{ID: "a/query", Tags: []string{"nlp"}}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(related); !slices.Equal(got, []string{"a/live"}) {
+		t.Errorf("FindRelated = %v, want [a/live]", got)
+	}
+
+	summary, err := store.Summary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.TotalModels != 1 || len(summary.TopPipelineTags) != 1 || summary.TopPipelineTags[0].Count != 1 {
+		t.Errorf("summary = %+v, want only a/live counted", summary)
+	}
+
+	counts, err := store.CountByFirstSeen(ctx, "day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Count != 1 {
+		t.Errorf("CountByFirstSeen = %+v, want one bucket counting a/live", counts)
+	}
+}
+
+func TestMemoryPurgeDeletedRemovesOnlyModelsPastRetention(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	store := newMemoryStore(t,
+		domain.HuggingFaceModel{ID: "a/expired"},
+		domain.HuggingFaceModel{ID: "a/at-cutoff"},
+		domain.HuggingFaceModel{ID: "a/recent"},
+		domain.HuggingFaceModel{ID: "a/live"},
+	)
+	ctx := context.Background()
+	for id, deletedAt := range map[string]time.Time{"a/expired": day(9), "a/at-cutoff": day(10), "a/recent": day(11)} {
+		if err := store.MarkDeleted(ctx, id, deletedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := store.PurgeDeleted(ctx, day(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d models, want 1", purged)
+	}
+	// The cutoff is exclusive: a model deleted exactly at it is kept.
+	for id, want := range map[string]bool{"a/expired": false, "a/at-cutoff": true, "a/recent": true, "a/live": true} {
+		model, err := store.FindByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kept := model != nil; kept != want {
+			t.Errorf("%s kept = %v, want %v", id, kept, want)
+		}
+	}
+}
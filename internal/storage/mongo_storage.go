@@ -4,40 +4,82 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"hf-scraper/internal/config"
 	"hf-scraper/internal/domain"
 	"hf-scraper/internal/service"
+	"hf-scraper/internal/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("hf-scraper/storage")
+
 // MongoModelStorage is the MongoDB implementation of the ModelStorage interface.
 type MongoModelStorage struct {
-	collection *mongo.Collection
+	// collections is swapped as a whole when the database is reconnected.
+	// Operations load it once, so they finish on the client they started on.
+	collections atomic.Pointer[modelCollections]
+	cfg         config.DatabaseConfig
+	slowQueries slowQueryLog
+}
+
+// modelCollections holds the model collection handles of one client.
+type modelCollections struct {
+	write *mongo.Collection
+	// read is the same collection with the configured read preference. It
+	// serves user-facing reads only; anything feeding a write decision reads
+	// from write so it never sees a lagging secondary.
+	read *mongo.Collection
+	// history holds the model snapshots.
+	history *mongo.Collection
+	// stats holds the periodic stats snapshots.
+	stats *mongo.Collection
+}
+
+// collection returns the model collection for writes and the reads they depend on.
+func (s *MongoModelStorage) collection() *mongo.Collection {
+	return s.collections.Load().write
+}
+
+// readCollection returns the model collection for user-facing reads.
+func (s *MongoModelStorage) readCollection() *mongo.Collection {
+	return s.collections.Load().read
 }
 
 func (s *MongoModelStorage) SearchModels(ctx context.Context, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
-	filter := bson.M{}
-	if opts.Query != "" {
-		// Using a case-insensitive regex search on the model ID.
-		filter["_id"] = primitive.Regex{Pattern: opts.Query, Options: "i"}
-	}
+	filter := searchFilter(opts)
+	defer s.slowQueries.track(ctx, "SearchModels", filter)()
 
 	// Get total count for pagination
-	total, err := s.collection.CountDocuments(ctx, filter)
+	total, err := s.readCollection().CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	if opts.Relevance && opts.Query != "" {
+		models, err := s.searchByRelevance(ctx, filter, opts)
+		return models, total, err
+	}
+
 	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: opts.SortBy, Value: opts.SortOrder}})
+	findOptions.SetSort(sortWithTiebreak(opts.SortBy, opts.SortOrder))
 	findOptions.SetLimit(opts.Limit)
 	findOptions.SetSkip((opts.Page - 1) * opts.Limit)
 
-	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	cursor, err := s.readCollection().Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -51,45 +93,507 @@ func (s *MongoModelStorage) SearchModels(ctx context.Context, opts service.Searc
 	return models, total, nil
 }
 
+// searchFilter builds the query matching the models selected by the filters
+// of opts, ignoring its sort and paging.
+func searchFilter(opts service.SearchOptions) bson.M {
+	filter := bson.M{}
+	if opts.Query != "" {
+		// Using a regex search on the model ID, case-insensitive unless requested otherwise.
+		regexOptions := "i"
+		if opts.CaseSensitive {
+			regexOptions = ""
+		}
+		pattern := opts.Query
+		if opts.Literal {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		filter["_id"] = primitive.Regex{Pattern: pattern, Options: regexOptions}
+	}
+	// Soft-deleted models are kept for reference but are not searchable.
+	filter["deletedAt"] = bson.M{"$exists": false}
+	if opts.License != "" {
+		filter["license"] = strings.ToLower(opts.License)
+	}
+	if opts.Library != "" {
+		filter["library"] = strings.ToLower(opts.Library)
+	}
+	if opts.Language != "" {
+		// Equality against an array field matches any of its elements.
+		filter["languages"] = strings.ToLower(opts.Language)
+	}
+	if opts.PipelineTag != "" {
+		filter["pipeline_tag"] = opts.PipelineTag
+	}
+	if !opts.ModifiedSince.IsZero() {
+		filter["lastModified"] = bson.M{"$gte": opts.ModifiedSince}
+	}
+	if opts.Gated != nil {
+		// Ungated covers "false" as well as models stored without the field.
+		operator := "$nin"
+		if *opts.Gated {
+			operator = "$in"
+		}
+		filter["gated"] = bson.M{operator: domain.GatedStatuses}
+	}
+	return filter
+}
+
+// CountByAuthor implements the ModelStorage interface. The page of authors
+// and their total are computed in one aggregation with a $facet stage.
+func (s *MongoModelStorage) CountByAuthor(ctx context.Context, opts service.SearchOptions) ([]domain.AuthorCount, int64, error) {
+	filter := searchFilter(opts)
+	pipeline := authorCountPipeline(filter, opts)
+
+	defer s.slowQueries.track(ctx, "CountByAuthor", filter)()
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Authors []domain.AuthorCount `bson:"authors"`
+		Total   []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, 0, err
+	}
+	if len(facets) != 1 {
+		return nil, 0, nil
+	}
+	var total int64
+	if len(facets[0].Total) == 1 {
+		total = facets[0].Total[0].Count
+	}
+	return facets[0].Authors, total, nil
+}
+
+// authorCountPipeline groups the models matching filter by author, skipping
+// models without one, and returns the page of opts ordered by descending
+// count with ties broken by author, next to the number of authors.
+func authorCountPipeline(filter bson.M, opts service.SearchOptions) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$match", Value: bson.M{"author": bson.M{"$nin": bson.A{"", nil}}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$author", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"authors": bson.A{
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": (opts.Page - 1) * opts.Limit},
+				bson.M{"$limit": opts.Limit},
+			},
+			"total": bson.A{bson.M{"$count": "count"}},
+		}}},
+	}
+}
+
+// FindDuplicateSHAs implements the ModelStorage interface. Like
+// CountByAuthor, the page and the total come from one $facet aggregation.
+func (s *MongoModelStorage) FindDuplicateSHAs(ctx context.Context, opts service.SearchOptions) ([]domain.DuplicateGroup, int64, error) {
+	filter := searchFilter(opts)
+	pipeline := duplicateSHAPipeline(filter, opts)
+
+	defer s.slowQueries.track(ctx, "FindDuplicateSHAs", filter)()
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Groups []domain.DuplicateGroup `bson:"groups"`
+		Total  []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, 0, err
+	}
+	if len(facets) != 1 {
+		return nil, 0, nil
+	}
+	var total int64
+	if len(facets[0].Total) == 1 {
+		total = facets[0].Total[0].Count
+	}
+	return facets[0].Groups, total, nil
+}
+
+// duplicateSHAPipeline groups the models matching filter by SHA, skipping
+// models without one, keeps the SHAs shared by more than one model and
+// returns the page of opts ordered by descending group size with ties broken
+// by SHA, next to the number of such SHAs. Sorting by _id before grouping
+// keeps each group's IDs in order.
+func duplicateSHAPipeline(filter bson.M, opts service.SearchOptions) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$match", Value: bson.M{"sha": bson.M{"$nin": bson.A{"", nil}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$sha", "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"groups": bson.A{
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": (opts.Page - 1) * opts.Limit},
+				bson.M{"$limit": opts.Limit},
+			},
+			"total": bson.A{bson.M{"$count": "count"}},
+		}}},
+	}
+}
+
+// GetByPipelineTag implements the ModelStorage interface.
+func (s *MongoModelStorage) GetByPipelineTag(ctx context.Context, tag string, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.PipelineTag = tag
+	return s.SearchModels(ctx, opts)
+}
+
+// RecordSnapshots implements the ModelStorage interface.
+func (s *MongoModelStorage) RecordSnapshots(ctx context.Context, snapshots []domain.ModelSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	docs := make([]any, len(snapshots))
+	for i, snapshot := range snapshots {
+		docs[i] = snapshot
+	}
+	defer s.slowQueries.track(ctx, "RecordSnapshots", fmt.Sprintf("%d snapshots", len(snapshots)))()
+	_, err := s.collections.Load().history.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// NearestSnapshot implements the ModelStorage interface with two indexed
+// lookups: the last snapshot at or before at and the first one after it.
+func (s *MongoModelStorage) NearestSnapshot(ctx context.Context, id string, at time.Time) (*domain.ModelSnapshot, error) {
+	history := s.collections.Load().history
+	var nearest *domain.ModelSnapshot
+	for _, side := range []struct {
+		operator string
+		order    int
+	}{{"$lte", -1}, {"$gt", 1}} {
+		filter := bson.M{"modelId": id, "scrapedAt": bson.M{side.operator: at}}
+		opts := options.FindOne().SetSort(bson.D{{Key: "scrapedAt", Value: side.order}})
+		done := s.slowQueries.track(ctx, "NearestSnapshot", filter)
+		var snapshot domain.ModelSnapshot
+		err := history.FindOne(ctx, filter, opts).Decode(&snapshot)
+		done()
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if nearest == nil || snapshot.ScrapedAt.Sub(at).Abs() < nearest.ScrapedAt.Sub(at).Abs() {
+			nearest = &snapshot
+		}
+	}
+	return nearest, nil
+}
+
+// SnapshotTrend implements the ModelStorage interface. Buckets are computed
+// with $dateTrunc and the last snapshots picked with $bottom, which requires
+// MongoDB 5.2.
+func (s *MongoModelStorage) SnapshotTrend(ctx context.Context, field, interval string) ([]domain.TrendPoint, error) {
+	pipeline := trendPipeline(field, interval)
+	defer s.slowQueries.track(ctx, "SnapshotTrend", bson.M{"field": field, "interval": interval})()
+	cursor, err := s.collections.Load().history.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var points []domain.TrendPoint
+	if err := cursor.All(ctx, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// trendPipeline buckets the snapshots by interval, keeps the last value of
+// field per model and bucket, then sums those per bucket, oldest first. The
+// last snapshot is picked within each group with $bottom rather than by
+// sorting the whole history first, which no index covers and which would
+// exceed the in-memory sort limit on a large history.
+func trendPipeline(field, interval string) mongo.Pipeline {
+	bucket := bson.M{"date": "$scrapedAt", "unit": interval}
+	if interval == "week" {
+		bucket["startOfWeek"] = "monday"
+	}
+	return mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"model": "$modelId", "bucket": bson.M{"$dateTrunc": bucket}},
+			"value": bson.M{"$bottom": bson.M{
+				"sortBy": bson.D{{Key: "scrapedAt", Value: 1}},
+				"output": "$" + field,
+			}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$_id.bucket",
+			"total":  bson.M{"$sum": "$value"},
+			"models": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+}
+
+// SaveStatsSnapshot implements the ModelStorage interface.
+func (s *MongoModelStorage) SaveStatsSnapshot(ctx context.Context, snapshot domain.StatsSnapshot) error {
+	defer s.slowQueries.track(ctx, "SaveStatsSnapshot", bson.M{"takenAt": snapshot.TakenAt})()
+	_, err := s.collections.Load().stats.InsertOne(ctx, snapshot)
+	return err
+}
+
+// LatestStatsSnapshot implements the ModelStorage interface.
+func (s *MongoModelStorage) LatestStatsSnapshot(ctx context.Context) (*domain.StatsSnapshot, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "takenAt", Value: -1}})
+	defer s.slowQueries.track(ctx, "LatestStatsSnapshot", bson.D{})()
+	var snapshot domain.StatsSnapshot
+	err := s.collections.Load().stats.FindOne(ctx, bson.D{}, opts).Decode(&snapshot)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// CountByFirstSeen implements the ModelStorage interface.
+func (s *MongoModelStorage) CountByFirstSeen(ctx context.Context, interval string) ([]domain.TimeCount, error) {
+	pipeline := firstSeenPipeline(interval)
+	defer s.slowQueries.track(ctx, "CountByFirstSeen", bson.M{"interval": interval})()
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []domain.TimeCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// firstSeenPipeline counts the models with a firstSeen that are not
+// soft-deleted per interval bucket, oldest first.
+func firstSeenPipeline(interval string) mongo.Pipeline {
+	bucket := bson.M{"date": "$firstSeen", "unit": interval}
+	if interval == "week" {
+		bucket["startOfWeek"] = "monday"
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"firstSeen": bson.M{"$type": "date"}, "deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$dateTrunc": bucket}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+}
+
+// FindByGated implements the ModelStorage interface.
+func (s *MongoModelStorage) FindByGated(ctx context.Context, gated bool, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.Gated = &gated
+	return s.SearchModels(ctx, opts)
+}
+
+// FindModifiedSince implements the ModelStorage interface.
+func (s *MongoModelStorage) FindModifiedSince(ctx context.Context, since time.Time, opts service.SearchOptions) ([]domain.HuggingFaceModel, int64, error) {
+	opts.ModifiedSince = since
+	opts.SortBy, opts.SortOrder = "lastModified", -1
+	return s.SearchModels(ctx, opts)
+}
+
+// searchByRelevance returns a page of the models matching filter, ranking
+// an exact ID match first and ID prefix matches second, each tier ordered by
+// the requested sort. The query is compared as a literal string here, so the
+// ranking is meaningful for plain-text queries even though matching uses a regex.
+func (s *MongoModelStorage) searchByRelevance(ctx context.Context, filter bson.M, opts service.SearchOptions) ([]domain.HuggingFaceModel, error) {
+	var id, query any = "$_id", opts.Query
+	if !opts.CaseSensitive {
+		id, query = bson.M{"$toLower": "$_id"}, strings.ToLower(opts.Query)
+	}
+
+	sort := append(bson.D{{Key: "matchScore", Value: -1}}, sortWithTiebreak(opts.SortBy, opts.SortOrder)...)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{
+			"matchScore": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{id, query}}, "then": 2},
+					bson.M{"case": bson.M{"$eq": bson.A{bson.M{"$indexOfCP": bson.A{id, query}}, 0}}, "then": 1},
+				},
+				"default": 0,
+			}},
+		}}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$skip", Value: (opts.Page - 1) * opts.Limit}},
+		{{Key: "$limit", Value: opts.Limit}},
+		{{Key: "$project", Value: bson.M{"matchScore": 0}}},
+	}
+
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var models []domain.HuggingFaceModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// sortWithTiebreak builds a sort on the given field that is fully deterministic:
+// documents with equal values are ordered by _id in the same direction.
+func sortWithTiebreak(field string, order int) bson.D {
+	sort := bson.D{{Key: field, Value: order}}
+	if field != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: order})
+	}
+	return sort
+}
+
 // NewMongoModelStorage creates a new storage adapter for models.
-func NewMongoModelStorage(db *mongo.Database, collectionName string) *MongoModelStorage {
-	return &MongoModelStorage{
-		collection: db.Collection(collectionName),
+func NewMongoModelStorage(db *mongo.Database, cfg config.DatabaseConfig) *MongoModelStorage {
+	s := &MongoModelStorage{
+		cfg:         cfg,
+		slowQueries: slowQueryLog{threshold: time.Duration(cfg.SlowQueryThresholdMs) * time.Millisecond},
+	}
+	s.useDatabase(db)
+	return s
+}
+
+// useDatabase points the adapter at db, e.g. one from a reconnected client.
+func (s *MongoModelStorage) useDatabase(db *mongo.Database) {
+	readOpts := options.Collection().SetReadPreference(readPreferenceFor(s.cfg.ReadPreference))
+	s.collections.Store(&modelCollections{
+		write:   db.Collection(s.cfg.Collection),
+		read:    db.Collection(s.cfg.Collection, readOpts),
+		history: db.Collection(s.cfg.HistoryCollection, readOpts),
+		stats:   db.Collection(s.cfg.StatsCollection),
+	})
+}
+
+// readPreferenceFor maps a configured read preference name to the driver's
+// read preference. Unknown names fall back to the primary.
+func readPreferenceFor(name string) *readpref.ReadPref {
+	switch name {
+	case config.ReadPreferenceSecondaryPreferred:
+		return readpref.SecondaryPreferred()
+	case config.ReadPreferenceNearest:
+		return readpref.Nearest()
+	default:
+		return readpref.Primary()
 	}
 }
 
 // Upsert implements the ModelStorage interface.
 func (s *MongoModelStorage) Upsert(ctx context.Context, model domain.HuggingFaceModel) error {
-	opts := options.Replace().SetUpsert(true)
+	opts := options.Update().SetUpsert(true)
 	filter := bson.M{"_id": model.ID}
-	_, err := s.collection.ReplaceOne(ctx, filter, model, opts)
+	defer s.slowQueries.track(ctx, "Upsert", filter)()
+	_, err := s.collection().UpdateOne(ctx, filter, replaceKeepingFirstSeen(model), opts)
 	return err
 }
 
+// replaceKeepingFirstSeen builds an update pipeline that replaces a model
+// document like ReplaceOne would, but keeps its firstSeen. When the update
+// inserts, firstSeen is the model's own if it has one, e.g. from an import,
+// and the server's current time otherwise. The model is wrapped in $literal
+// so tags or other strings starting with "$" are not read as expressions.
+func replaceKeepingFirstSeen(model domain.HuggingFaceModel) mongo.Pipeline {
+	var firstSeen any = "$$NOW"
+	if model.FirstSeen != nil {
+		firstSeen = *model.FirstSeen
+	}
+	return mongo.Pipeline{
+		{{Key: "$replaceWith", Value: bson.M{"$mergeObjects": bson.A{
+			bson.M{"$literal": model},
+			bson.M{"firstSeen": bson.M{"$ifNull": bson.A{"$firstSeen", firstSeen}}},
+		}}}},
+	}
+}
+
+// UpsertWithResult implements the ModelStorage interface. The outcome comes
+// from the update result: an upserted ID means an insert, and a matched but
+// unmodified document means the stored copy was identical.
+func (s *MongoModelStorage) UpsertWithResult(ctx context.Context, model domain.HuggingFaceModel) (service.UpsertResult, error) {
+	opts := options.Update().SetUpsert(true)
+	filter := bson.M{"_id": model.ID}
+	defer s.slowQueries.track(ctx, "UpsertWithResult", filter)()
+	result, err := s.collection().UpdateOne(ctx, filter, replaceKeepingFirstSeen(model), opts)
+	if err != nil {
+		return 0, err
+	}
+	return upsertResultOf(result), nil
+}
+
+// upsertResultOf classifies an upsert by its counts: a matched document that
+// the replacement left as it was counts as unchanged.
+func upsertResultOf(result *mongo.UpdateResult) service.UpsertResult {
+	switch {
+	case result.UpsertedCount > 0:
+		return service.UpsertInserted
+	case result.ModifiedCount > 0:
+		return service.UpsertUpdated
+	default:
+		return service.UpsertUnchanged
+	}
+}
+
 // BulkUpsert implements the ModelStorage interface.
 func (s *MongoModelStorage) BulkUpsert(ctx context.Context, models []domain.HuggingFaceModel) error {
 	if len(models) == 0 {
 		return nil
 	}
 
+	ctx, span := tracer.Start(ctx, "storage.BulkUpsert", trace.WithAttributes(attribute.Int("db.models.count", len(models))))
+	defer span.End()
+
 	writeModels := make([]mongo.WriteModel, len(models))
 	for i, model := range models {
 		filter := bson.M{"_id": model.ID}
-		replacement := model
-		writeModels[i] = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(replacement).SetUpsert(true)
+		writeModels[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(replaceKeepingFirstSeen(model)).SetUpsert(true)
 	}
 
 	// SetOrdered(false) allows MongoDB to process the operations in parallel, which is faster.
 	opts := options.BulkWrite().SetOrdered(false)
-	_, err := s.collection.BulkWrite(ctx, writeModels, opts)
-	return err
+	defer s.slowQueries.track(ctx, "BulkUpsert", fmt.Sprintf("%d models", len(models)))()
+	_, err := s.collection().BulkWrite(ctx, writeModels, opts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return partialWriteError(err, models)
+}
+
+// partialWriteError turns a bulk write exception that only rejected some
+// documents into a *service.PartialWriteError. With an unordered bulk write
+// the other documents were written. Anything else, such as a write concern
+// error, is returned unchanged.
+func partialWriteError(err error, models []domain.HuggingFaceModel) error {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	failed := make(map[string]string, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= len(models) {
+			return err
+		}
+		failed[models[writeErr.Index].ID] = writeErr.Message
+	}
+	return &service.PartialWriteError{Failed: failed}
 }
 
 // FindByID implements the ModelStorage interface.
 func (s *MongoModelStorage) FindByID(ctx context.Context, id string) (*domain.HuggingFaceModel, error) {
 	var model domain.HuggingFaceModel
 	filter := bson.M{"_id": id}
-	err := s.collection.FindOne(ctx, filter).Decode(&model)
+	defer s.slowQueries.track(ctx, "FindByID", filter)()
+	err := s.readCollection().FindOne(ctx, filter).Decode(&model)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil // Return nil, nil if not found
@@ -99,16 +603,384 @@ func (s *MongoModelStorage) FindByID(ctx context.Context, id string) (*domain.Hu
 	return &model, nil
 }
 
+// FindByIDs implements the ModelStorage interface.
+func (s *MongoModelStorage) FindByIDs(ctx context.Context, ids []string) ([]domain.HuggingFaceModel, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	defer s.slowQueries.track(ctx, "FindByIDs", fmt.Sprintf("%d ids", len(ids)))()
+	cursor, err := s.collection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "deletedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var models []domain.HuggingFaceModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
 // FindMostRecentlyModified implements the ModelStorage interface.
 func (s *MongoModelStorage) FindMostRecentlyModified(ctx context.Context) (*domain.HuggingFaceModel, error) {
+	return s.FindExtremeBy(ctx, config.BenchmarkFieldLastModified, -1)
+}
+
+// FindExtremeBy implements the ModelStorage interface.
+func (s *MongoModelStorage) FindExtremeBy(ctx context.Context, field string, order int) (*domain.HuggingFaceModel, error) {
+	if err := checkBenchmarkField(field); err != nil {
+		return nil, err
+	}
+	if order >= 0 {
+		order = 1
+	}
 	var model domain.HuggingFaceModel
-	opts := options.FindOne().SetSort(bson.D{{Key: "lastModified", Value: -1}})
-	err := s.collection.FindOne(ctx, bson.D{}, opts).Decode(&model)
+	// Soft-deleted models no longer reflect the Hub, so they can't be the benchmark.
+	filter := bson.M{"deletedAt": bson.M{"$exists": false}}
+	opts := options.FindOne().SetSort(bson.D{{Key: field, Value: order}})
+	defer s.slowQueries.track(ctx, "FindExtremeBy", filter)()
+	err := s.collection().FindOne(ctx, filter, opts).Decode(&model)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil // No models in DB yet
+			return nil, service.ErrNotFound // No live models in DB yet
 		}
 		return nil, err
 	}
 	return &model, nil
 }
+
+// ValueAtPercentile implements the ModelStorage interface by counting the
+// live models and reading the one at the percentile's rank in field order.
+func (s *MongoModelStorage) ValueAtPercentile(ctx context.Context, field string, percentile float64) (int64, error) {
+	if err := checkCountField(field); err != nil {
+		return 0, err
+	}
+	filter := bson.M{"deletedAt": bson.M{"$exists": false}}
+	defer s.slowQueries.track(ctx, "ValueAtPercentile", filter)()
+	total, err := s.readCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, service.ErrNotFound
+	}
+
+	var model domain.HuggingFaceModel
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: field, Value: -1}}).
+		SetSkip(service.PercentileRank(total, percentile) - 1).
+		SetProjection(bson.M{field: 1})
+	if err := s.readCollection().FindOne(ctx, filter, opts).Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Models were deleted since the count.
+			return 0, service.ErrNotFound
+		}
+		return 0, err
+	}
+	return model.CountOf(field), nil
+}
+
+// SampleModels implements the ModelStorage interface using a $sample stage.
+func (s *MongoModelStorage) SampleModels(ctx context.Context, n int) ([]domain.HuggingFaceModel, error) {
+	models := []domain.HuggingFaceModel{}
+	if n <= 0 {
+		return models, nil
+	}
+
+	pipeline := samplePipeline(n)
+	defer s.slowQueries.track(ctx, "SampleModels", pipeline[0][0].Value)()
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// samplePipeline picks n random models that are not soft-deleted.
+func samplePipeline(n int) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$sample", Value: bson.M{"size": n}}},
+	}
+}
+
+// ListIDs implements the ModelStorage interface.
+func (s *MongoModelStorage) ListIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+	filter := bson.M{"_id": bson.M{"$gt": afterID}}
+	defer s.slowQueries.track(ctx, "ListIDs", filter)()
+	cursor, err := s.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids, nil
+}
+
+// MarkDeleted implements the ModelStorage interface.
+func (s *MongoModelStorage) MarkDeleted(ctx context.Context, id string, at time.Time) error {
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}
+	defer s.slowQueries.track(ctx, "MarkDeleted", filter)()
+	_, err := s.collection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"deletedAt": at}})
+	return err
+}
+
+// PurgeDeleted implements the ModelStorage interface.
+func (s *MongoModelStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	filter := purgeFilter(before)
+	defer s.slowQueries.track(ctx, "PurgeDeleted", filter)()
+	result, err := s.collection().DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// purgeFilter matches the models soft-deleted strictly before the given time.
+// Models that are not deleted have no deletedAt and never match.
+func purgeFilter(before time.Time) bson.M {
+	return bson.M{"deletedAt": bson.M{"$lt": before}}
+}
+
+// DeleteByAuthor implements the ModelStorage interface.
+func (s *MongoModelStorage) DeleteByAuthor(ctx context.Context, author string) (int64, error) {
+	filter := bson.M{"author": author}
+	defer s.slowQueries.track(ctx, "DeleteByAuthor", filter)()
+	result, err := s.collection().DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DistinctAuthors implements the ModelStorage interface.
+func (s *MongoModelStorage) DistinctAuthors(ctx context.Context) ([]string, error) {
+	filter := bson.M{"author": bson.M{"$nin": bson.A{nil, ""}}}
+	defer s.slowQueries.track(ctx, "DistinctAuthors", filter)()
+	values, err := s.collection().Distinct(ctx, "author", filter)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]string, 0, len(values))
+	for _, value := range values {
+		if author, ok := value.(string); ok {
+			authors = append(authors, author)
+		}
+	}
+	return authors, nil
+}
+
+// FindRelated implements the ModelStorage interface.
+// Candidates must share at least one tag; they are scored by the size of the
+// tag intersection plus one for the same pipeline tag, with likes and ID as tiebreakers.
+func (s *MongoModelStorage) FindRelated(ctx context.Context, model domain.HuggingFaceModel, limit int) ([]domain.HuggingFaceModel, error) {
+	if len(model.Tags) == 0 || limit <= 0 {
+		return []domain.HuggingFaceModel{}, nil
+	}
+
+	pipeline := relatedPipeline(model, limit)
+	defer s.slowQueries.track(ctx, "FindRelated", bson.M{"_id": model.ID})()
+	cursor, err := s.readCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	models := []domain.HuggingFaceModel{}
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// relatedPipeline ranks the models that are not soft-deleted by how related
+// they are to model, keeping the first limit.
+func relatedPipeline(model domain.HuggingFaceModel, limit int) mongo.Pipeline {
+	pipelineMatch := bson.A{bson.M{"$eq": bson.A{"$pipeline_tag", model.PipelineTag}}, 1, 0}
+	if model.PipelineTag == "" {
+		pipelineMatch = bson.A{false, 1, 0}
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"_id":       bson.M{"$ne": model.ID},
+			"tags":      bson.M{"$in": model.Tags},
+			"deletedAt": bson.M{"$exists": false},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"relatedScore": bson.M{"$add": bson.A{
+				bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{"$tags", bson.A{}}}, model.Tags}}},
+				bson.M{"$cond": pipelineMatch},
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "relatedScore", Value: -1}, {Key: "likes", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"relatedScore": 0}}},
+	}
+}
+
+// EnsureIndexes implements the ModelStorage interface. The sort indexes carry
+// _id as a tiebreaker to match sortWithTiebreak. Creating an index that
+// already exists is a no-op.
+func (s *MongoModelStorage) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "likes", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "downloads", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "lastModified", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "author", Value: 1}}},
+	}
+	defer s.slowQueries.track(ctx, "EnsureIndexes", fmt.Sprintf("%d indexes", len(indexes)))()
+	if _, err := s.collection().Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+	_, err := s.collections.Load().history.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "modelId", Value: 1}, {Key: "scrapedAt", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.collections.Load().stats.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "takenAt", Value: -1}},
+	})
+	return err
+}
+
+// sortableFieldDefaults holds the value a model without the field is given by
+// NormalizeMissingFields. They match what encoding a zero HuggingFaceModel writes.
+var sortableFieldDefaults = bson.D{
+	{Key: "likes", Value: 0},
+	{Key: "downloads", Value: 0},
+	{Key: "lastModified", Value: time.Time{}},
+	{Key: "createdAt", Value: time.Time{}},
+	{Key: "author", Value: ""},
+	{Key: "pipeline_tag", Value: ""},
+}
+
+// NormalizeMissingFields implements the ModelStorage interface with one
+// UpdateMany per field. Equality with null matches both null and missing fields.
+func (s *MongoModelStorage) NormalizeMissingFields(ctx context.Context) (int64, error) {
+	var updated int64
+	for _, field := range sortableFieldDefaults {
+		filter := bson.M{field.Key: nil}
+		done := s.slowQueries.track(ctx, "NormalizeMissingFields", filter)
+		result, err := s.collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{field.Key: field.Value}})
+		done()
+		if err != nil {
+			return updated, fmt.Errorf("normalizing %s: %w", field.Key, err)
+		}
+		updated += result.ModifiedCount
+	}
+	return updated, nil
+}
+
+// CollectionStats implements the ModelStorage interface using the collStats command.
+func (s *MongoModelStorage) CollectionStats(ctx context.Context) (*domain.CollectionStats, error) {
+	// Sizes come back as int32, int64 or double depending on magnitude.
+	var result struct {
+		Count       float64            `bson:"count"`
+		Size        float64            `bson:"size"`
+		AvgObjSize  float64            `bson:"avgObjSize"`
+		StorageSize float64            `bson:"storageSize"`
+		IndexSizes  map[string]float64 `bson:"indexSizes"`
+		Sharded     bool               `bson:"sharded"`
+	}
+	command := bson.D{{Key: "collStats", Value: s.collection().Name()}}
+	if err := s.collection().Database().RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	stats := &domain.CollectionStats{
+		Count:            int64(result.Count),
+		SizeBytes:        int64(result.Size),
+		AvgObjSizeBytes:  int64(result.AvgObjSize),
+		StorageSizeBytes: int64(result.StorageSize),
+		IndexSizes:       make(map[string]int64, len(result.IndexSizes)),
+		Sharded:          result.Sharded,
+	}
+	for name, size := range result.IndexSizes {
+		stats.IndexSizes[name] = int64(size)
+	}
+	return stats, nil
+}
+
+// summaryTopPipelineTags is the number of pipeline tags reported by Summary.
+const summaryTopPipelineTags = 5
+
+// summaryPipeline computes every aggregate of the stats summary over the
+// models that are not soft-deleted in a single $facet stage, so the summary
+// takes one round trip.
+func summaryPipeline() mongo.Pipeline {
+	gatedValues := bson.A{domain.GatedStatusTrue, domain.GatedStatusAuto, domain.GatedStatusManual}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":                nil,
+					"totalModels":        bson.M{"$sum": 1},
+					"gatedModels":        bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$gated", gatedValues}}, 1, 0}}},
+					"privateModels":      bson.M{"$sum": bson.M{"$cond": bson.A{"$private", 1, 0}}},
+					"totalDownloads":     bson.M{"$sum": "$downloads"},
+					"newestLastModified": bson.M{"$max": "$lastModified"},
+				}},
+			},
+			"topPipelineTags": bson.A{
+				bson.M{"$match": bson.M{"pipeline_tag": bson.M{"$nin": bson.A{"", nil}}}},
+				bson.M{"$group": bson.M{"_id": "$pipeline_tag", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": summaryTopPipelineTags},
+			},
+		}}},
+	}
+}
+
+// Summary implements the ModelStorage interface.
+func (s *MongoModelStorage) Summary(ctx context.Context) (*domain.StatsSummary, error) {
+	defer s.slowQueries.track(ctx, "Summary", bson.D{})()
+	cursor, err := s.readCollection().Aggregate(ctx, summaryPipeline())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Totals          []domain.StatsSummary `bson:"totals"`
+		TopPipelineTags []domain.TagCount     `bson:"topPipelineTags"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	summary := &domain.StatsSummary{TopPipelineTags: []domain.TagCount{}}
+	if len(facets) == 1 {
+		if len(facets[0].Totals) == 1 {
+			totals := facets[0].Totals[0]
+			totals.TopPipelineTags = summary.TopPipelineTags
+			summary = &totals
+		}
+		if facets[0].TopPipelineTags != nil {
+			summary.TopPipelineTags = facets[0].TopPipelineTags
+		}
+	}
+	return summary, nil
+}
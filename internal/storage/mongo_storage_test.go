@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/domain"
+	"hf-scraper/internal/service"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestSearchFilterRegexOptionsFollowCaseFlag(t *testing.T) {
+	for _, tc := range []struct {
+		caseSensitive bool
+		wantOptions   string
+	}{
+		{caseSensitive: false, wantOptions: "i"},
+		{caseSensitive: true, wantOptions: ""},
+	} {
+		filter := searchFilter(service.SearchOptions{Query: "Llama", CaseSensitive: tc.caseSensitive})
+		regex, ok := filter["_id"].(primitive.Regex)
+		if !ok {
+			t.Fatalf("CaseSensitive=%v: _id filter is %T, want primitive.Regex", tc.caseSensitive, filter["_id"])
+		}
+		if regex.Pattern != "Llama" || regex.Options != tc.wantOptions {
+			t.Errorf("CaseSensitive=%v: regex = %+v, want pattern Llama with options %q", tc.caseSensitive, regex, tc.wantOptions)
+		}
+	}
+}
+
+func TestSortWithTiebreakOrdersTiesByID(t *testing.T) {
+	for _, tc := range []struct {
+		field string
+		order int
+		want  bson.D
+	}{
+		{field: "likes", order: -1, want: bson.D{{Key: "likes", Value: -1}, {Key: "_id", Value: -1}}},
+		{field: "lastModified", order: 1, want: bson.D{{Key: "lastModified", Value: 1}, {Key: "_id", Value: 1}}},
+		{field: "_id", order: 1, want: bson.D{{Key: "_id", Value: 1}}},
+	} {
+		if got := sortWithTiebreak(tc.field, tc.order); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("sortWithTiebreak(%q, %d) = %v, want %v", tc.field, tc.order, got, tc.want)
+		}
+	}
+}
+
+func TestSummaryPipelineIsOneFacetStageOverLiveModels(t *testing.T) {
+	pipeline := summaryPipeline()
+	if len(pipeline) != 2 || pipeline[0][0].Key != "$match" || pipeline[1][0].Key != "$facet" {
+		t.Fatalf("pipeline = %v, want a $match and a single $facet stage", pipeline)
+	}
+	if want := (bson.M{"deletedAt": bson.M{"$exists": false}}); !reflect.DeepEqual(pipeline[0][0].Value, want) {
+		t.Errorf("$match = %v, want %v", pipeline[0][0].Value, want)
+	}
+	facets := pipeline[1][0].Value.(bson.M)
+	if _, ok := facets["totals"]; !ok {
+		t.Error("the totals facet is missing")
+	}
+	tags, ok := facets["topPipelineTags"].(bson.A)
+	if !ok {
+		t.Fatal("the topPipelineTags facet is missing")
+	}
+	last := tags[len(tags)-1].(bson.M)
+	if last["$limit"] != summaryTopPipelineTags {
+		t.Errorf("topPipelineTags ends with %v, want a $limit of %d", last, summaryTopPipelineTags)
+	}
+}
+
+func TestSearchFilterMatchesLicenseLowercased(t *testing.T) {
+	filter := searchFilter(service.SearchOptions{License: "MIT"})
+	if filter["license"] != "mit" {
+		t.Errorf("license filter = %v, want mit", filter["license"])
+	}
+	if _, ok := searchFilter(service.SearchOptions{})["license"]; ok {
+		t.Error("a search without a license filters on it")
+	}
+}
+
+func TestSearchFilterMatchesLibraryAndLanguage(t *testing.T) {
+	filter := searchFilter(service.SearchOptions{Library: "Transformers", Language: "EN"})
+	if filter["library"] != "transformers" {
+		t.Errorf("library filter = %v, want transformers", filter["library"])
+	}
+	if filter["languages"] != "en" {
+		t.Errorf("languages filter = %v, want en", filter["languages"])
+	}
+}
+
+func TestReadPreferenceFor(t *testing.T) {
+	for name, want := range map[string]readpref.Mode{
+		config.ReadPreferencePrimary:            readpref.PrimaryMode,
+		config.ReadPreferenceSecondaryPreferred: readpref.SecondaryPreferredMode,
+		config.ReadPreferenceNearest:            readpref.NearestMode,
+		"":                                      readpref.PrimaryMode,
+	} {
+		if got := readPreferenceFor(name).Mode(); got != want {
+			t.Errorf("readPreferenceFor(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestSamplePipelineSamplesLiveModels(t *testing.T) {
+	pipeline := samplePipeline(7)
+	if len(pipeline) != 2 {
+		t.Fatalf("pipeline = %v, want $match then $sample", pipeline)
+	}
+	if stage := pipeline[0][0]; stage.Key != "$match" ||
+		!reflect.DeepEqual(stage.Value, bson.M{"deletedAt": bson.M{"$exists": false}}) {
+		t.Errorf("first stage = %v, want a $match excluding deleted models", stage)
+	}
+	if stage := pipeline[1][0]; stage.Key != "$sample" || !reflect.DeepEqual(stage.Value, bson.M{"size": 7}) {
+		t.Errorf("second stage = %v, want {$sample: {size: 7}}", stage)
+	}
+}
+
+func TestSortableFieldDefaultsMatchEncodedZeroModel(t *testing.T) {
+	encoded, err := bson.Marshal(domain.HuggingFaceModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := bson.Marshal(sortableFieldDefaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range sortableFieldDefaults {
+		want, err := bson.Raw(encoded).LookupErr(field.Key)
+		if err != nil {
+			t.Errorf("%s: a zero model does not encode the field: %v", field.Key, err)
+			continue
+		}
+		got := bson.Raw(defaults).Lookup(field.Key)
+		if !got.Equal(want) {
+			t.Errorf("%s: default %v (%s), want %v (%s) as a zero model writes", field.Key, got, got.Type, want, want.Type)
+		}
+	}
+}
+
+func TestSearchFilterEscapesLiteralQueries(t *testing.T) {
+	for _, tc := range []struct {
+		literal     bool
+		wantPattern string
+	}{
+		{literal: false, wantPattern: "model[v1].*"},
+		{literal: true, wantPattern: `model\[v1\]\.\*`},
+	} {
+		filter := searchFilter(service.SearchOptions{Query: "model[v1].*", Literal: tc.literal})
+		if regex := filter["_id"].(primitive.Regex); regex.Pattern != tc.wantPattern {
+			t.Errorf("Literal=%v: pattern = %q, want %q", tc.literal, regex.Pattern, tc.wantPattern)
+		}
+	}
+}
+
+func TestUpsertResultOfClassifiesCounts(t *testing.T) {
+	for _, tc := range []struct {
+		result mongo.UpdateResult
+		want   service.UpsertResult
+	}{
+		{result: mongo.UpdateResult{UpsertedCount: 1, UpsertedID: "a/new"}, want: service.UpsertInserted},
+		{result: mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, want: service.UpsertUpdated},
+		{result: mongo.UpdateResult{MatchedCount: 1}, want: service.UpsertUnchanged},
+	} {
+		if got := upsertResultOf(&tc.result); got != tc.want {
+			t.Errorf("%+v: got %v, want %v", tc.result, got, tc.want)
+		}
+	}
+}
+
+func TestSearchFilterModifiedSince(t *testing.T) {
+	since := time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC)
+	filter := searchFilter(service.SearchOptions{ModifiedSince: since})
+	if got, want := filter["lastModified"], (bson.M{"$gte": since}); !reflect.DeepEqual(got, want) {
+		t.Errorf("lastModified filter = %v, want %v", got, want)
+	}
+	if _, ok := searchFilter(service.SearchOptions{})["lastModified"]; ok {
+		t.Error("a zero ModifiedSince filtered on lastModified")
+	}
+}
+
+func TestSearchFilterGroupsGatedStatuses(t *testing.T) {
+	gated := true
+	in, ok := searchFilter(service.SearchOptions{Gated: &gated})["gated"].(bson.M)["$in"].([]domain.GatedStatus)
+	if !ok {
+		t.Fatalf("gated filter = %v, want an $in of statuses", searchFilter(service.SearchOptions{Gated: &gated})["gated"])
+	}
+	for _, status := range []domain.GatedStatus{domain.GatedStatusTrue, domain.GatedStatusAuto, domain.GatedStatusManual} {
+		if !slices.Contains(in, status) {
+			t.Errorf("the gated $in does not match %q", status)
+		}
+	}
+	if slices.Contains(in, domain.GatedStatusFalse) {
+		t.Error("the gated $in matches false")
+	}
+
+	ungated := false
+	if got, want := searchFilter(service.SearchOptions{Gated: &ungated})["gated"], (bson.M{"$nin": in}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ungated filter = %v, want %v", got, want)
+	}
+}
+
+func TestAuthorCountPipelineOrdersByCountThenAuthor(t *testing.T) {
+	filter := bson.M{"library": "transformers"}
+	pipeline := authorCountPipeline(filter, service.SearchOptions{Page: 3, Limit: 20})
+	if len(pipeline) != 4 {
+		t.Fatalf("pipeline = %v, want $match, $match, $group, $facet", pipeline)
+	}
+	for i, key := range []string{"$match", "$match", "$group", "$facet"} {
+		if pipeline[i][0].Key != key {
+			t.Errorf("stage %d = %s, want %s", i, pipeline[i][0].Key, key)
+		}
+	}
+	if !reflect.DeepEqual(pipeline[0][0].Value, filter) {
+		t.Errorf("first stage matches %v, want the search filter %v", pipeline[0][0].Value, filter)
+	}
+	if want := (bson.M{"_id": "$author", "count": bson.M{"$sum": 1}}); !reflect.DeepEqual(pipeline[2][0].Value, want) {
+		t.Errorf("$group = %v, want %v", pipeline[2][0].Value, want)
+	}
+
+	authors := pipeline[3][0].Value.(bson.M)["authors"].(bson.A)
+	want := bson.A{
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$skip": int64(40)},
+		bson.M{"$limit": int64(20)},
+	}
+	if !reflect.DeepEqual(authors, want) {
+		t.Errorf("authors facet = %v, want %v", authors, want)
+	}
+}
+
+func TestTrendPipelinePicksLastSnapshotWithoutSortingHistory(t *testing.T) {
+	pipeline := trendPipeline("likes", "week")
+	if first := pipeline[0][0]; first.Key != "$group" {
+		t.Fatalf("first stage = %v, want a $group rather than a sort of the whole history", first)
+	}
+	group := pipeline[0][0].Value.(bson.M)
+	want := bson.M{"$bottom": bson.M{"sortBy": bson.D{{Key: "scrapedAt", Value: 1}}, "output": "$likes"}}
+	if !reflect.DeepEqual(group["value"], want) {
+		t.Errorf("value = %v, want %v", group["value"], want)
+	}
+	truncate := group["_id"].(bson.M)["bucket"].(bson.M)["$dateTrunc"].(bson.M)
+	if truncate["unit"] != "week" || truncate["startOfWeek"] != "monday" {
+		t.Errorf("$dateTrunc = %v, want weeks starting on Monday", truncate)
+	}
+	if last := pipeline[len(pipeline)-1][0]; last.Key != "$sort" || !reflect.DeepEqual(last.Value, bson.D{{Key: "_id", Value: 1}}) {
+		t.Errorf("last stage = %v, want buckets sorted oldest first", last)
+	}
+}
+
+func TestFirstSeenPipelineBucketsByFirstSeen(t *testing.T) {
+	pipeline := firstSeenPipeline("day")
+	want := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"firstSeen": bson.M{"$type": "date"}, "deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$dateTrunc": bson.M{"date": "$firstSeen", "unit": "day"}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("pipeline = %v, want %v", pipeline, want)
+	}
+	truncate := firstSeenPipeline("week")[1][0].Value.(bson.M)["_id"].(bson.M)["$dateTrunc"].(bson.M)
+	if truncate["startOfWeek"] != "monday" {
+		t.Errorf("weekly $dateTrunc = %v, want weeks starting on Monday", truncate)
+	}
+}
+
+func TestDuplicateSHAPipelineGroupsSharedSHAs(t *testing.T) {
+	pipeline := duplicateSHAPipeline(bson.M{}, service.SearchOptions{Page: 2, Limit: 10})
+	want := []struct {
+		key   string
+		value any
+	}{
+		{"$match", bson.M{}},
+		{"$match", bson.M{"sha": bson.M{"$nin": bson.A{"", nil}}}},
+		{"$sort", bson.D{{Key: "_id", Value: 1}}},
+		{"$group", bson.M{"_id": "$sha", "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}},
+		{"$match", bson.M{"count": bson.M{"$gt": 1}}},
+	}
+	if len(pipeline) != len(want)+1 {
+		t.Fatalf("pipeline has %d stages, want %d", len(pipeline), len(want)+1)
+	}
+	for i, stage := range want {
+		if got := pipeline[i][0]; got.Key != stage.key || !reflect.DeepEqual(got.Value, stage.value) {
+			t.Errorf("stage %d = %v, want {%s %v}", i, got, stage.key, stage.value)
+		}
+	}
+	groups := pipeline[len(want)][0].Value.(bson.M)["groups"].(bson.A)
+	if skip := groups[1].(bson.M)["$skip"]; skip != int64(10) {
+		t.Errorf("$skip = %v, want the second page of 10", skip)
+	}
+}
+
+func TestRelatedPipelineSkipsDeletedModels(t *testing.T) {
+	pipeline := relatedPipeline(domain.HuggingFaceModel{ID: "a/model", Tags: []string{"nlp"}}, 5)
+	want := bson.M{
+		"_id":       bson.M{"$ne": "a/model"},
+		"tags":      bson.M{"$in": []string{"nlp"}},
+		"deletedAt": bson.M{"$exists": false},
+	}
+	if pipeline[0][0].Key != "$match" || !reflect.DeepEqual(pipeline[0][0].Value, want) {
+		t.Errorf("first stage = %v, want a $match of %v", pipeline[0], want)
+	}
+}
+
+func TestPurgeFilterMatchesDeletionsBeforeTheCutoff(t *testing.T) {
+	cutoff := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	want := bson.M{"deletedAt": bson.M{"$lt": cutoff}}
+	if filter := purgeFilter(cutoff); !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %v, want %v", filter, want)
+	}
+}
+
+func TestPartialWriteErrorMapsRejectedIndexesToIDs(t *testing.T) {
+	models := []domain.HuggingFaceModel{{ID: "a/zero"}, {ID: "a/one"}, {ID: "a/two"}}
+	writeErrors := func(indexes ...int) []mongo.BulkWriteError {
+		var errs []mongo.BulkWriteError
+		for _, index := range indexes {
+			errs = append(errs, mongo.BulkWriteError{WriteError: mongo.WriteError{Index: index, Code: 121, Message: fmt.Sprintf("rejected %d", index)}})
+		}
+		return errs
+	}
+
+	err := partialWriteError(mongo.BulkWriteException{WriteErrors: writeErrors(0, 2)}, models)
+	var partial *service.PartialWriteError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want a *service.PartialWriteError", err)
+	}
+	if want := map[string]string{"a/zero": "rejected 0", "a/two": "rejected 2"}; !reflect.DeepEqual(partial.Failed, want) {
+		t.Errorf("Failed = %v, want %v", partial.Failed, want)
+	}
+
+	for name, in := range map[string]error{
+		"write concern error": mongo.BulkWriteException{
+			WriteConcernError: &mongo.WriteConcernError{Code: 64, Message: "waiting for replication timed out"},
+			WriteErrors:       writeErrors(1),
+		},
+		"index out of range": mongo.BulkWriteException{WriteErrors: writeErrors(1, 3)},
+		"no write errors":    mongo.BulkWriteException{Labels: []string{"NetworkError"}},
+		"other error":        errors.New("connection reset"),
+	} {
+		if out := partialWriteError(in, models); !reflect.DeepEqual(out, in) {
+			t.Errorf("%s: got %v, want the error returned unchanged", name, out)
+		}
+	}
+	if err := partialWriteError(nil, models); err != nil {
+		t.Errorf("no error: got %v, want nil", err)
+	}
+}
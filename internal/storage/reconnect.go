@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"hf-scraper/internal/config"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// disconnectGrace bounds how long a replaced client waits for operations that
+// are still using it before its connections are closed.
+const disconnectGrace = time.Minute
+
+// mongoSupervisor health-checks the Mongo connection and replaces the client
+// when it keeps failing, e.g. because the credentials were rotated, so the
+// daemon recovers without a restart.
+type mongoSupervisor struct {
+	cfg    config.DatabaseConfig
+	models *MongoModelStorage
+	status *MongoStatusStorage
+
+	mu     sync.Mutex
+	client *mongo.Client
+}
+
+// connectMongo creates a client for the configured URI. The driver connects
+// lazily, so this only fails on an invalid URI.
+func connectMongo(ctx context.Context, cfg config.DatabaseConfig) (*mongo.Client, error) {
+	return mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+}
+
+// run pings the database every HealthCheckSeconds until ctx is cancelled and
+// reconnects after ReconnectAfterFailures consecutive failures.
+func (m *mongoSupervisor) run(ctx context.Context) {
+	if m.cfg.HealthCheckSeconds <= 0 {
+		return
+	}
+	interval := time.Duration(m.cfg.HealthCheckSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	superviseConnection(ctx, ticker.C, max(m.cfg.ReconnectAfterFailures, 1),
+		func(ctx context.Context) error { return ping(ctx, m.current(), interval) },
+		func(ctx context.Context) error { return m.reconnect(ctx, interval) })
+}
+
+// superviseConnection runs check on every tick until ctx is cancelled and
+// calls reconnect once threshold checks in a row have failed. A failed
+// reconnect is retried on the next failing check.
+func superviseConnection(ctx context.Context, ticks <-chan time.Time, threshold int, check, reconnect func(context.Context) error) {
+	failures := 0
+	for {
+		select {
+		case <-ticks:
+		case <-ctx.Done():
+			return
+		}
+
+		err := check(ctx)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		log.Printf("Database Warning: health check failed (%d in a row): %v", failures, err)
+		if failures < threshold {
+			continue
+		}
+		if err := reconnect(ctx); err != nil {
+			log.Printf("Database Error: reconnect failed, will retry: %v", err)
+			continue
+		}
+		failures = 0
+	}
+}
+
+// reconnect builds a new client from the configuration and, once it answers,
+// swaps it into the storage adapters. Operations already running keep the
+// collections they loaded and finish on the old client, which is disconnected
+// in the background once they have returned their connections.
+func (m *mongoSupervisor) reconnect(ctx context.Context, timeout time.Duration) error {
+	client, err := connectMongo(ctx, m.cfg)
+	if err != nil {
+		return err
+	}
+	if err := ping(ctx, client, timeout); err != nil {
+		client.Disconnect(context.Background())
+		return err
+	}
+
+	db := client.Database(m.cfg.Name)
+	m.mu.Lock()
+	old := m.client
+	m.client = client
+	m.models.useDatabase(db)
+	m.status.useDatabase(db)
+	m.mu.Unlock()
+	log.Println("Database: reconnected to MongoDB with a new client.")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), disconnectGrace)
+		defer cancel()
+		if err := old.Disconnect(ctx); err != nil {
+			log.Printf("Database Warning: failed to disconnect the replaced client: %v", err)
+		}
+	}()
+	return nil
+}
+
+// current returns the client in use.
+func (m *mongoSupervisor) current() *mongo.Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client
+}
+
+// close disconnects the client in use.
+func (m *mongoSupervisor) close(ctx context.Context) error {
+	return m.current().Disconnect(ctx)
+}
+
+// ping checks that the primary answers within timeout.
+func ping(ctx context.Context, client *mongo.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return client.Ping(ctx, readpref.Primary())
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// flakyConnection simulates a database connection: each check takes the
+// next result from checks and each reconnect the next from reconnects,
+// and every call is recorded in calls.
+type flakyConnection struct {
+	checks     []error
+	reconnects []error
+	calls      []string
+}
+
+func (c *flakyConnection) check(context.Context) error {
+	c.calls = append(c.calls, "check")
+	err := c.checks[0]
+	c.checks = c.checks[1:]
+	return err
+}
+
+func (c *flakyConnection) reconnect(context.Context) error {
+	c.calls = append(c.calls, "reconnect")
+	err := c.reconnects[0]
+	c.reconnects = c.reconnects[1:]
+	return err
+}
+
+// supervise runs superviseConnection over conn for one tick per scripted
+// check and returns once the last one has been handled.
+func supervise(conn *flakyConnection, threshold int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		superviseConnection(ctx, ticks, threshold, conn.check, conn.reconnect)
+	}()
+	for range len(conn.checks) {
+		ticks <- time.Now()
+	}
+	cancel()
+	<-done
+}
+
+func TestLostConnectionIsReconnectedAfterThreshold(t *testing.T) {
+	lost := errors.New("connection lost")
+	conn := &flakyConnection{
+		checks:     []error{nil, lost, lost, nil, lost, lost, lost, nil},
+		reconnects: []error{nil},
+	}
+	supervise(conn, 3)
+
+	want := []string{"check", "check", "check", "check", "check", "check", "check", "reconnect", "check"}
+	if !slices.Equal(conn.calls, want) {
+		t.Errorf("calls = %v, want %v", conn.calls, want)
+	}
+}
+
+func TestFailedReconnectIsRetriedOnTheNextFailedCheck(t *testing.T) {
+	lost := errors.New("connection lost")
+	conn := &flakyConnection{
+		checks:     []error{lost, lost, lost, nil, lost},
+		reconnects: []error{errors.New("auth failed"), nil},
+	}
+	supervise(conn, 2)
+
+	// After the successful reconnect the count starts over, so the last
+	// failed check alone does not reconnect again.
+	want := []string{"check", "check", "reconnect", "check", "reconnect", "check", "check"}
+	if !slices.Equal(conn.calls, want) {
+		t.Errorf("calls = %v, want %v", conn.calls, want)
+	}
+}
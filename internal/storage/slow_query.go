@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxFilterSummary caps how much of a filter is written to the slow-query log.
+const maxFilterSummary = 200
+
+// slowQueryLog warns about storage operations that take longer than a
+// threshold, which usually points at a missing index.
+type slowQueryLog struct {
+	threshold time.Duration
+}
+
+// track starts timing an operation. The returned function must be called when
+// the operation finishes; it logs the operation if it was slow. The trace ID
+// of ctx is included so the entry can be matched to the request that caused it.
+func (l slowQueryLog) track(ctx context.Context, op string, filter any) func() {
+	if l.threshold <= 0 {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		if elapsed < l.threshold {
+			return
+		}
+
+		summary := fmt.Sprint(filter)
+		if len(summary) > maxFilterSummary {
+			summary = summary[:maxFilterSummary] + "..."
+		}
+		traceInfo := ""
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+			traceInfo = " trace_id=" + spanCtx.TraceID().String()
+		}
+		log.Printf("WARNING: slow query: %s took %s (threshold %s) filter=%s%s", op, elapsed.Round(time.Millisecond), l.threshold, summary, traceInfo)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// captureLog redirects the standard logger to a buffer for the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestSlowQueryLogWarnsOverThreshold(t *testing.T) {
+	buf := captureLog(t)
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID}))
+	slow := slowQueryLog{threshold: time.Millisecond}
+
+	done := slow.track(ctx, "SearchModels", map[string]string{"license": "mit"})
+	time.Sleep(5 * time.Millisecond)
+	done()
+
+	entry := buf.String()
+	for _, want := range []string{"slow query: SearchModels", "license:mit", "trace_id=4bf92f3577b34da6a3ce929d0e0e4736"} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("log entry %q does not contain %q", entry, want)
+		}
+	}
+}
+
+func TestSlowQueryLogStaysQuietUnderThresholdOrDisabled(t *testing.T) {
+	buf := captureLog(t)
+
+	slowQueryLog{threshold: time.Hour}.track(context.Background(), "FindByID", nil)()
+	done := slowQueryLog{}.track(context.Background(), "FindByID", nil)
+	time.Sleep(time.Millisecond)
+	done()
+
+	if buf.Len() != 0 {
+		t.Errorf("logged %q, want nothing", buf)
+	}
+}
@@ -4,6 +4,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"hf-scraper/internal/domain"
@@ -17,21 +18,33 @@ const statusDocumentID = "service_status"
 
 // MongoStatusStorage is the MongoDB implementation of the StatusStorage interface.
 type MongoStatusStorage struct {
-	collection *mongo.Collection
+	// current is swapped when the database is reconnected.
+	current        atomic.Pointer[mongo.Collection]
+	collectionName string
 }
 
 // NewMongoStatusStorage creates a new storage adapter for service status.
 func NewMongoStatusStorage(db *mongo.Database, collectionName string) *MongoStatusStorage {
-	return &MongoStatusStorage{
-		collection: db.Collection(collectionName),
-	}
+	s := &MongoStatusStorage{collectionName: collectionName}
+	s.useDatabase(db)
+	return s
+}
+
+// useDatabase points the adapter at db, e.g. one from a reconnected client.
+func (s *MongoStatusStorage) useDatabase(db *mongo.Database) {
+	s.current.Store(db.Collection(s.collectionName))
+}
+
+// collection returns the status collection.
+func (s *MongoStatusStorage) collection() *mongo.Collection {
+	return s.current.Load()
 }
 
 // GetStatus implements the StatusStorage interface.
 func (s *MongoStatusStorage) GetStatusDocument(ctx context.Context) (*domain.StatusDocument, error) {
 	var doc domain.StatusDocument
 	filter := bson.M{"_id": statusDocumentID}
-	err := s.collection.FindOne(ctx, filter).Decode(&doc)
+	err := s.collection().FindOne(ctx, filter).Decode(&doc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			// Return a default, "first run" document.
@@ -53,19 +66,44 @@ func (s *MongoStatusStorage) UpdateStatus(ctx context.Context, status domain.Ser
 		},
 	}
 	opts := options.Update().SetUpsert(true)
-	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	_, err := s.collection().UpdateOne(ctx, filter, update, opts)
 	return err
 }
-func (s *MongoStatusStorage) UpdateBackfillCursor(ctx context.Context, cursorURL string) error {
+func (s *MongoStatusStorage) UpdateBackfillCursor(ctx context.Context, shard, cursorURL string) error {
 	filter := bson.M{"_id": statusDocumentID}
 	update := bson.M{
 		"$set": bson.M{
-			"backfillCursor": cursorURL,
-			"updatedAt":      time.Now().UTC(),
+			"backfillCursors." + shard: cursorURL,
+			"updatedAt":                time.Now().UTC(),
 		},
 	}
+	if shard == domain.DefaultCursorShard {
+		// The map entry supersedes the legacy single cursor.
+		update["$unset"] = bson.M{"backfillCursor": ""}
+	}
 	opts := options.Update().SetUpsert(true)
-	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	_, err := s.collection().UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+// ClearBackfillCursor implements the StatusStorage interface.
+func (s *MongoStatusStorage) ClearBackfillCursor(ctx context.Context, shard string) error {
+	filter := bson.M{"_id": statusDocumentID}
+	unset := bson.M{"backfillCursors." + shard: ""}
+	if shard == domain.DefaultCursorShard {
+		unset["backfillCursor"] = ""
+	}
+	update := bson.M{
+		"$unset": unset,
+		"$set":   bson.M{"updatedAt": time.Now().UTC()},
+	}
+	if _, err := s.collection().UpdateOne(ctx, filter, update); err != nil {
+		return err
+	}
+
+	// Drop the map itself once the last shard has finished.
+	emptyFilter := bson.M{"_id": statusDocumentID, "backfillCursors": bson.M{}}
+	_, err := s.collection().UpdateOne(ctx, emptyFilter, bson.M{"$unset": bson.M{"backfillCursors": ""}})
 	return err
 }
 
@@ -78,6 +116,6 @@ func (s *MongoStatusStorage) SetStatus(ctx context.Context, status domain.Servic
 	}
 	opts := options.Replace().SetUpsert(true)
 	filter := bson.M{"_id": statusDocumentID}
-	_, err := s.collection.ReplaceOne(ctx, filter, doc, opts)
+	_, err := s.collection().ReplaceOne(ctx, filter, doc, opts)
 	return err
 }
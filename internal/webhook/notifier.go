@@ -0,0 +1,137 @@
+// Path: internal/webhook/notifier.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/events"
+)
+
+// payload is the JSON body posted for each event.
+type payload struct {
+	Topic string `json:"topic"`
+	Data  any    `json:"data"`
+}
+
+// Notifier posts broker events to the configured webhook URLs.
+type Notifier struct {
+	client     *http.Client
+	urls       []string
+	topics     []string
+	maxRetries int
+	backoff    time.Duration
+	// slots holds one token per in-flight delivery.
+	slots chan struct{}
+}
+
+// NewNotifier creates a notifier from the webhook settings.
+func NewNotifier(cfg config.WebhookConfig) *Notifier {
+	return &Notifier{
+		client:     &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		urls:       cfg.URLs,
+		topics:     cfg.Topics,
+		maxRetries: max(cfg.MaxRetries, 0),
+		backoff:    time.Duration(cfg.BackoffMs) * time.Millisecond,
+		slots:      make(chan struct{}, max(cfg.MaxInFlight, 1)),
+	}
+}
+
+// Run subscribes to the configured topics and delivers their events until
+// ctx is cancelled. It returns immediately when no URL is configured.
+func (n *Notifier) Run(ctx context.Context, broker *events.Broker) {
+	if len(n.urls) == 0 {
+		return
+	}
+	merged := make(chan events.Event)
+	for _, topic := range n.topics {
+		sub := broker.Subscribe(topic)
+		defer broker.Unsubscribe(topic, sub)
+		go func() {
+			for event := range sub {
+				select {
+				case merged <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	log.Printf("Webhooks: delivering %v events to %d endpoints.", n.topics, len(n.urls))
+
+	for {
+		select {
+		case event := <-merged:
+			n.dispatch(ctx, event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatch starts one delivery per URL, dropping those for which no
+// in-flight slot is free.
+func (n *Notifier) dispatch(ctx context.Context, event events.Event) {
+	body, err := json.Marshal(payload{Topic: event.Topic, Data: event.Data})
+	if err != nil {
+		log.Printf("Webhook Error: could not encode %s event: %v", event.Topic, err)
+		return
+	}
+	for _, url := range n.urls {
+		select {
+		case n.slots <- struct{}{}:
+			go func() {
+				defer func() { <-n.slots }()
+				n.deliver(ctx, url, body)
+			}()
+		default:
+			log.Printf("Webhook Warning: dropped %s event for %s, too many deliveries in flight.", event.Topic, url)
+		}
+	}
+}
+
+// deliver posts body to url, retrying failed attempts with exponential
+// backoff until the retry budget is spent.
+func (n *Notifier) deliver(ctx context.Context, url string, body []byte) {
+	wait := n.backoff
+	for attempt := 0; ; attempt++ {
+		err := n.post(ctx, url, body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.maxRetries {
+			log.Printf("Webhook Error: dropped delivery to %s after %d attempts: %v", url, attempt+1, err)
+			return
+		}
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// post makes a single delivery attempt. Any non-2xx response is a failure.
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hf-scraper/internal/config"
+	"hf-scraper/internal/events"
+)
+
+// endpoint is a webhook receiver that answers each attempt with respond.
+type endpoint struct {
+	attempts atomic.Int32
+	server   *httptest.Server
+	// released is closed when the test ends, so slow responses finish.
+	released chan struct{}
+}
+
+func newEndpoint(t *testing.T, respond func(e *endpoint, w http.ResponseWriter, r *http.Request, attempt int32)) *endpoint {
+	t.Helper()
+	e := &endpoint{released: make(chan struct{})}
+	e.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		respond(e, w, r, e.attempts.Add(1))
+	}))
+	t.Cleanup(e.server.Close)
+	t.Cleanup(func() { close(e.released) })
+	return e
+}
+
+// slow answers only once the client abandons the attempt or the test ends.
+func slow(e *endpoint, w http.ResponseWriter, r *http.Request, _ int32) {
+	select {
+	case <-r.Context().Done():
+	case <-e.released:
+	}
+}
+
+func TestDeliveryTimesOutAndStopsAfterRetryBudget(t *testing.T) {
+	e := newEndpoint(t, slow)
+	n := NewNotifier(config.WebhookConfig{URLs: []string{e.server.URL}, MaxRetries: 2, BackoffMs: 1, MaxInFlight: 1})
+	n.client.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	n.deliver(context.Background(), e.server.URL, []byte(`{}`))
+
+	if got := e.attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 1 plus 2 retries", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("delivery took %s, want each attempt cut off by the timeout", elapsed)
+	}
+}
+
+func TestDeliveryRetriesUntilAccepted(t *testing.T) {
+	e := newEndpoint(t, func(_ *endpoint, w http.ResponseWriter, r *http.Request, attempt int32) {
+		if attempt < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	n := NewNotifier(config.WebhookConfig{URLs: []string{e.server.URL}, TimeoutSeconds: 5, MaxRetries: 5, BackoffMs: 1, MaxInFlight: 1})
+
+	n.deliver(context.Background(), e.server.URL, []byte(`{}`))
+
+	if got := e.attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want retries to stop at the first success", got)
+	}
+}
+
+func TestDispatchDropsEventsPastInFlightCap(t *testing.T) {
+	e := newEndpoint(t, slow)
+	n := NewNotifier(config.WebhookConfig{URLs: []string{e.server.URL}, MaxInFlight: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n.dispatch(ctx, events.Event{Topic: "mode_change"})
+	n.dispatch(ctx, events.Event{Topic: "mode_change"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for e.attempts.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	// Wait for the first delivery to give its slot back.
+	n.slots <- struct{}{}
+	if got := e.attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want the second event dropped while the first was in flight", got)
+	}
+}